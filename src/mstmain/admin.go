@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	patternAdmin         = "/admin"                  // http handler for managing saved data
+	patternAdminDownload = "/admin/download"         // http handler for downloading a saved data file
+	fileAdmin            = "templates/admin.html"    // html for managing saved data
+	adminRealm           = "Prim MST administration" // HTTP Basic realm for the admin pages
+	adminCookie          = "primmst_admin"           // cookie holding the admin forms' CSRF token
+)
+
+// DataFileT is a saved data file listed on the admin page
+type DataFileT struct {
+	Name     string // path relative to the data directory, or the graph state file
+	Size     int64
+	Modified string
+}
+
+// Type to contain all the admin HTML template actions
+type AdminT struct {
+	Files   []DataFileT // saved graphs, exports, and job artifacts
+	Presets []string    // saved preset names
+	Status  string      // result of the last action
+	CSRF    string      // token the forms post back, matching the admin cookie
+}
+
+// dataFilePath maps an admin file name to its path, rejecting names outside the data directory
+func dataFilePath(name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if len(name) == 0 || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid data file name %q", name)
+	}
+	return filepath.Join(dataDir, clean), nil
+}
+
+// listDataFiles lists the files in the data directory, the graph state file among them
+func listDataFiles() ([]DataFileT, error) {
+	files := make([]DataFileT, 0)
+	add := func(name string, info fs.FileInfo) {
+		files = append(files, DataFileT{
+			Name:     name,
+			Size:     info.Size(),
+			Modified: info.ModTime().Format(time.RFC3339),
+		})
+	}
+	err := filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+		add(filepath.ToSlash(rel), info)
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return files, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// adminToken returns the CSRF token of the admin session, setting the admin cookie if the
+// request has none
+func adminToken(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(adminCookie); err == nil && len(c.Value) > 0 {
+		return c.Value
+	}
+	c := &http.Cookie{Name: adminCookie, Value: randomToken(), Path: patternAdmin, HttpOnly: true,
+		Secure: r.TLS != nil, SameSite: http.SameSiteStrictMode}
+	http.SetCookie(w, c)
+	return c.Value
+}
+
+// checkAdminPost rejects an admin POST from another site: its Origin or Referer must be this
+// host, and its csrf form value must match the admin cookie
+func checkAdminPost(r *http.Request) error {
+	source := r.Header.Get("Origin")
+	if len(source) == 0 {
+		source = r.Header.Get("Referer")
+	}
+	if len(source) > 0 {
+		u, err := url.Parse(source)
+		if err != nil || u.Host != r.Host {
+			return fmt.Errorf("the request came from %q, not this host", source)
+		}
+	}
+	c, err := r.Cookie(adminCookie)
+	token := r.PostFormValue("csrf")
+	if err != nil || len(token) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(c.Value)) != 1 {
+		return errors.New("the form is not from this admin session, reload the admin page")
+	}
+	return nil
+}
+
+// HTTP handler for /admin connections lists the saved data and deletes files and presets
+func handleAdmin(w http.ResponseWriter, r *http.Request) {
+	var admin AdminT
+	admin.CSRF = adminToken(w, r)
+	code := http.StatusOK
+
+	if r.Method == http.MethodPost && readOnly {
+		admin.Status = errReadOnly.Error()
+	} else if r.Method == http.MethodPost {
+		err := checkAdminPost(r)
+		if err != nil {
+			code = http.StatusForbidden
+		} else {
+			switch r.FormValue("action") {
+			case "delete":
+				var path string
+				if path, err = dataFilePath(r.FormValue("file")); err == nil {
+					l := fileLock(path)
+					l.Lock()
+					err = os.Remove(path)
+					l.Unlock()
+				}
+				admin.Status = "Deleted file " + r.FormValue("file")
+			case "deletepreset":
+				err = deletePreset(r.FormValue("preset"))
+				admin.Status = "Deleted preset " + r.FormValue("preset")
+			default:
+				err = fmt.Errorf("unknown action %q", r.FormValue("action"))
+			}
+		}
+		if err != nil {
+			fmt.Printf("admin error: %v\n", err)
+			admin.Status = err.Error()
+		}
+	}
+
+	var err error
+	if admin.Files, err = listDataFiles(); err != nil {
+		fmt.Printf("listDataFiles error: %v\n", err)
+		admin.Status = err.Error()
+	}
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Printf("loadPresets error: %v\n", err)
+		admin.Status = err.Error()
+	}
+	admin.Presets = presetNames(presets)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	if err := tmplAdmin.Execute(w, admin); err != nil {
+		fmt.Printf("Write to HTTP output using template with admin error: %v\n", err)
+	}
+}
+
+// HTTP handler for /admin/download connections
+func handleAdminDownload(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("file")
+	path, err := dataFilePath(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, err := readFileLocked(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filepath.Base(path)))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(b)
+}
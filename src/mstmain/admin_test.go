@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckAdminPost(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		referer string
+		cookie  string
+		csrf    string
+		wantErr bool
+	}{
+		{"form from the admin page", "", "http://example.com/admin", "token", "token", false},
+		{"same origin", "http://example.com", "", "token", "token", false},
+		{"no origin or referer", "", "", "token", "token", false},
+		{"other origin", "http://evil.example", "", "token", "token", true},
+		{"other referer", "", "http://evil.example/admin", "token", "token", true},
+		{"origin is checked before the referer", "http://evil.example", "http://example.com/admin", "token", "token", true},
+		{"no cookie", "http://example.com", "", "", "token", true},
+		{"no token", "http://example.com", "", "token", "", true},
+		{"wrong token", "http://example.com", "", "token", "other", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "http://example.com/admin",
+			strings.NewReader("action=delete&file=x&csrf="+tt.csrf))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if len(tt.origin) > 0 {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if len(tt.referer) > 0 {
+			r.Header.Set("Referer", tt.referer)
+		}
+		if len(tt.cookie) > 0 {
+			r.AddCookie(&http.Cookie{Name: adminCookie, Value: tt.cookie})
+		}
+		if err := checkAdminPost(r); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
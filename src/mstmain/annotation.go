@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxAnnotations    = 50  // annotations of a plot
+	maxAnnotationText = 200 // characters of an annotation
+)
+
+// Annotation is a text label at x,y plot coordinates, drawn as the annotations layer and
+// included in the exports
+type Annotation struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Text string  `json:"text"`
+	Left float64 `json:"-"` // x position in percent of the plot width, for the html template
+	Top  float64 `json:"-"` // y position in percent of the plot height from the top
+}
+
+// parseAnnotations parses the annotations, one x, y, text per line.  The text may have commas.
+func parseAnnotations(list string) ([]Annotation, error) {
+	var annotations []Annotation
+	for i, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, ",", 3)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("annotation line %d is not x, y, text", i+1)
+		}
+		x, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("annotation line %d: %v", i+1, err)
+		}
+		y, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("annotation line %d: %v", i+1, err)
+		}
+		text := strings.TrimSpace(fields[2])
+		if len(text) == 0 || len([]rune(text)) > maxAnnotationText {
+			return nil, fmt.Errorf("annotation line %d text must have 1-%d characters", i+1, maxAnnotationText)
+		}
+		if len(annotations) == maxAnnotations {
+			return nil, fmt.Errorf("more than %d annotations", maxAnnotations)
+		}
+		annotations = append(annotations, Annotation{X: x, Y: y, Text: text})
+	}
+	return annotations, nil
+}
+
+// formAnnotations gets the annotations from the HTML form or API request
+func (p *PrimMST) formAnnotations(r *http.Request) error {
+	var err error
+	p.annotations, err = parseAnnotations(r.FormValue("annotations"))
+	return err
+}
+
+// annotationList formats the annotations for the form, one x, y, text per line
+func (p *PrimMST) annotationList() string {
+	lines := make([]string, len(p.annotations))
+	for i, a := range p.annotations {
+		lines[i] = fmt.Sprintf("%g, %g, %s", a.X, a.Y, a.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// placedAnnotations returns the annotations with their positions in percent of the plot
+func (p *PrimMST) placedAnnotations() []Annotation {
+	ep := p.plotEndpoints()
+	placed := make([]Annotation, len(p.annotations))
+	for i, a := range p.annotations {
+		a.Left = 100 * (a.X - ep.xmin) / (ep.xmax - ep.xmin)
+		a.Top = 100 * (ep.ymax - a.Y) / (ep.ymax - ep.ymin)
+		placed[i] = a
+	}
+	return placed
+}
+
+// drawAnnotations marks the annotated points, the text is placed over the grid by the template
+func drawAnnotations(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for _, a := range p.annotations {
+		g.mark(a.X, a.Y, "annotation")
+	}
+	return nil
+}
+
+// writeAnnotations writes the annotations as SVG text in the frame
+func (p *PrimMST) writeAnnotations(w io.Writer) {
+	for _, a := range p.annotations {
+		x, y := p.svgPoint(complex(a.X, a.Y))
+		fmt.Fprintf(w, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"3\" fill=\"#80f\"/>\n", x, y)
+		fmt.Fprintf(w, "<text x=\"%.2f\" y=\"%.2f\" font-family=\"Arial, sans-serif\" font-size=\"12\" fill=\"#80f\">%s</text>\n",
+			x+5, y-5, html.EscapeString(a.Text))
+	}
+}
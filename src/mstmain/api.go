@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Point is the x,y location of a vertex in the JSON API
+type Point struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Name string  `json:"name,omitempty"` // name from the imported vertex list
+}
+
+// EdgeJSON is an MST edge between vertex indexes in the JSON API
+type EdgeJSON struct {
+	V        int     `json:"v"`
+	W        int     `json:"w"`
+	Distance float64 `json:"distance"`
+}
+
+// ResponseJSON is the JSON API response for /api/primmst
+type ResponseJSON struct {
+	Metadata Metadata   `json:"metadata"`
+	Status   []string   `json:"status"`
+	Xmin     float64    `json:"xmin"`
+	Xmax     float64    `json:"xmax"`
+	Ymin     float64    `json:"ymin"`
+	Ymax     float64    `json:"ymax"`
+	Distance float64    `json:"distance"` // MST total distance
+	Vertices []Point    `json:"vertices"` // metadata start is the start vertex index
+	Edges    []EdgeJSON `json:"edges"`    // in the order Prim added them
+
+	Approximation *Approximation    `json:"approximation,omitempty"`    // approximate mode result
+	Nearest       *NearestNeighbors `json:"nearestNeighbors,omitempty"` // nearest neighbor statistics
+	Randomness    *Randomness       `json:"randomness,omitempty"`       // spatial randomness test
+	Operations    *Operations       `json:"operations,omitempty"`       // solver operation counts
+	Ties          *TieReport        `json:"ties,omitempty"`             // tie-breaks of Prim's algorithm
+	MonteCarlo    *MonteCarlo       `json:"monteCarlo,omitempty"`       // MST weight distribution of repeated random graphs
+	Prize         *PrizeCollecting  `json:"prizeCollecting,omitempty"`  // prize-collecting tree
+	Facilities    []Facility        `json:"facilities,omitempty"`       // facility service areas
+	ClusterScores ClusterScores     `json:"clusterScores,omitempty"`    // MST cluster quality by number of clusters
+	Attached      []int             `json:"attached,omitempty"`         // facility area of each vertex
+	Stretch       *Stretch          `json:"stretch,omitempty"`          // MST path stretch from the start vertex
+	Annotations   []Annotation      `json:"annotations,omitempty"`      // text annotations at plot coordinates
+	Cost          *CostBreakdown    `json:"cost,omitempty"`             // MST weight split into distance and fixed components
+	Page          *PageJSON         `json:"page,omitempty"`             // page of the edges when paginated
+}
+
+// point returns the JSON API point of vertex v at z
+func (p *PrimMST) point(v int, z complex128) Point {
+	pt := Point{X: real(z), Y: imag(z)}
+	if p.names != nil {
+		pt.Name = p.names[v]
+	}
+	return pt
+}
+
+// totalDistance returns the sum of the MST edge distances
+func (p *PrimMST) totalDistance() float64 {
+	var distance float64
+	for _, e := range p.mst {
+		if e != nil {
+			distance += p.weight(e.v, e.w)
+		}
+	}
+	return distance
+}
+
+// response creates the JSON API response from the MST
+func (p *PrimMST) response(status []string) *ResponseJSON {
+	resp := &ResponseJSON{
+		Metadata: p.meta,
+		Status:   status,
+		Xmin:     p.xmin,
+		Xmax:     p.xmax,
+		Ymin:     p.ymin,
+		Ymax:     p.ymax,
+		Distance: p.totalDistance(),
+		Vertices: make([]Point, len(p.location)),
+		Edges:    make([]EdgeJSON, 0, len(p.order)),
+
+		Approximation: p.approx,
+		Nearest:       p.nearest,
+		Randomness:    p.randomness,
+		Operations:    p.ops,
+		Ties:          p.ties,
+		MonteCarlo:    p.montecarlo,
+		Prize:         p.prize,
+		Facilities:    p.facilities,
+		ClusterScores: p.clusterScores,
+		Attached:      p.attached,
+		Stretch:       p.stretch,
+		Annotations:   p.annotations,
+		Cost:          p.cost,
+	}
+	for i, z := range p.location {
+		resp.Vertices[i] = p.point(i, z)
+	}
+	for _, v := range p.order {
+		if e := p.mst[v]; e != nil {
+			resp.Edges = append(resp.Edges, EdgeJSON{V: e.v, W: e.w, Distance: p.weight(e.v, e.w)})
+		}
+	}
+	return resp
+}
+
+// HTTP handler for /api/primmst connections
+func handleAPIPrimMST(w http.ResponseWriter, r *http.Request) {
+
+	if err := checkBudget(r); err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	// A repeated run with a seed is served from the response cache
+	p, status := cachedCreateMST(w, r)
+	if len(p.location) > 0 {
+		setLatestMST(p)
+	}
+
+	// The node-link format is requested with format=nodelink
+	if r.FormValue("format") == "nodelink" && len(p.location) > 0 {
+		p.writeNodeLink(w, r)
+		return
+	}
+
+	// The edges can be paged with limit and cursor, and streamed with format=ndjson
+	resp := p.response(status)
+	if code, err := resp.paginate(r); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	if wantNDJSON(r) && len(p.location) > 0 {
+		if err := writeNDJSON(w, resp); err != nil {
+			fmt.Printf("NDJSON encode error: %v\n", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(p.location) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		fmt.Printf("JSON encode error: %v\n", err)
+	}
+}
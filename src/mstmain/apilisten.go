@@ -0,0 +1,48 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const patternAPI = "/api/" // prefix of the JSON API paths
+
+// bearerAuth is middleware that requires the Authorization: Bearer token
+func bearerAuth(token string, h http.Handler) http.Handler {
+	want := sha256.Sum256([]byte(token))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := r.Header.Get("Authorization"), false
+		if len(got) > len("Bearer ") && strings.EqualFold(got[:len("Bearer ")], "Bearer ") {
+			sum := sha256.Sum256([]byte(got[len("Bearer "):]))
+			ok = subtle.ConstantTimeCompare(sum[:], want[:]) == 1
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer realm=\"Prim MST API\"")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// apiAuth wraps the API handler with its authentication: basic, token, or empty for none
+func apiAuth(kind, user, password, token string, h http.Handler) (http.Handler, error) {
+	switch kind {
+	case "":
+		return h, nil
+	case "basic":
+		if len(user) == 0 || len(password) == 0 {
+			return nil, fmt.Errorf("-api-auth basic requires -api-user and -api-password")
+		}
+		return basicAuth(user, password, "Prim MST API", h), nil
+	case "token":
+		if len(token) == 0 {
+			return nil, fmt.Errorf("-api-auth token requires -api-token")
+		}
+		return bearerAuth(token, h), nil
+	}
+	return nil, fmt.Errorf("unknown -api-auth %s, use basic or token", kind)
+}
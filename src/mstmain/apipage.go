@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PageJSON is the page of the MST edges in a paginated JSON API response
+type PageJSON struct {
+	Offset int    `json:"offset"`         // index of the first edge of the page in Prim order
+	Limit  int    `json:"limit"`          // most edges of a page, 0 is all
+	Total  int    `json:"total"`          // edges of the MST
+	Next   string `json:"next,omitempty"` // cursor of the next page, empty on the last page
+}
+
+// ndjsonHeader is the first line of an NDJSON response, the response without the edges,
+// which follow one per line
+type ndjsonHeader struct {
+	*ResponseJSON
+	Edges []EdgeJSON `json:"edges,omitempty"`
+}
+
+// encodeCursor returns the continuation token of the edges of the graph from offset
+func encodeCursor(graph string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(graph + "." + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the graph ID and edge offset of the continuation token
+func decodeCursor(cursor string) (string, int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, fmt.Errorf("cursor %q is not valid", cursor)
+	}
+	graph, off, ok := strings.Cut(string(b), ".")
+	offset, err := strconv.Atoi(off)
+	if !ok || err != nil || offset < 0 {
+		return "", 0, fmt.Errorf("cursor %q is not valid", cursor)
+	}
+	return graph, offset, nil
+}
+
+// paginate keeps the page of the edges selected by the limit and cursor form values.  The
+// edges are in the stable Prim order of the graph, so a cursor is only valid for the graph
+// it was issued for: the request must repeat the parameters and seed of the first page.
+// It returns the HTTP status of an error.
+func (resp *ResponseJSON) paginate(r *http.Request) (int, error) {
+	limit, err := formInt(r, "limit", 0)
+	if err != nil || limit < 0 {
+		return http.StatusBadRequest, fmt.Errorf("limit %q must be a non-negative integer", r.FormValue("limit"))
+	}
+	offset := 0
+	if cursor := r.FormValue("cursor"); len(cursor) > 0 {
+		var graph string
+		if graph, offset, err = decodeCursor(cursor); err != nil {
+			return http.StatusBadRequest, err
+		}
+		if graph != resp.Metadata.Graph {
+			return http.StatusConflict, fmt.Errorf("the cursor is for graph %s but the parameters give graph %s, repeat the parameters and seed of the first page",
+				graph, resp.Metadata.Graph)
+		}
+	}
+	if limit == 0 && offset == 0 {
+		return http.StatusOK, nil
+	}
+	total := len(resp.Edges)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	resp.Page = &PageJSON{Offset: offset, Limit: limit, Total: total}
+	if end < total {
+		resp.Page.Next = encodeCursor(resp.Metadata.Graph, end)
+	}
+	resp.Edges = resp.Edges[offset:end]
+	return http.StatusOK, nil
+}
+
+// wantNDJSON returns true if the request asks for newline delimited JSON, with format=ndjson
+// or the Accept header
+func wantNDJSON(r *http.Request) bool {
+	return r.FormValue("format") == "ndjson" || strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+}
+
+// writeNDJSON streams the response as newline delimited JSON: the response without the edges,
+// then one edge per line, flushing every flushEdges edges so the client need not buffer them
+func writeNDJSON(w http.ResponseWriter, resp *ResponseJSON) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(ndjsonHeader{ResponseJSON: resp}); err != nil {
+		return err
+	}
+	flusher, _ := w.(http.Flusher)
+	for i, e := range resp.Edges {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		if flusher != nil && (i+1)%flushEdges == 0 {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
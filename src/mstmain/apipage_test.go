@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	tests := []struct {
+		graph  string
+		offset int
+	}{
+		{"b2db9134e36a1baf", 0},
+		{"b2db9134e36a1baf", 20},
+		{"0000000000000000", 1 << 30},
+		{"", 7},
+	}
+	for _, tt := range tests {
+		cursor := encodeCursor(tt.graph, tt.offset)
+		graph, offset, err := decodeCursor(cursor)
+		if err != nil || graph != tt.graph || offset != tt.offset {
+			t.Errorf("decodeCursor(encodeCursor(%q, %d)) = %q, %d, %v", tt.graph, tt.offset, graph, offset, err)
+		}
+	}
+
+	invalid := []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("b2db9134e36a1baf")),
+		base64.RawURLEncoding.EncodeToString([]byte("b2db9134e36a1baf.")),
+		base64.RawURLEncoding.EncodeToString([]byte("b2db9134e36a1baf.x")),
+		base64.RawURLEncoding.EncodeToString([]byte("b2db9134e36a1baf.-1")),
+	}
+	for _, cursor := range invalid {
+		if graph, offset, err := decodeCursor(cursor); err == nil {
+			t.Errorf("decodeCursor(%q) = %q, %d, want an error", cursor, graph, offset)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	const graph = "b2db9134e36a1baf"
+	tests := []struct {
+		query      string
+		wantCode   int
+		wantEdges  []int // V of the edges of the page
+		wantNext   string
+		wantPaging bool
+	}{
+		{"", http.StatusOK, []int{0, 1, 2, 3, 4}, "", false},
+		{"limit=2", http.StatusOK, []int{0, 1}, encodeCursor(graph, 2), true},
+		{"limit=2&cursor=" + encodeCursor(graph, 2), http.StatusOK, []int{2, 3}, encodeCursor(graph, 4), true},
+		{"limit=2&cursor=" + encodeCursor(graph, 4), http.StatusOK, []int{4}, "", true},
+		{"cursor=" + encodeCursor(graph, 3), http.StatusOK, []int{3, 4}, "", true},
+		{"limit=2&cursor=" + encodeCursor(graph, 9), http.StatusOK, []int{}, "", true},
+		{"limit=-1", http.StatusBadRequest, nil, "", false},
+		{"limit=x", http.StatusBadRequest, nil, "", false},
+		{"cursor=bad!", http.StatusBadRequest, nil, "", false},
+		{"cursor=" + encodeCursor("0000000000000000", 2), http.StatusConflict, nil, "", false},
+	}
+	for _, tt := range tests {
+		resp := &ResponseJSON{Metadata: Metadata{Graph: graph}}
+		for v := 0; v < 5; v++ {
+			resp.Edges = append(resp.Edges, EdgeJSON{V: v, W: v + 1})
+		}
+		r := httptest.NewRequest(http.MethodGet, "/api/primmst?"+tt.query, nil)
+		code, err := resp.paginate(r)
+		if code != tt.wantCode || (err != nil) != (tt.wantCode != http.StatusOK) {
+			t.Errorf("%s: status %d, %v, want %d", tt.query, code, err, tt.wantCode)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(resp.Edges) != len(tt.wantEdges) {
+			t.Errorf("%s: %d edges, want %d", tt.query, len(resp.Edges), len(tt.wantEdges))
+			continue
+		}
+		for i, e := range resp.Edges {
+			if e.V != tt.wantEdges[i] {
+				t.Errorf("%s: edge %d is %d-%d, want %d", tt.query, i, e.V, e.W, tt.wantEdges[i])
+			}
+		}
+		if (resp.Page != nil) != tt.wantPaging {
+			t.Errorf("%s: page %+v, want a page %v", tt.query, resp.Page, tt.wantPaging)
+		} else if resp.Page != nil && (resp.Page.Next != tt.wantNext || resp.Page.Total != 5) {
+			t.Errorf("%s: page %+v, want next %q of 5 edges", tt.query, resp.Page, tt.wantNext)
+		}
+	}
+}
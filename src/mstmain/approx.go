@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+const (
+	algorithmApproximate = "Approximate" // approximate MST by random edge sampling
+	defaultNeighbors     = 8             // default nearest neighbors sampled per vertex
+	defaultRandomEdges   = 2             // default random long edges sampled per vertex
+	maxNeighbors         = 64            // most nearest neighbors sampled per vertex
+	maxRandomEdges       = 16            // most random long edges sampled per vertex
+)
+
+// Approximation reports the sampled spanning tree and its estimated optimality gap
+type Approximation struct {
+	Neighbors   int     `json:"neighbors"`   // nearest neighbors sampled per vertex
+	RandomEdges int     `json:"randomEdges"` // random edges sampled per vertex
+	Sampled     int     `json:"sampled"`     // distinct candidate edges sampled
+	Candidates  int     `json:"candidates"`  // edges in the complete graph
+	Weight      float64 `json:"weight"`      // spanning tree total distance
+	LowerBound  float64 `json:"lowerBound"`  // half the sum of nearest neighbor distances, 0 when unknown
+	Gap         float64 `json:"gap"`         // (weight - lowerBound) / lowerBound, an upper bound on the gap
+}
+
+// String formats the approximation for the html template
+func (a Approximation) String() string {
+	if a.LowerBound == 0 && a.Weight > 0 {
+		return fmt.Sprintf("sampled %d of %d edges, weight %.2f, no lower bound for the metric",
+			a.Sampled, a.Candidates, a.Weight)
+	}
+	return fmt.Sprintf("sampled %d of %d edges, weight %.2f, lower bound %.2f, gap at most %.1f%%",
+		a.Sampled, a.Candidates, a.Weight, a.LowerBound, 100*a.Gap)
+}
+
+// sampleEdges returns the k nearest neighbor edges and r random edges of each vertex, without
+// duplicates.  The nearest neighbors are by position, found with the grid of the vertices, and
+// only the sampled edges are weighed.  k is at most n-1.
+func (p *PrimMST) sampleEdges(g *vertexGrid, k, r int) ([]WeightedEdge, error) {
+	n := len(p.location)
+	seen := make(map[Edge]bool)
+	edges := make([]WeightedEdge, 0, n*(k+r))
+	add := func(v, w int) error {
+		e := Edge{v: v, w: w}.key()
+		if v == w || seen[e] {
+			return nil
+		}
+		weight, err := p.edgeWeight(e.v, e.w)
+		if err != nil {
+			return err
+		}
+		seen[e] = true
+		edges = append(edges, WeightedEdge{Edge: e, distance: weight})
+		return nil
+	}
+
+	for v := 0; v < n; v++ {
+		for _, nb := range g.nearest(v, k, math.Inf(1), nil) {
+			if err := add(v, nb.w); err != nil {
+				return nil, err
+			}
+		}
+		for i := 0; i < r && n > 1; i++ {
+			if err := add(v, p.rnd.Intn(n)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return edges, nil
+}
+
+// connectForest joins each component but the largest to its nearest other component until
+// the forest is a spanning tree, in case the sample does not connect the graph.  The nearest
+// vertices of the other components are by position, found with the grid of the vertices.
+func (p *PrimMST) connectForest(g *vertexGrid, forest []Edge) []Edge {
+	n := len(p.location)
+	uf := newUnionFind(n)
+	defer uf.count(p.ops)
+	for _, e := range forest {
+		uf.union(e.v, e.w)
+	}
+	for len(forest) < n-1 {
+		// The components in the order of their first vertex
+		var roots []int
+		components := make(map[int][]int)
+		largest := uf.find(0)
+		for v := 0; v < n; v++ {
+			root := uf.find(v)
+			if _, ok := components[root]; !ok {
+				roots = append(roots, root)
+			}
+			components[root] = append(components[root], v)
+			if len(components[root]) > len(components[largest]) {
+				largest = root
+			}
+		}
+		for _, root := range roots {
+			if root == largest {
+				continue
+			}
+			own := func(w int) bool { return uf.find(w) == root }
+			best := neighbor{distance: math.Inf(1)}
+			from := -1
+			for _, v := range components[root] {
+				if nb := g.nearest(v, 1, best.distance, own); len(nb) > 0 {
+					best, from = nb[0], v
+				}
+			}
+			if from >= 0 && uf.union(from, best.w) {
+				forest = append(forest, Edge{v: from, w: best.w})
+			}
+		}
+	}
+	return forest
+}
+
+// findApproximate finds a spanning tree of a sample of the candidate edges, k nearest
+// neighbors plus r random edges per vertex, and estimates its optimality gap.  Without a
+// cached distance matrix it weighs only the sampled edges, in O(V(k+r)) time and memory.
+func (p *PrimMST) findApproximate(k, r int) error {
+	n := len(p.location)
+	if k > n-1 {
+		k = n - 1
+	}
+	start := time.Now()
+	g := newVertexGrid(p.location)
+	edges, err := p.sampleEdges(g, k, r)
+	if err != nil {
+		err = p.useEuclidean(err)
+		edges, _ = p.sampleEdges(g, k, r)
+	}
+	p.meta.timePhase("sample", start)
+
+	sortEdges(edges)
+	p.countOperations(algorithmApproximate, len(edges))
+	p.rootTree(p.connectForest(g, kruskal(n, edges, p.ops)))
+	p.ops.Scanned += g.scanned
+
+	a := &Approximation{Neighbors: k, RandomEdges: r, Sampled: len(edges), Candidates: n * (n - 1) / 2}
+	a.Weight = p.totalDistance()
+	// Each vertex's nearest neighbor distance is at most the length of its MST edges,
+	// and each MST edge is counted by at most its two endpoints.  Without the matrix the
+	// distance is Euclidean, which is at most the length over the terrain but not a bound
+	// on the weight model of another metric.
+	if n > 1 && (p.graph != nil || p.terrain != nil || p.euclidean()) {
+		for _, nearest := range p.nearestDistances() {
+			a.LowerBound += nearest / 2
+		}
+	}
+	if a.LowerBound > 0 {
+		a.Gap = (a.Weight - a.LowerBound) / a.LowerBound
+	}
+	p.approx = a
+	return err
+}
+
+// formApproximate gets the approximate mode sample sizes from the HTML form
+func (p *PrimMST) formApproximate(r *http.Request) error {
+	var err error
+	if p.neighbors, err = formInt(r, "neighbors", defaultNeighbors); err != nil {
+		p.randomEdges = defaultRandomEdges
+		return err
+	}
+	if p.randomEdges, err = formInt(r, "randomedges", defaultRandomEdges); err != nil {
+		return err
+	}
+	if p.neighbors < 1 || p.randomEdges < 0 {
+		p.neighbors, p.randomEdges = defaultNeighbors, defaultRandomEdges
+		return fmt.Errorf("approximate mode needs at least 1 neighbor and 0 random edges per vertex, using %d and %d",
+			p.neighbors, p.randomEdges)
+	}
+	if p.neighbors > maxNeighbors || p.randomEdges > maxRandomEdges {
+		if p.neighbors > maxNeighbors {
+			p.neighbors = maxNeighbors
+		}
+		if p.randomEdges > maxRandomEdges {
+			p.randomEdges = maxRandomEdges
+		}
+		return fmt.Errorf("approximate mode samples at most %d neighbors and %d random edges per vertex, using %d and %d",
+			maxNeighbors, maxRandomEdges, p.neighbors, p.randomEdges)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+func TestFormApproximate(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		neighbors, edges int
+		wantErr          bool
+	}{
+		{"defaults", "", defaultNeighbors, defaultRandomEdges, false},
+		{"given", "neighbors=3&randomedges=0", 3, 0, false},
+		{"largest", "neighbors=64&randomedges=16", maxNeighbors, maxRandomEdges, false},
+		{"no neighbors", "neighbors=0", defaultNeighbors, defaultRandomEdges, true},
+		{"negative random edges", "randomedges=-1", defaultNeighbors, defaultRandomEdges, true},
+		{"too many neighbors", "neighbors=1000000000&randomedges=1", maxNeighbors, 1, true},
+		{"too many random edges", "neighbors=4&randomedges=1000000000", 4, maxRandomEdges, true},
+		{"not a number", "neighbors=x", defaultNeighbors, defaultRandomEdges, true},
+	}
+	for _, tt := range tests {
+		p := &PrimMST{}
+		err := p.formApproximate(httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if p.neighbors != tt.neighbors || p.randomEdges != tt.edges {
+			t.Errorf("%s: %d neighbors and %d random edges, want %d and %d",
+				tt.name, p.neighbors, p.randomEdges, tt.neighbors, tt.edges)
+		}
+	}
+}
+
+// TestFindApproximate checks that the approximate tree spans the vertices, weighs at least
+// the MST and at most the MST when every neighbor is sampled, and that the lower bound holds,
+// with and without the distance matrix
+func TestFindApproximate(t *testing.T) {
+	tests := []struct {
+		n, k, r int
+		exact   bool // every edge is sampled, so the tree is an MST
+	}{
+		{1, 8, 2, false},
+		{2, 8, 2, true},
+		{3, 1, 0, false},
+		{10, 9, 0, true},
+		{10, 1000, 1000, true},
+		{50, 1, 0, false},
+		{200, 4, 1, false},
+		{300, 8, 2, false},
+	}
+	for i := 0; i < 2*len(tests); i++ {
+		tt, matrix := tests[i/2], i%2 == 0
+		rnd := rand.New(rand.NewSource(int64(tt.n)))
+		location := make([]complex128, tt.n)
+		for i := range location {
+			location[i] = complex(100*rnd.Float64(), 100*rnd.Float64())
+		}
+		graph := solver.Distances(location)
+		p := &PrimMST{location: location, rnd: rnd}
+		if matrix {
+			p.graph = graph
+		}
+		if err := p.findApproximate(tt.k, tt.r); err != nil {
+			t.Fatalf("n %d: %v", tt.n, err)
+		}
+		edges, weight := 0, 0.0
+		for _, e := range p.mst {
+			if e != nil {
+				edges++
+				weight += graph[e.v][e.w]
+			}
+		}
+		if tt.n == 1 {
+			continue
+		}
+		want := kruskalWeight(graph)
+		if edges != tt.n-1 || weight < want-1e-9 || tt.exact && weight > want+1e-9 {
+			t.Errorf("n %d k %d r %d matrix %t: %d edges of weight %g, MST weight %g", tt.n, tt.k, tt.r, matrix, edges, weight, want)
+		}
+		if math.Abs(p.approx.Weight-weight) > 1e-9 || p.approx.LowerBound > want+1e-9 {
+			t.Errorf("n %d k %d r %d matrix %t: weight %g, lower bound %g, MST weight %g",
+				tt.n, tt.k, tt.r, matrix, p.approx.Weight, p.approx.LowerBound, want)
+		}
+	}
+}
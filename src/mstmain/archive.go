@@ -0,0 +1,311 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	patternArchive = "/primmstarchive" // http handler for the session archive download and import
+	archiveVersion = 1                 // version of the session archive
+	maxArchiveSize = 64 << 20          // largest session archive upload in bytes
+	maxEntrySize   = 16 << 20          // largest uncompressed file in the session archive
+	maxEntries     = 10000             // most files in the session archive
+	fileArchive    = "archive.json"    // archive description in the zip
+	fileSnapshots  = "snapshots.json"  // pinned snapshots in the zip
+)
+
+// Archive describes a session archive, a zip of the saved graph, presets, stored runs,
+// and pinned snapshots that can be imported into another instance
+type Archive struct {
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	Graph     bool      `json:"graph"`     // the archive has the saved graph state
+	Presets   int       `json:"presets"`   // saved presets
+	Runs      int       `json:"runs"`      // stored runs
+	Snapshots int       `json:"snapshots"` // pinned snapshots of the session
+}
+
+// writeArchive writes the zip of the saved data and the request session's snapshots
+func writeArchive(w io.Writer, r *http.Request) error {
+	zw := zip.NewWriter(w)
+	a := Archive{Version: archiveVersion, Created: time.Now().UTC()}
+	add := func(name string, b []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(b)
+		return err
+	}
+
+	if b, err := readFileLocked(filepath.Join(dataDir, fileState)); err == nil {
+		if err := add(fileState, b); err != nil {
+			return err
+		}
+		a.Graph = true
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	presets, err := loadPresets()
+	if err != nil {
+		return err
+	}
+	if a.Presets = len(presets); a.Presets > 0 {
+		b, err := json.MarshalIndent(presets, "", "\t")
+		if err != nil {
+			return err
+		}
+		if err := add(filePresets, b); err != nil {
+			return err
+		}
+	}
+
+	ids, err := recentRuns(math.MaxInt)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		p, err := runPath(id)
+		if err != nil {
+			return err
+		}
+		b, err := readFileLocked(p)
+		if err != nil {
+			return err
+		}
+		if err := add(path.Join(dirRuns, id+".json"), b); err != nil {
+			return err
+		}
+		a.Runs++
+	}
+
+	if s := snapshots(r); len(s) > 0 {
+		b, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if err := add(fileSnapshots, b); err != nil {
+			return err
+		}
+		a.Snapshots = len(s)
+	}
+
+	b, err := json.MarshalIndent(a, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := add(fileArchive, b); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// readZipFile reads a file of the archive, rejecting a file larger than maxEntrySize
+// whatever size its header declares, so a small upload cannot expand without bound
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxEntrySize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", f.Name, maxEntrySize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, maxEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxEntrySize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", f.Name, maxEntrySize)
+	}
+	return b, nil
+}
+
+// importArchive imports the session archive: it replaces the saved graph, adds or replaces
+// the presets, adds the runs that are not already stored, and pins the snapshots to the
+// request's session.  It returns a summary of what was imported.
+func importArchive(zr *zip.Reader, r *http.Request) (string, error) {
+	if readOnly {
+		return "", errReadOnly
+	}
+	if len(zr.File) > maxEntries {
+		return "", fmt.Errorf("the session archive has %d files, more than %d", len(zr.File), maxEntries)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	f, ok := files[fileArchive]
+	if !ok {
+		return "", fmt.Errorf("not a session archive, %s is missing", fileArchive)
+	}
+	b, err := readZipFile(f)
+	if err != nil {
+		return "", err
+	}
+	var a Archive
+	if err := json.Unmarshal(b, &a); err != nil {
+		return "", fmt.Errorf("%s: %v", fileArchive, err)
+	}
+	if a.Version > archiveVersion {
+		return "", fmt.Errorf("session archive version %d is newer than %d", a.Version, archiveVersion)
+	}
+
+	var summary []string
+	if f, ok := files[fileState]; ok {
+		b, err := readZipFile(f)
+		if err != nil {
+			return "", err
+		}
+		s := &GraphState{}
+		if err := json.Unmarshal(b, s); err != nil {
+			return "", fmt.Errorf("%s: %v", fileState, err)
+		}
+		if s.Version > stateVersion {
+			return "", fmt.Errorf("graph state version %d is newer than %d", s.Version, stateVersion)
+		}
+		if err := (&PrimMST{}).restoreState(s); err != nil {
+			return "", err
+		}
+		if err := saveState(s); err != nil {
+			return "", err
+		}
+		summary = append(summary, fmt.Sprintf("saved graph of %d vertices", len(s.Vertices)))
+	}
+
+	if f, ok := files[filePresets]; ok {
+		b, err := readZipFile(f)
+		if err != nil {
+			return "", err
+		}
+		imported := make(map[string]Preset)
+		if err := json.Unmarshal(b, &imported); err != nil {
+			return "", fmt.Errorf("%s: %v", filePresets, err)
+		}
+		err = updatePresets(func(presets map[string]Preset) {
+			for name, preset := range imported {
+				if !presetNamePattern.MatchString(name) {
+					continue
+				}
+				preset.Name = name
+				presets[name] = preset
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+		summary = append(summary, fmt.Sprintf("%d presets", len(imported)))
+	}
+
+	added, skipped := 0, 0
+	for name, f := range files {
+		id := strings.TrimSuffix(strings.TrimPrefix(name, dirRuns+"/"), ".json")
+		if !strings.HasPrefix(name, dirRuns+"/") || !runIDPattern.MatchString(id) {
+			continue
+		}
+		p, err := runPath(id)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(p); err == nil {
+			skipped++
+			continue
+		}
+		b, err := readZipFile(f)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(b, &Run{}); err != nil {
+			return "", fmt.Errorf("%s: %v", name, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return "", err
+		}
+		if err := writeFileAtomic(p, func(w io.Writer) error {
+			_, err := w.Write(b)
+			return err
+		}); err != nil {
+			return "", err
+		}
+		added++
+	}
+	if added+skipped > 0 {
+		summary = append(summary, fmt.Sprintf("%d runs (%d already stored)", added, skipped))
+	}
+
+	if f, ok := files[fileSnapshots]; ok {
+		c, err := r.Cookie(editCookie)
+		if err != nil {
+			return "", errors.New("snapshots need cookies for the session")
+		}
+		b, err := readZipFile(f)
+		if err != nil {
+			return "", err
+		}
+		var s []Snapshot
+		if err := json.Unmarshal(b, &s); err != nil {
+			return "", fmt.Errorf("%s: %v", fileSnapshots, err)
+		}
+		for _, snapshot := range s {
+			pinSnapshot(c.Value, snapshot)
+		}
+		summary = append(summary, fmt.Sprintf("%d snapshots", len(s)))
+	}
+
+	if len(summary) == 0 {
+		return "Imported an empty session archive", nil
+	}
+	return "Imported " + strings.Join(summary, ", "), nil
+}
+
+// HTTP handler for /primmstarchive connections.  A GET downloads the session archive and a
+// POST imports the uploaded archive file.
+func handleArchive(w http.ResponseWriter, r *http.Request) {
+	editSession(w, r)
+	if r.Method != http.MethodPost {
+		var buf bytes.Buffer
+		if err := writeArchive(&buf, r); err != nil {
+			fmt.Printf("writeArchive error: %v\n", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", "attachment; filename=\"primmstsession.zip\"")
+		w.Write(buf.Bytes())
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxArchiveSize)
+	file, header, err := r.FormFile("archive")
+	if err != nil {
+		http.Error(w, "Upload a session archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	zr, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		http.Error(w, "Session archive is not a zip: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	summary, err := importArchive(zr, r)
+	if err != nil {
+		fmt.Printf("importArchive error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, summary)
+}
@@ -0,0 +1,39 @@
+package main
+
+// equalAspect returns the endpoints widened symmetrically in the shorter dimension
+// so that one unit of x and one unit of y have the same length on the square plot
+func (ep Endpoints) equalAspect() Endpoints {
+	width := ep.xmax - ep.xmin
+	height := ep.ymax - ep.ymin
+	if width > height {
+		pad := (width - height) / 2
+		ep.ymin -= pad
+		ep.ymax += pad
+	} else {
+		pad := (height - width) / 2
+		ep.xmin -= pad
+		ep.xmax += pad
+	}
+	return ep
+}
+
+// plotEndpoints returns the endpoints of the plot, letterboxed when the aspect ratio is equal
+func (p *PrimMST) plotEndpoints() Endpoints {
+	if p.aspect {
+		return p.Endpoints.equalAspect()
+	}
+	return p.Endpoints
+}
+
+// letterbox colors the grid cells outside the Euclidean graph endpoints
+func (g *gridPlot) letterbox(ep Endpoints) {
+	for row := 0; row < g.rows; row++ {
+		y := g.ymax - float64(row)/g.yscale
+		for col := 0; col < g.columns; col++ {
+			x := g.xmin + float64(col)/g.xscale
+			if x < ep.xmin || x > ep.xmax || y < ep.ymin || y > ep.ymax {
+				g.set(row, col, "letterbox")
+			}
+		}
+	}
+}
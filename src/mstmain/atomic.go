@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileLocks guard the persisted graph and data files against concurrent reads and writes
+var (
+	fileLocksMu sync.Mutex
+	fileLocks   = make(map[string]*sync.RWMutex)
+)
+
+// fileLock returns the lock for the file at path
+func fileLock(path string) *sync.RWMutex {
+	fileLocksMu.Lock()
+	defer fileLocksMu.Unlock()
+	path = filepath.Clean(path)
+	l, ok := fileLocks[path]
+	if !ok {
+		l = &sync.RWMutex{}
+		fileLocks[path] = l
+	}
+	return l
+}
+
+// writeFileAtomic writes a temporary file in the same directory and renames it to path,
+// so a reader never sees a partially written file
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	if readOnly {
+		return errReadOnly
+	}
+	l := fileLock(path)
+	l.Lock()
+	defer l.Unlock()
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	// Remove the temporary file if it was not renamed
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// readFileLocked reads the file at path while no writer holds its lock
+func readFileLocked(path string) ([]byte, error) {
+	l := fileLock(path)
+	l.RLock()
+	defer l.RUnlock()
+	return os.ReadFile(path)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// basicAuth is middleware that requires the HTTP Basic user and password
+func basicAuth(user, password, realm string, h http.Handler) http.Handler {
+	// Compare hashes so the comparison time does not depend on the lengths
+	wantUser := sha256.Sum256([]byte(user))
+	wantPassword := sha256.Sum256([]byte(password))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, pw, ok := r.BasicAuth()
+		gotUser := sha256.Sum256([]byte(u))
+		gotPassword := sha256.Sum256([]byte(pw))
+		if !ok || subtle.ConstantTimeCompare(gotUser[:], wantUser[:])&
+			subtle.ConstantTimeCompare(gotPassword[:], wantPassword[:]) != 1 {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\""+realm+"\", charset=\"UTF-8\"")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// exceptPrefix serves requests for paths with prefix using open, and the rest using protected
+func exceptPrefix(prefix string, protected, open http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			open.ServeHTTP(w, r)
+			return
+		}
+		protected.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	patternBatch  = "/primmstbatch" // http handler for the plots of a seed and vertex count sweep
+	dirBatch      = "batch"         // batch exports saved in the data directory, one directory each
+	defaultSeeds  = 10              // seeds of a batch export
+	maxBatchPlots = 100             // plots of a batch export
+)
+
+// Batch formats and destinations, the first is the default
+var (
+	batchFormats = []string{"svg", "png", "svg+png"}
+	batchTargets = []string{"zip", "data"}
+)
+
+// BatchPlot is a plot of the batch export in its manifest
+type BatchPlot struct {
+	Vertices  int        `json:"vertices"`
+	Seed      int64      `json:"seed"`
+	Distance  float64    `json:"distance"` // MST total distance
+	Status    []string   `json:"status,omitempty"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// BatchManifest lists the plots of a batch export with the checksums of their files
+type BatchManifest struct {
+	Version   int         `json:"version"`
+	Created   time.Time   `json:"created"`
+	Algorithm string      `json:"algorithm"`
+	Metric    string      `json:"metric"`
+	Plots     []BatchPlot `json:"plots"`
+}
+
+// writePNG writes a PNG image of the MST, the raster version of the last SVG frame
+func (p *PrimMST) writePNG(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, svgWidth, svgHeight))
+	black := color.RGBA{0, 0, 0, 0xff}
+	for i := range img.Pix {
+		img.Pix[i] = 0xff // white
+	}
+	for i := 0; i < svgWidth; i++ {
+		for _, j := range []int{0, 1, svgHeight - 2, svgHeight - 1} {
+			img.Set(i, j, black)
+			img.Set(j, i, black)
+		}
+	}
+
+	// Draw the edges, then the vertices on top of them, the start vertex in green
+	for _, v := range p.order[1:] {
+		e := p.mst[v]
+		x1, y1 := p.svgPoint(p.location[e.v])
+		x2, y2 := p.svgPoint(p.location[e.w])
+		steps := math.Max(math.Abs(x2-x1), math.Abs(y2-y1))
+		for k := 0.0; k <= steps; k++ {
+			t := k / math.Max(steps, 1)
+			x, y := int(x1+t*(x2-x1)), int(y1+t*(y2-y1))
+			img.Set(x, y, color.RGBA{0x88, 0x88, 0x88, 0xff})
+			img.Set(x+1, y, color.RGBA{0x88, 0x88, 0x88, 0xff})
+		}
+	}
+	disc := func(z complex128, r int, c color.RGBA) {
+		x, y := p.svgPoint(z)
+		for dx := -r; dx <= r; dx++ {
+			for dy := -r; dy <= r; dy++ {
+				if dx*dx+dy*dy <= r*r {
+					img.Set(int(x)+dx, int(y)+dy, c)
+				}
+			}
+		}
+	}
+	for i, z := range p.location {
+		if i != p.start {
+			disc(z, 2, black)
+		}
+	}
+	disc(p.location[p.start], 4, color.RGBA{0, 0xff, 0, 0xff})
+	for _, a := range p.annotations {
+		disc(complex(a.X, a.Y), 3, color.RGBA{0x88, 0, 0xff, 0xff})
+	}
+	return png.Encode(w, img)
+}
+
+// batchVertices parses the comma separated vertex counts of the batch
+func batchVertices(list string) ([]int, error) {
+	var counts []int
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if len(field) == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		if n > maxVertices {
+			return nil, &BudgetError{What: "vertices", Asked: n, Budget: maxVertices, Flag: "-max-vertices"}
+		}
+		if n < minVertices {
+			return nil, fmt.Errorf("number of vertices %d is less than %d", n, minVertices)
+		}
+		if len(counts) == maxBatchPlots {
+			return nil, fmt.Errorf("the batch has more than %d vertex counts", maxBatchPlots)
+		}
+		counts = append(counts, n)
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("the batch needs vertex counts")
+	}
+	return counts, nil
+}
+
+// batchExports returns the exports of the plots of the graph options for each vertex count
+// and the seeds from the first seed, with the manifest of the batch
+func batchExports(r *http.Request) ([]export, *BatchManifest, error) {
+	counts, err := batchVertices(r.FormValue("batchvertices"))
+	if err != nil {
+		return nil, nil, err
+	}
+	seeds, err := formInt(r, "batchseeds", defaultSeeds)
+	if err != nil {
+		return nil, nil, err
+	}
+	first, err := strconv.ParseInt(r.FormValue("seed"), 10, 64)
+	if err != nil {
+		first = 1
+	}
+	// Divide rather than multiply, so a huge seed count cannot overflow past the limit
+	if seeds < 1 || seeds > maxBatchPlots/len(counts) {
+		return nil, nil, fmt.Errorf("%d seeds of %d vertex counts is not in the range 1-%d plots",
+			seeds, len(counts), maxBatchPlots)
+	}
+	if first > math.MaxInt64-int64(seeds) {
+		return nil, nil, fmt.Errorf("seed %d is too large for %d seeds", first, seeds)
+	}
+	format, err := formChoice(r, "batchformat", batchFormats)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Each plot is the MST of the graph options with the count and seed, without the
+	// earlier graph or a pasted vertex list
+	params := runParams(r.Form)
+	for _, name := range []string{"graph", "newstartvert", "edit", "editop", "epsilon", "vertexlist"} {
+		params.Del(name)
+	}
+	manifest := &BatchManifest{Version: manifestVersion, Created: time.Now().UTC()}
+	var exports []export
+	for _, n := range counts {
+		for seed := first; seed < first+int64(seeds); seed++ {
+			params.Set("vertices", strconv.Itoa(n))
+			params.Set("seed", strconv.FormatInt(seed, 10))
+			req, err := formRequest(params)
+			if err != nil {
+				return nil, nil, err
+			}
+			p, status := createMST(req.WithContext(r.Context()))
+			if len(p.order) == 0 {
+				return nil, nil, fmt.Errorf("%d vertices, seed %d: %s", n, seed, strings.Join(status, ", "))
+			}
+			manifest.Algorithm, manifest.Metric = p.meta.Algorithm, p.meta.Metric
+			plot := BatchPlot{Vertices: n, Seed: seed, Distance: p.totalDistance(), Status: status}
+			name := fmt.Sprintf("primmst-n%03d-seed%d", n, seed)
+			var plotExports []export
+			if format != "png" {
+				plotExports = append(plotExports, export{name + ".svg", "image/svg+xml", func(w io.Writer) error {
+					return p.writeFrame(w, len(p.order)-1)
+				}})
+			}
+			if format != "svg" {
+				plotExports = append(plotExports, export{name + ".png", "image/png", p.writePNG})
+			}
+			for _, e := range plotExports {
+				_, a, err := e.artifact()
+				if err != nil {
+					return nil, nil, err
+				}
+				plot.Artifacts = append(plot.Artifacts, a)
+			}
+			exports = append(exports, plotExports...)
+			manifest.Plots = append(manifest.Plots, plot)
+		}
+	}
+	return exports, manifest, nil
+}
+
+// writeBatchZip writes a zip archive of the batch plots and manifest.json
+func writeBatchZip(w io.Writer, exports []export, manifest *BatchManifest) error {
+	zw := zip.NewWriter(w)
+	for _, e := range exports {
+		f, err := zw.Create(e.name)
+		if err != nil {
+			return err
+		}
+		if err := e.write(f); err != nil {
+			return err
+		}
+	}
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// saveBatch writes the batch plots and manifest.json to a new directory of the data
+// directory and returns the directory
+func saveBatch(exports []export, manifest *BatchManifest) (string, error) {
+	if readOnly {
+		return "", errReadOnly
+	}
+	dir := filepath.Join(dataDir, dirBatch, strconv.FormatInt(newSeed(), 36))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	for _, e := range exports {
+		if err := writeFileAtomic(filepath.Join(dir, e.name), e.write); err != nil {
+			return "", err
+		}
+	}
+	err := writeFileAtomic(filepath.Join(dir, "manifest.json"), func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(manifest)
+	})
+	return dir, err
+}
+
+// HTTP handler for /primmstbatch connections.  It renders the MST plots of the graph options
+// for each vertex count and seed, and downloads them as a zip or saves them in the data directory.
+func handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Export a batch from the graph options", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseMultipartForm(maxUpload); err != nil && err != http.ErrNotMultipart {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	target, err := formChoice(r, "batchto", batchTargets)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exports, manifest, err := batchExports(r)
+	if err != nil {
+		fmt.Printf("batchExports error: %v\n", err)
+		writeBudgetError(w, r, err)
+		return
+	}
+
+	if target == "data" {
+		dir, err := saveBatch(exports, manifest)
+		if err != nil {
+			fmt.Printf("saveBatch error: %v\n", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "Saved %d plots in %s\n", len(manifest.Plots), dir)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"primmstbatch.zip\"")
+	if err := writeBatchZip(w, exports, manifest); err != nil {
+		fmt.Printf("writeBatchZip error: %v\n", err)
+	}
+}
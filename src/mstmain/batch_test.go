@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBatchLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+	}{
+		{"overflowing seed count", url.Values{"batchseeds": {"4611686018427387904"}, "batchvertices": {"10,10,10,10"}}},
+		{"too many plots", url.Values{"batchseeds": {"26"}, "batchvertices": {"10,10,10,10"}}},
+		{"no seeds", url.Values{"batchseeds": {"0"}, "batchvertices": {"10"}}},
+		{"too many vertex counts", url.Values{"batchseeds": {"1"}, "batchvertices": {strings.Repeat("10,", maxBatchPlots+1)}}},
+		{"overflowing last seed", url.Values{"batchseeds": {"2"}, "batchvertices": {"10"}, "seed": {"9223372036854775807"}}},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.params.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if exports, _, err := batchExports(r); err == nil {
+			t.Errorf("%s: %d exports, want an error", tt.name, len(exports))
+		}
+	}
+}
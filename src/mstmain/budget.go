@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	fileBudget         = "templates/budget.html" // html for the requests over the server budget
+	defaultMaxVertices = 500                     // default maximum number of vertices, the -max-vertices flag
+)
+
+// Server budget of the vertices of a graph and the rows or columns of an auto-sized grid,
+// set by the -max-vertices and -max-grid flags
+var (
+	maxVertices   = defaultMaxVertices
+	maxResolution = defaultMaxResolution
+)
+
+// BudgetError is a request over the server budget, shown on the budget error page
+type BudgetError struct {
+	What   string // what the request asks too much of
+	Asked  int    // requested amount
+	Budget int    // server budget
+	Flag   string // server flag that sets the budget
+	Theme  string
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%d %s is over the server budget of %d", e.Asked, e.What, e.Budget)
+}
+
+// checkBudget checks the vertex count, pasted vertex list, and grid size of the form against
+// the server budget before anything is allocated for them
+func checkBudget(r *http.Request) error {
+	if n, err := formInt(r, "vertices", 0); err == nil && n > maxVertices {
+		return &BudgetError{What: "vertices", Asked: n, Budget: maxVertices, Flag: "-max-vertices"}
+	}
+	if list := r.FormValue("vertexlist"); len(list) > 0 {
+		lines := 0
+		for _, line := range strings.Split(list, "\n") {
+			if line = strings.TrimSpace(line); len(line) > 0 && !strings.HasPrefix(line, "#") {
+				lines++
+			}
+		}
+		if lines > maxVertices {
+			return &BudgetError{What: "pasted vertices", Asked: lines, Budget: maxVertices, Flag: "-max-vertices"}
+		}
+	}
+	if n, err := formInt(r, "maxresolution", 0); err == nil && n > maxResolution {
+		return &BudgetError{What: "grid rows and columns", Asked: n, Budget: maxResolution, Flag: "-max-grid"}
+	}
+	return nil
+}
+
+// writeBudgetError writes the budget error page, or the plain error when it is not over budget
+func writeBudgetError(w http.ResponseWriter, r *http.Request, err error) {
+	var be *BudgetError
+	if !errors.As(err, &be) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Printf("checkBudget error: %v\n", be)
+	be.Theme, _ = formChoice(r, "theme", themes)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	if err := tmplBudget.Execute(w, be); err != nil {
+		fmt.Printf("Write to HTTP output using template with budget error: %v\n", err)
+	}
+}
+
+// budgetMemory describes the memory of the distance matrix at the vertex budget
+func budgetMemory() string {
+	bytes := float64(maxVertices) * float64(maxVertices) * 8
+	return fmt.Sprintf("%.1f MiB", bytes/(1<<20))
+}
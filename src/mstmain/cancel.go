@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// computeTimeout is the longest computation of a request, set by -timeout, 0 has no limit
+var computeTimeout time.Duration
+
+// computeContext returns the context of the computation for the request, which is done when
+// the client goes away, after -timeout, or after the timelimit form value in seconds
+func computeContext(r *http.Request) (context.Context, context.CancelFunc, error) {
+	limit := computeTimeout
+	seconds, err := formFloat(r, "timelimit", 0)
+	if err == nil && seconds < 0 {
+		err = fmt.Errorf("time limit %g is negative", seconds)
+	}
+	if err == nil && seconds > 0 {
+		if d := time.Duration(seconds * float64(time.Second)); limit == 0 || d < limit {
+			limit = d
+		}
+	}
+	if limit == 0 {
+		ctx, cancel := context.WithCancel(r.Context())
+		return ctx, cancel, err
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), limit)
+	return ctx, cancel, err
+}
+
+// context returns the context of the computation, which is never done if none was set
+func (p *PrimMST) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// checkpoint returns true if the computation was cancelled or timed out, and records
+// the phase it stopped in as a partial result
+func (p *PrimMST) checkpoint(phase string) bool {
+	err := p.context().Err()
+	if err == nil {
+		return false
+	}
+	if len(p.meta.Partial) == 0 {
+		reason := "cancelled"
+		if errors.Is(err, context.DeadlineExceeded) {
+			reason = "timed out"
+		}
+		p.meta.Partial = fmt.Sprintf("Partial result: %s during %s", reason, phase)
+	}
+	return true
+}
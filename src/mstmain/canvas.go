@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+const (
+	patternCanvas = "/primmstcanvas"        // http handler for the interactive canvas view
+	fileCanvas    = "templates/canvas.html" // html for the interactive canvas view
+)
+
+// Type to contain all the canvas HTML template actions
+type CanvasT struct {
+	JSON     template.JS // node-link JSON of the MST drawn by static/canvas.js
+	Distance float64     // MST total distance
+	Status   string      // status of the MST
+	Theme    string      // page theme
+}
+
+// HTTP handler for /primmstcanvas connections
+func handleCanvas(w http.ResponseWriter, r *http.Request) {
+
+	p, status := createMST(r)
+	if len(p.location) == 0 {
+		http.Error(w, strings.Join(status, ", "), http.StatusBadRequest)
+		return
+	}
+	setLatestMST(p)
+
+	b, err := json.Marshal(p.nodeLink(false))
+	if err != nil {
+		fmt.Printf("JSON encode error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	view := CanvasT{JSON: template.JS(b), Distance: p.totalDistance(), Status: "OK", Theme: p.theme}
+	if len(status) > 0 {
+		view.Status = strings.Join(status, ", ")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmplCanvas.Execute(w, view); err != nil {
+		fmt.Printf("Write to HTTP output using template with canvas error: %v\n", err)
+	}
+}
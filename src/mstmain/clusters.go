@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultClusters = 5 // default number of MST clusters
+	clusterClasses  = 8 // number of CSS classes for the cluster colors
+)
+
+// formClusters gets the number of clusters from the HTML form, the default when the form
+// value is invalid
+func (p *PrimMST) formClusters(r *http.Request) error {
+	p.clusterCount = defaultClusters
+	k, err := formInt(r, "clusters", defaultClusters)
+	if err != nil {
+		return err
+	}
+	if k < 1 || k > maxVertices {
+		return fmt.Errorf("clusters %d is not in the range 1-%d", k, maxVertices)
+	}
+	p.clusterCount = k
+	return nil
+}
+
+// findClusters splits the vertices into p.clusterCount single linkage clusters by
+// removing the heaviest MST edges, and numbers the clusters by their lowest vertex
+func (p *PrimMST) findClusters() {
+	p.cluster, p.clusterCount = p.cutClusters(p.sortedEdges(), p.clusterCount)
+}
+
+// sortedEdges returns the MST edges in increasing distance order
+func (p *PrimMST) sortedEdges() []Edge {
+	edges := make([]Edge, 0, len(p.location)-1)
+	for _, e := range p.mst {
+		if e != nil {
+			edges = append(edges, *e)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return p.weight(edges[i].v, edges[i].w) < p.weight(edges[j].v, edges[j].w)
+	})
+	return edges
+}
+
+// cutClusters removes the k-1 heaviest of the sorted MST edges and returns the cluster of
+// each vertex, numbered by their lowest vertex, and the number of clusters
+func (p *PrimMST) cutClusters(edges []Edge, k int) ([]int, int) {
+	n := len(p.location)
+	if k > n {
+		k = n
+	}
+	if k < 1 {
+		k = 1
+	}
+	uf := newUnionFind(n)
+	if keep := len(edges) - (k - 1); keep > 0 {
+		for _, e := range edges[:keep] {
+			uf.union(e.v, e.w)
+		}
+	}
+
+	cluster := make([]int, n)
+	number := make(map[int]int)
+	for v := 0; v < n; v++ {
+		root := uf.find(v)
+		c, ok := number[root]
+		if !ok {
+			c = len(number)
+			number[root] = c
+		}
+		cluster[v] = c
+	}
+	return cluster, len(number)
+}
+
+// clusterSummary reports the number of clusters and their sizes
+func (p *PrimMST) clusterSummary() string {
+	sizes := make([]int, p.clusterCount)
+	for _, c := range p.cluster {
+		sizes[c]++
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return fmt.Sprintf("%d MST clusters, sizes %s", p.clusterCount,
+		strings.Trim(fmt.Sprint(sizes), "[]"))
+}
+
+// plotClusters colors the vertices by cluster
+func (p *PrimMST) plotClusters(g *gridPlot) {
+	for v, z := range p.location {
+		g.point(real(z), imag(z), fmt.Sprintf("cluster%d", p.cluster[v]%clusterClasses))
+	}
+}
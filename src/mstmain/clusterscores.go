@@ -0,0 +1,103 @@
+package main
+
+import "math"
+
+const maxScoreClusters = 10 // largest number of clusters scored for the k table
+
+// ClusterScore is the quality of the MST clusters for one number of clusters
+type ClusterScore struct {
+	K          int     `json:"k"`
+	Silhouette float64 `json:"silhouette"` // mean silhouette of the vertices, -1 to 1, higher separates better
+	Within     float64 `json:"within"`     // mean distance between vertices in the same cluster
+	Between    float64 `json:"between"`    // mean distance between vertices in different clusters
+	Ratio      float64 `json:"ratio"`      // within over between, lower separates better
+	Selected   bool    `json:"selected"`   // the number of clusters drawn
+}
+
+// ClusterScores are the cluster scores in increasing number of clusters
+type ClusterScores []ClusterScore
+
+// scoreClusters scores the clusters of the cut MST for k from 2 to maxScoreClusters, and
+// the number drawn when it is larger, so the choices of k can be compared
+func (p *PrimMST) scoreClusters() ClusterScores {
+	n := len(p.location)
+	edges := p.sortedEdges()
+	var ks []int
+	for k := 2; k <= maxScoreClusters && k < n; k++ {
+		ks = append(ks, k)
+	}
+	if p.clusterCount > maxScoreClusters && p.clusterCount < n {
+		ks = append(ks, p.clusterCount)
+	}
+	var scores ClusterScores
+	for _, k := range ks {
+		cluster, count := p.cutClusters(edges, k)
+		score := p.clusterScore(cluster, count)
+		score.Selected = k == p.clusterCount
+		scores = append(scores, score)
+	}
+	return scores
+}
+
+// clusterScore computes the silhouette and the within and between cluster mean distances
+// of the clustering from the edge weights
+func (p *PrimMST) clusterScore(cluster []int, count int) ClusterScore {
+	n := len(p.location)
+	score := ClusterScore{K: count}
+	var within, between float64
+	var nwithin, nbetween int
+	sum := make([]float64, count) // distances from the vertex to each cluster
+	size := make([]int, count)
+	for _, c := range cluster {
+		size[c]++
+	}
+	for v := 0; v < n; v++ {
+		for c := range sum {
+			sum[c] = 0
+		}
+		for w := 0; w < n; w++ {
+			if w == v {
+				continue
+			}
+			d := p.weight(v, w)
+			sum[cluster[w]] += d
+			if w < v {
+				continue
+			}
+			if cluster[v] == cluster[w] {
+				within += d
+				nwithin++
+			} else {
+				between += d
+				nbetween++
+			}
+		}
+
+		// The silhouette of a vertex alone in its cluster is 0
+		own := cluster[v]
+		if size[own] == 1 {
+			continue
+		}
+		a := sum[own] / float64(size[own]-1)
+		b := math.Inf(1)
+		for c := range sum {
+			if c != own {
+				b = math.Min(b, sum[c]/float64(size[c]))
+			}
+		}
+		if s := math.Max(a, b); s > 0 {
+			score.Silhouette += (b - a) / s
+		}
+	}
+	score.Silhouette /= float64(n)
+	if nwithin > 0 {
+		score.Within = within / float64(nwithin)
+	}
+	if nbetween > 0 {
+		score.Between = between / float64(nbetween)
+	}
+	if score.Between > 0 {
+		score.Ratio = score.Within / score.Between
+	}
+	return score
+}
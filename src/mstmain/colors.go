@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// LayerColor is the color of a plot layer, Class is the CSS class of its grid cells
+// and the form field is Class + "color"
+type LayerColor struct {
+	Class string // CSS class of the layer
+	Label string // layer name in the graph options form
+	Color string // #rrggbb color
+}
+
+// layerColors are the configurable layers with the light theme colors as defaults
+var layerColors = []LayerColor{
+	{"vertex", "Vertex", "#000000"},
+	{"edge", "Edge", "#dddddd"},
+	{"startvertex", "Start vertex", "#00ff00"},
+	{"outliervertex", "Outlier vertex", "#dd0000"},
+	{"changededge", "Changed edge", "#ff8800"},
+	{"orderpath", "Order path", "#ffeecc"},
+}
+
+// colorPattern matches the colors accepted from the form, which are written into a style element
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// formColors gets the layer colors from the HTML form when custom colors are checked,
+// otherwise the theme colors are used and no layer colors are returned
+func formColors(r *http.Request) ([]LayerColor, error) {
+	if len(r.FormValue("usecolors")) == 0 {
+		return nil, nil
+	}
+	var colors []LayerColor
+	for _, lc := range layerColors {
+		color := r.FormValue(lc.Class + "color")
+		if len(color) == 0 {
+			continue
+		}
+		if !colorPattern.MatchString(color) {
+			return nil, fmt.Errorf("%s color %s is not #rrggbb", lc.Label, color)
+		}
+		lc.Color = color
+		colors = append(colors, lc)
+	}
+	return colors, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	patternCompare = "/compare"               // http handler for comparing two uploaded vertex sets
+	fileCompare    = "templates/compare.html" // html for the side by side comparison
+	maxUpload      = 1 << 20                  // maximum size of an uploaded vertex file
+)
+
+// CompareStats are the statistics of one vertex set in the comparison table
+type CompareStats struct {
+	Name     string        // uploaded file name
+	Vertices int           // vertices accepted
+	Rejected int           // lines rejected
+	Distance string        // MST total distance
+	MeanEdge string        // mean MST edge length
+	MaxEdge  string        // longest MST edge length
+	Tree     string        // canonical hash of the MST edges
+	Runtime  time.Duration // time to find the MST
+}
+
+// CompareT contains the comparison HTML template actions
+type CompareT struct {
+	Xlabel  []string       // x-axis labels shared by both plots
+	Ylabel  []string       // y-axis labels shared by both plots
+	Stats   []CompareStats // statistics of each vertex set
+	Uploads bool           // the trees are the MSTs of uploaded vertex files
+	Status  string         // status of the comparison
+	Theme   string         // page theme
+}
+
+// uploadVertices reads the vertices of the uploaded file in the form field
+func uploadVertices(r *http.Request, field string) ([]complex128, CompareStats, error) {
+	stats := CompareStats{}
+	f, header, err := r.FormFile(field)
+	if err != nil {
+		return nil, stats, fmt.Errorf("%s: %v", field, err)
+	}
+	defer f.Close()
+	stats.Name = header.Filename
+	b, err := io.ReadAll(io.LimitReader(f, maxUpload))
+	if err != nil {
+		return nil, stats, err
+	}
+	all := Endpoints{xmin: math.Inf(-1), xmax: math.Inf(1), ymin: math.Inf(-1), ymax: math.Inf(1)}
+	list, err := parseVertexList(string(b), all)
+	if err != nil {
+		return nil, stats, fmt.Errorf("%s: %v", stats.Name, err)
+	}
+	location := list.location
+	stats.Vertices, stats.Rejected = len(location), list.rejected
+	if len(location) < minVertices || len(location) > maxVertices {
+		return nil, stats, fmt.Errorf("%s has %d vertices, not in the range %d-%d",
+			stats.Name, len(location), minVertices, maxVertices)
+	}
+	return location, stats, nil
+}
+
+// compareMST finds the MST of the vertices and fills in its statistics
+func compareMST(location []complex128, stats *CompareStats, f labelFormat) *PrimMST {
+	p := &PrimMST{location: location}
+	start := time.Now()
+	p.findDistances()
+	p.findMST()
+	stats.Runtime = time.Since(start)
+	p.treeStats(stats, f)
+	return p
+}
+
+// treeStats fills in the distance and edge statistics of the spanning tree
+func (p *PrimMST) treeStats(stats *CompareStats, f labelFormat) {
+	var longest float64
+	for _, e := range p.mst {
+		if e != nil && p.weight(e.v, e.w) > longest {
+			longest = p.weight(e.v, e.w)
+		}
+	}
+	distance := p.totalDistance()
+	stats.Distance = f.format(distance)
+	stats.MeanEdge = f.format(distance / float64(len(p.location)-1))
+	stats.MaxEdge = f.format(longest)
+	stats.Tree = p.treeHash()
+}
+
+// plotCompare draws the MST on a grid with the shared endpoints
+func (p *PrimMST) plotCompare(ep Endpoints) []string {
+	g := newGridPlot(ep)
+	for _, e := range p.mst {
+		if e != nil {
+			a, b := p.location[e.v], p.location[e.w]
+			g.line(real(a), imag(a), real(b), imag(b), "edge")
+		}
+	}
+	for _, z := range p.location {
+		g.point(real(z), imag(z), "vertex")
+	}
+	return g.grid
+}
+
+// HTTP handler for /compare connections
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Upload two vertex files from the graph options", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseMultipartForm(2 * maxUpload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plot := CompareT{Uploads: true}
+	status := make([]string, 0)
+	plot.Theme, _ = formChoice(r, "theme", themes)
+	format, err := formLabelFormat(r)
+	if err != nil {
+		status = append(status, err.Error())
+	}
+
+	// Each vertex set gets its own MST, the plots share endpoints fit to both sets
+	var (
+		msts []*PrimMST
+		all  []complex128
+	)
+	for _, field := range []string{"before", "after"} {
+		location, stats, err := uploadVertices(r, field)
+		if err != nil {
+			fmt.Printf("uploadVertices error: %v\n", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		msts = append(msts, compareMST(location, &stats, format))
+		plot.Stats = append(plot.Stats, stats)
+		all = append(all, location...)
+	}
+	ep := fitEndpoints(all, defaultMargin).equalAspect()
+	g := newGridPlot(ep)
+	plot.Xlabel, plot.Ylabel = g.labels(format)
+
+	if len(status) > 0 {
+		plot.Status = strings.Join(status, ", ")
+	} else {
+		plot.Status = format.lang.message(msgSameAxes)
+	}
+
+	sw := newStreamWriter(w, r)
+	if err := tmplCompare.ExecuteTemplate(sw, "gridstart", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(msts[0].plotCompare(ep), columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplCompare.ExecuteTemplate(sw, "gridmiddle", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(msts[1].plotCompare(ep), columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplCompare.ExecuteTemplate(sw, "gridend", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+	}
+}
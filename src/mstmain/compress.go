@@ -0,0 +1,123 @@
+package main
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressWriter compresses the http response body with gzip or deflate
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string         // gzip or deflate
+	w           io.WriteCloser // compressor, nil until the first write or when passing through
+	wroteHeader bool
+}
+
+// acceptEncoding chooses gzip or deflate from the Accept-Encoding request header,
+// honoring the q-values, and returns an empty string if neither is acceptable.  The q-value
+// of * applies only to the codings the header does not name, so "gzip;q=0, *" excludes gzip.
+func acceptEncoding(header string) string {
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if len(coding) > 0 {
+			qs[coding] = q
+		}
+	}
+	best := ""
+	bestq := 0.0
+	// gzip is preferred over deflate for equal q-values
+	for _, coding := range []string{"gzip", "deflate"} {
+		q, ok := qs[coding]
+		if !ok {
+			q = qs["*"]
+		}
+		if q > bestq {
+			best = coding
+			bestq = q
+		}
+	}
+	return best
+}
+
+// WriteHeader decides whether to compress once the handler has set its headers
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	h := cw.Header()
+	// Pass through responses that are already encoded or compressed, and empty bodies
+	if h.Get("Content-Encoding") == "" && h.Get("Content-Type") != "application/zip" &&
+		code != http.StatusNoContent && code != http.StatusNotModified && code != http.StatusPartialContent {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		if cw.encoding == "gzip" {
+			cw.w = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.w, _ = flate.NewWriter(cw.ResponseWriter, flate.DefaultCompression)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+// Write compresses the response body
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.w == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+	return cw.w.Write(b)
+}
+
+// Flush sends the compressed data written so far to the client
+func (cw *compressWriter) Flush() {
+	if f, ok := cw.w.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close finishes the compressed stream
+func (cw *compressWriter) close() error {
+	if cw.w == nil {
+		return nil
+	}
+	return cw.w.Close()
+}
+
+// compress is middleware that compresses responses for clients accepting gzip or deflate
+func compress(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := acceptEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			h.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
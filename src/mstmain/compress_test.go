@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip, deflate", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"br", ""},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", "gzip"},
+		{"*;q=0", ""},
+		{"gzip;q=0, *", "deflate"},
+		{"*, gzip;q=0", "deflate"},
+		{"gzip;q=0, deflate;q=0, *", ""},
+		{"*;q=0, deflate", "deflate"},
+		{"*;q=0.8, gzip;q=0.5", "deflate"},
+		{"GZIP ; q=1.0", "gzip"},
+		{"gzip;q=bad", "gzip"},
+	}
+	for _, tt := range tests {
+		if got := acceptEncoding(tt.header); got != tt.want {
+			t.Errorf("acceptEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// configSetting is a setting of the config file, the flag name and its value
+type configSetting struct {
+	name  string
+	value string
+	line  int
+}
+
+// configValue returns the value of the setting without its quotes and trailing comment
+func configValue(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, `"`):
+		end := 1
+		for ; end < len(s) && s[end] != '"'; end++ {
+			if s[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(s) {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return strconv.Unquote(s[:end+1])
+	case strings.HasPrefix(s, "'"):
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return s[1 : end+1], nil
+	}
+	if i := strings.Index(s, "#"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// parseConfig parses the flat settings of a TOML or YAML config file, the format by the
+// file extension.  A key is a flag name, with underscores for hyphens if you like, and the
+// keys of a TOML table or an indented YAML mapping are prefixed with its name: listen in
+// [api] or under api: is api-listen.  Arrays and deeper nesting are not supported.
+func parseConfig(text, ext string) ([]configSetting, error) {
+	yaml := false
+	switch ext {
+	case ".toml":
+	case ".yaml", ".yml":
+		yaml = true
+	default:
+		return nil, fmt.Errorf("config file extension %q is not .toml, .yaml, or .yml", ext)
+	}
+	var settings []configSetting
+	section := ""
+	for i, line := range strings.Split(text, "\n") {
+		n := i + 1
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		var key, value string
+		if yaml {
+			indented := trimmed != strings.TrimRight(line, "\r")
+			if !indented {
+				section = ""
+			} else if len(section) == 0 {
+				return nil, fmt.Errorf("line %d: indented setting outside a mapping", n)
+			}
+			k, v, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: %q is not key: value", n, trimmed)
+			}
+			key, value = strings.TrimSpace(k), v
+			if !indented && len(strings.TrimSpace(value)) == 0 {
+				section = key + "-"
+				continue
+			}
+			if len(value) > 0 && value[0] != ' ' && value[0] != '\t' {
+				return nil, fmt.Errorf("line %d: %q needs a space after the colon", n, trimmed)
+			}
+		} else {
+			if strings.HasPrefix(trimmed, "[") {
+				end := strings.Index(trimmed, "]")
+				if end < 0 {
+					return nil, fmt.Errorf("line %d: unterminated table %s", n, trimmed)
+				}
+				section = strings.TrimSpace(trimmed[1:end]) + "-"
+				continue
+			}
+			k, v, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: %q is not key = value", n, trimmed)
+			}
+			key, value = strings.TrimSpace(k), v
+		}
+		value, err := configValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		key = strings.ReplaceAll(strings.Trim(section+key, `"'`), "_", "-")
+		settings = append(settings, configSetting{name: key, value: value, line: n})
+	}
+	return settings, nil
+}
+
+// loadConfig sets the flags from the config file, except the flags given on the command
+// line, which override the file
+func loadConfig(fs *flag.FlagSet, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	settings, err := parseConfig(string(b), strings.ToLower(filepath.Ext(path)))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for _, s := range settings {
+		if fs.Lookup(s.name) == nil || s.name == "config" {
+			return fmt.Errorf("%s line %d: unknown setting %s", path, s.line, s.name)
+		}
+		if given[s.name] {
+			continue
+		}
+		if err := fs.Set(s.name, s.value); err != nil {
+			return fmt.Errorf("%s line %d: %s: %v", path, s.line, s.name, err)
+		}
+	}
+	return nil
+}
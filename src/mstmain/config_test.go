@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		text    string
+		want    []configSetting
+		wantErr bool
+	}{
+		{"toml flat", ".toml", "listen = \"127.0.0.1:9090\"\nread_only = true\n", []configSetting{
+			{"listen", "127.0.0.1:9090", 1}, {"read-only", "true", 2}}, false},
+		{"toml table", ".toml", "# comment\ntheme = 'dark'\n\n[api]\nlisten = \":8081\" # trailing\n", []configSetting{
+			{"theme", "dark", 2}, {"api-listen", ":8081", 5}}, false},
+		{"toml escapes", ".toml", `data_dir = "C:\\data \"x\""`, []configSetting{
+			{"data-dir", `C:\data "x"`, 1}}, false},
+		{"toml bare value comment", ".toml", "retention = 24h # a day", []configSetting{
+			{"retention", "24h", 1}}, false},
+		{"toml hash in string", ".toml", `theme = "a#b"`, []configSetting{{"theme", "a#b", 1}}, false},
+		{"toml quoted key", ".toml", `"max_vertices" = 100`, []configSetting{{"max-vertices", "100", 1}}, false},
+		{"toml missing equals", ".toml", "listen", nil, true},
+		{"toml unterminated table", ".toml", "[api\nlisten = 1", nil, true},
+		{"toml unterminated string", ".toml", `listen = "abc`, nil, true},
+		{"yaml flat", ".yaml", "---\nlisten: 127.0.0.1:9090\nread_only: true\n", []configSetting{
+			{"listen", "127.0.0.1:9090", 2}, {"read-only", "true", 3}}, false},
+		{"yaml mapping", ".yml", "api:\n  listen: \":8081\"\n  user: 'bob'\ntheme: dark\n", []configSetting{
+			{"api-listen", ":8081", 2}, {"api-user", "bob", 3}, {"theme", "dark", 4}}, false},
+		{"yaml windows line ends", ".yaml", "theme: dark\r\nlisten: :9090\r\n", []configSetting{
+			{"theme", "dark", 1}, {"listen", ":9090", 2}}, false},
+		{"yaml indented outside a mapping", ".yaml", "  listen: :9090\n", nil, true},
+		{"yaml no space after colon", ".yaml", "theme:dark\n", nil, true},
+		{"yaml missing colon", ".yaml", "theme dark\n", nil, true},
+		{"unknown extension", ".ini", "theme = dark", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseConfig(tt.text, tt.ext)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want an error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: settings %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "primmst.toml")
+	if err := os.WriteFile(path, []byte("theme = \"dark\"\nlisten = \":9090\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("primmst", flag.ContinueOnError)
+	theme := fs.String("theme", "light", "")
+	listen := fs.String("listen", ":8080", "")
+	fs.String("config", "", "")
+	if err := fs.Parse([]string{"-listen", ":7070"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadConfig(fs, path); err != nil {
+		t.Fatal(err)
+	}
+	if *theme != "dark" || *listen != ":7070" {
+		t.Errorf("theme %s, listen %s, want dark from the file and :7070 from the command line", *theme, *listen)
+	}
+
+	for _, text := range []string{"unknown = 1\n", "config = \"other.toml\"\n"} {
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := loadConfig(fs, path); err == nil {
+			t.Errorf("loadConfig of %q gave no error", text)
+		}
+	}
+}
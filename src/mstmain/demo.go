@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	patternDemo       = "/primmstdemo"        // http handler for the Brownian motion demo page
+	patternDemoEvents = "/primmstdemo/events" // http handler for the server-sent events of the demo
+	fileDemo          = "templates/demo.html" // html for the Brownian motion demo
+	defaultInterval   = 1.0                   // default seconds between demo frames
+	minInterval       = 0.1                   // shortest seconds between demo frames
+	defaultSigma      = 0.01                  // default step of the vertices, a fraction of the bounds
+	maxDemoFrames     = 36000                 // frames of a demo stream before it ends
+)
+
+// DemoT is the Brownian motion demo page of the last MST
+type DemoT struct {
+	Theme    string
+	Vertices int
+	Bounds   string  // xmin, xmax, ymin, ymax as JSON for static/demo.js
+	Interval float64 // seconds between frames
+	Sigma    float64 // step of the vertices, a fraction of the bounds
+}
+
+// DemoFrame is a server-sent event of the demo, the MST of the drifted vertices
+type DemoFrame struct {
+	Frame    int          `json:"frame"`
+	Distance float64      `json:"distance"` // MST total distance
+	Changed  int          `json:"changed"`  // MST edges not in the previous frame's MST
+	Nodes    [][2]float64 `json:"nodes"`    // x, y of each vertex
+	Links    [][3]int     `json:"links"`    // v, w, and 1 if the edge changed
+	Start    int          `json:"start"`    // start vertex index
+}
+
+// brownian moves each vertex by a normal step with standard deviation sigma times the
+// bounds in x and y, reflecting the vertices off the bounds
+func (p *PrimMST) brownian(sigma float64) {
+	reflect := func(v, min, max float64) float64 {
+		for v < min || v > max {
+			if v < min {
+				v = 2*min - v
+			}
+			if v > max {
+				v = 2*max - v
+			}
+		}
+		return v
+	}
+	sx, sy := sigma*(p.xmax-p.xmin), sigma*(p.ymax-p.ymin)
+	for i, z := range p.location {
+		x := reflect(real(z)+sx*p.rnd.NormFloat64(), p.xmin, p.xmax)
+		y := reflect(imag(z)+sy*p.rnd.NormFloat64(), p.ymin, p.ymax)
+		p.location[i] = complex(x, y)
+	}
+}
+
+// demoParams gets the seconds between frames and the vertex step from the form
+func demoParams(r *http.Request) (float64, float64, error) {
+	interval, err := formFloat(r, "interval", defaultInterval)
+	if err != nil {
+		return 0, 0, err
+	}
+	sigma, err := formFloat(r, "sigma", defaultSigma)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !(interval >= minInterval && interval <= 60) || !(sigma > 0 && sigma <= 0.5) {
+		return 0, 0, fmt.Errorf("interval %g must be %g-60 seconds and sigma %g must be in (0, 0.5]",
+			interval, minInterval, sigma)
+	}
+	return interval, sigma, nil
+}
+
+// demoFrame finds the MST of the drifted vertices and marks the edges not in the previous MST
+func (p *PrimMST) demoFrame(frame int, before map[Edge]bool) (DemoFrame, error) {
+	if err := p.findDistances(); err != nil {
+		return DemoFrame{}, err
+	}
+	if err := p.solve(); err != nil {
+		return DemoFrame{}, err
+	}
+	f := DemoFrame{Frame: frame, Distance: p.totalDistance(), Start: p.start,
+		Nodes: make([][2]float64, len(p.location)), Links: make([][3]int, 0, len(p.location)-1)}
+	for i, z := range p.location {
+		f.Nodes[i] = [2]float64{real(z), imag(z)}
+	}
+	for _, e := range p.mst {
+		if e == nil {
+			continue
+		}
+		changed := 0
+		if before != nil && !before[e.key()] {
+			changed = 1
+			f.Changed++
+		}
+		f.Links = append(f.Links, [3]int{e.v, e.w, changed})
+	}
+	return f, nil
+}
+
+// HTTP handler for /primmstdemo connections.  A POST of the graph options creates the MST
+// first, otherwise the demo drifts the last MST.
+func handleDemo(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := checkBudget(r); err != nil {
+			writeBudgetError(w, r, err)
+			return
+		}
+		p, status := createMST(r)
+		if len(p.location) == 0 {
+			http.Error(w, strings.Join(status, ", "), http.StatusBadRequest)
+			return
+		}
+		setLatestMST(p)
+	}
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	interval, sigma, err := demoParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bounds, _ := json.Marshal([4]float64{p.xmin, p.xmax, p.ymin, p.ymax})
+	page := DemoT{Theme: p.theme, Vertices: len(p.location), Bounds: string(bounds), Interval: interval, Sigma: sigma}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmplDemo.Execute(w, page); err != nil {
+		fmt.Printf("Write to HTTP output using template with demo error: %v\n", err)
+	}
+}
+
+// HTTP handler for /primmstdemo/events connections.  It streams server-sent frame events of
+// the last MST as its vertices drift by Brownian motion, finding the MST again every interval
+// seconds, until the client disconnects.  The seed makes the drift repeatable.
+func handleDemoEvents(w http.ResponseWriter, r *http.Request) {
+	last := latestMST()
+	if last == nil || len(last.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	interval, sigma, err := demoParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seed, err := formInt(r, "seed", 1)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Drift a copy of the last MST with its own vertices and random numbers
+	d := *last
+	p := &d
+	p.location = append([]complex128(nil), p.location...)
+	p.rnd = rand.New(rand.NewSource(int64(seed)))
+	p.ctx = r.Context()
+	p.meta.Timings = nil
+	p.changed = nil
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // reverse proxies stream the events
+	ticker := time.NewTicker(time.Duration(interval * float64(time.Second)))
+	defer ticker.Stop()
+	var before map[Edge]bool
+	for frame := 0; frame < maxDemoFrames; frame++ {
+		if frame > 0 {
+			p.brownian(sigma)
+		}
+		f, err := p.demoFrame(frame, before)
+		if err != nil {
+			fmt.Fprintf(w, "event: failed\ndata: %s\n\n", strings.ReplaceAll(err.Error(), "\n", " "))
+			flusher.Flush()
+			return
+		}
+		b, err := json.Marshal(f)
+		if err != nil {
+			fmt.Printf("JSON encode error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(w, "event: frame\ndata: %s\n\n", b)
+		flusher.Flush()
+		before = p.edgeSet()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+	fmt.Fprint(w, "event: end\ndata: {}\n\n")
+	flusher.Flush()
+}
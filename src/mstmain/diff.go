@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	patternDiff = "/diff"               // http handler for the MST diff of two stored runs
+	fileDiff    = "templates/diff.html" // html for the MST diff
+)
+
+// DiffT contains the MST diff HTML template actions
+type DiffT struct {
+	Grid   []string      // plotting grid
+	Xlabel []string      // x-axis labels
+	Ylabel []string      // y-axis labels
+	Legend []LegendEntry // layers drawn on the grid
+	A      string        // run A description
+	B      string        // run B description
+	OnlyA  int           // edges only in run A
+	OnlyB  int           // edges only in run B
+	Both   int           // edges in both runs
+	Status string        // status of the diff
+}
+
+// runEdges returns the set of the run's MST edges
+func runEdges(run *Run) map[Edge]bool {
+	edges := make(map[Edge]bool, len(run.Edges))
+	for _, e := range run.Edges {
+		edges[Edge{v: e.V, w: e.W}.key()] = true
+	}
+	return edges
+}
+
+// sameVertices returns an error if the runs are not on the same vertex set
+func sameVertices(a, b *Run) error {
+	if len(a.Vertices) != len(b.Vertices) {
+		return fmt.Errorf("run %s has %d vertices and run %s has %d", a.ID, len(a.Vertices), b.ID, len(b.Vertices))
+	}
+	for i := range a.Vertices {
+		if a.Vertices[i] != b.Vertices[i] {
+			return fmt.Errorf("runs %s and %s have different vertices", a.ID, b.ID)
+		}
+	}
+	return nil
+}
+
+// plotDiff draws the edges only in run A, only in run B, and in both
+func plotDiff(a, b *Run, plot *DiffT) {
+	ep := Endpoints{xmin: a.Xmin, xmax: a.Xmax, ymin: a.Ymin, ymax: a.Ymax}
+	g := newGridPlot(ep)
+	inA, inB := runEdges(a), runEdges(b)
+	line := func(e Edge, class string) {
+		v, w := a.Vertices[e.v], a.Vertices[e.w]
+		g.line(v.X, v.Y, w.X, w.Y, class)
+	}
+	// Draw the shared edges first so the differences are on top
+	for e := range inA {
+		if inB[e] {
+			line(e, "edgeboth")
+			plot.Both++
+		}
+	}
+	for e := range inA {
+		if !inB[e] {
+			line(e, "edgea")
+			plot.OnlyA++
+		}
+	}
+	for e := range inB {
+		if !inA[e] {
+			line(e, "edgeb")
+			plot.OnlyB++
+		}
+	}
+	for _, v := range a.Vertices {
+		g.point(v.X, v.Y, "vertex")
+	}
+	plot.Grid = g.grid
+	plot.Xlabel, plot.Ylabel = g.labels(defaultLabelFormat)
+	plot.Legend = legend(g.grid)
+}
+
+// HTTP handler for /diff connections, the stored runs are the a and b query parameters
+func handleDiff(w http.ResponseWriter, r *http.Request) {
+	var runs []*Run
+	for _, name := range []string{"a", "b"} {
+		run, err := loadRun(r.FormValue(name))
+		if err != nil {
+			fmt.Printf("loadRun error: %v\n", err)
+			http.Error(w, fmt.Sprintf("run %s: %v", name, err), http.StatusNotFound)
+			return
+		}
+		runs = append(runs, run)
+	}
+	a, b := runs[0], runs[1]
+	if err := sameVertices(a, b); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plot := DiffT{A: a.String(), B: b.String()}
+	plotDiff(a, b, &plot)
+	status := make([]string, 0)
+	if plot.OnlyA == 0 && plot.OnlyB == 0 {
+		lang, _, _ := formLocale(r)
+		status = append(status, lang.message(msgIdentical))
+	}
+	if len(a.Metadata.Tree) > 0 && len(b.Metadata.Tree) > 0 {
+		status = append(status, fmt.Sprintf("tree hashes %s and %s", a.Metadata.Tree, b.Metadata.Tree))
+	}
+	plot.Status = strings.Join(status, ", ")
+
+	sw := newStreamWriter(w, r)
+	if err := tmplDiff.ExecuteTemplate(sw, "gridstart", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(plot.Grid, columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplDiff.ExecuteTemplate(sw, "gridend", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"net/http"
+	"strconv"
+)
+
+const patternDistances = "/primmstdistances" // http handler for the distance matrix download
+
+// distance returns the distance between vertices i and j, 0 on the diagonal
+func (p *PrimMST) distance(i, j int) float64 {
+	if i == j {
+		return 0
+	}
+	return p.weight(i, j)
+}
+
+// weight returns the weight of edge v-w, MaxFloat64 when v is w like the distance matrix diagonal
+func (p *PrimMST) weight(v, w int) float64 {
+	d, _ := p.edgeWeight(v, w)
+	return d
+}
+
+// edgeWeight returns the weight of edge v-w from the distance matrix or, for the approximate
+// MST without one, measures it over the terrain, by the weight model of the metric, or as the
+// Euclidean distance
+func (p *PrimMST) edgeWeight(v, w int) (float64, error) {
+	switch {
+	case p.graph != nil:
+		return p.graph[v][w], nil
+	case v == w:
+		return math.MaxFloat64, nil
+	case p.terrain != nil:
+		return p.terrainLength(v, w), nil
+	case !p.euclidean():
+		return p.modelWeight(v, w)
+	}
+	return cmplx.Abs(p.location[v] - p.location[w]), nil
+}
+
+// withMatrix returns p, or for the approximate MST, which weighs only the edges it samples,
+// a copy of p with the distance matrix for the pages that use every distance
+func (p *PrimMST) withMatrix() *PrimMST {
+	if p == nil || p.graph != nil || len(p.location) == 0 {
+		return p
+	}
+	q := *p
+	if err := q.findDistances(); err != nil {
+		fmt.Printf("findDistances error: %v\n", err)
+	}
+	return &q
+}
+
+// writeDistancesCSV writes the distance matrix as CSV, one row per vertex.  The distances
+// use the shortest representation that parses back to the same float64.
+func (p *PrimMST) writeDistancesCSV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, 0, 32)
+	n := len(p.location)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if j > 0 {
+				bw.WriteByte(',')
+			}
+			buf = strconv.AppendFloat(buf[:0], p.distance(i, j), 'g', -1, 64)
+			bw.Write(buf)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// writeDistancesBinary writes the number of vertices as a little-endian uint64 followed by
+// the row-major distance matrix as little-endian float64s
+func (p *PrimMST) writeDistancesBinary(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, 8)
+	n := len(p.location)
+	binary.LittleEndian.PutUint64(buf, uint64(n))
+	bw.Write(buf)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			binary.LittleEndian.PutUint64(buf, math.Float64bits(p.distance(i, j)))
+			bw.Write(buf)
+		}
+	}
+	return bw.Flush()
+}
+
+// HTTP handler for /primmstdistances connections, format=binary downloads the binary matrix,
+// otherwise CSV, and manifest=1 downloads its manifest.  The distances of the approximate MST
+// are measured as they are written.  The compress middleware gzips
+// either one for clients that accept it.
+// The graph query parameter selects a cached graph instead of the last MST.
+func handleDistances(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if id := r.FormValue("graph"); len(id) > 0 {
+		cg, ok := graphs.get(id)
+		if !ok {
+			http.Error(w, "Graph "+id+" is not in the cache", http.StatusNotFound)
+			return
+		}
+		if cg.graph == nil {
+			http.Error(w, "Graph "+id+" has no distance matrix, the approximate MST weighs only the edges it samples",
+				http.StatusNotFound)
+			return
+		}
+		p = &PrimMST{location: make([]complex128, len(cg.graph)), graph: cg.graph}
+		p.meta.Graph = id
+	}
+	if p == nil || len(p.location) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+
+	if r.FormValue("format") == "binary" {
+		p.serveExport(w, r, export{"distances.bin", "application/octet-stream", p.writeDistancesBinary})
+	} else {
+		p.serveExport(w, r, export{"distances.csv", "text/csv; charset=utf-8", p.writeDistancesCSV})
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	editCookie      = "primmst_edits" // cookie identifying the edit history of a browser session
+	maxEditHistory  = 50              // edits kept per session for undo
+	maxEditSessions = 100             // edit histories kept, the least recently used is dropped
+)
+
+// editStep is a graph state in the edit history and the edit that left it
+type editStep struct {
+	state *GraphState
+	label string
+}
+
+// editHistory is the undo and redo stacks of a session's vertex edits.  It follows one
+// graph: editing a different graph starts a new history.
+type editHistory struct {
+	undo  []editStep
+	redo  []editStep
+	graph string    // graph ID after the latest edit, undo, or redo
+	used  time.Time // latest use, for dropping the least recently used history
+}
+
+// editHistories are the edit histories by session
+var editHistories = struct {
+	sync.Mutex
+	sessions map[string]*editHistory
+}{sessions: make(map[string]*editHistory)}
+
+// editSession sets the edit history cookie if the request has none, adding it to the request
+// so that the edit sees the same session on its first request
+func editSession(w http.ResponseWriter, r *http.Request) {
+	if _, err := r.Cookie(editCookie); err == nil {
+		return
+	}
+	c := &http.Cookie{Name: editCookie, Value: randomToken(), Path: "/", HttpOnly: true,
+		SameSite: http.SameSiteLaxMode}
+	http.SetCookie(w, c)
+	r.AddCookie(c)
+}
+
+// sessionHistory returns the edit history of the request's session for the graph ID,
+// starting a new one if the session edited another graph
+func sessionHistory(r *http.Request, graph string) (*editHistory, error) {
+	c, err := r.Cookie(editCookie)
+	if err != nil {
+		return nil, errors.New("vertex edits need cookies for the undo history")
+	}
+	editHistories.Lock()
+	defer editHistories.Unlock()
+	h, ok := editHistories.sessions[c.Value]
+	if !ok || h.graph != graph {
+		h = &editHistory{}
+		editHistories.sessions[c.Value] = h
+	}
+	h.used = time.Now()
+	dropLeastRecent(editHistories.sessions, maxEditSessions, func(h *editHistory) time.Time { return h.used })
+	return h, nil
+}
+
+// editCounts returns the number of edits that can be undone and redone in the session's
+// history of the graph ID
+func editCounts(r *http.Request, graph string) (int, int) {
+	c, err := r.Cookie(editCookie)
+	if err != nil {
+		return 0, 0
+	}
+	editHistories.Lock()
+	defer editHistories.Unlock()
+	h, ok := editHistories.sessions[c.Value]
+	if !ok || h.graph != graph {
+		return 0, 0
+	}
+	return len(h.undo), len(h.redo)
+}
+
+// editOp returns the undo or redo button, or else the add, move, or delete edit selected
+// in the form, empty when there is no edit
+func editOp(r *http.Request) string {
+	if op := r.PostFormValue("edit"); len(op) > 0 {
+		return op
+	}
+	return r.PostFormValue("editop")
+}
+
+// editVertices applies the add, move, or delete edit of the form to a copy of the graph state
+func editVertices(r *http.Request, s *GraphState) (*GraphState, string, error) {
+	next := *s
+	next.Vertices = append([]Point(nil), s.Vertices...)
+	op := editOp(r)
+	vertex := func() (int, error) {
+		v, err := strconv.Atoi(r.PostFormValue("editvertex"))
+		if err != nil {
+			return 0, fmt.Errorf("%s needs a vertex index: %v", op, err)
+		}
+		if v < 0 || v >= len(s.Vertices) {
+			return 0, fmt.Errorf("vertex %d is not in the range 0-%d", v, len(s.Vertices)-1)
+		}
+		return v, nil
+	}
+	location := func() (Point, error) {
+		x, err := strconv.ParseFloat(r.PostFormValue("editx"), 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("%s needs an x coordinate: %v", op, err)
+		}
+		y, err := strconv.ParseFloat(r.PostFormValue("edity"), 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("%s needs a y coordinate: %v", op, err)
+		}
+		if x < s.Xmin || x > s.Xmax || y < s.Ymin || y > s.Ymax {
+			return Point{}, fmt.Errorf("(%g, %g) is outside the graph endpoints", x, y)
+		}
+		return Point{X: x, Y: y}, nil
+	}
+
+	switch op {
+	case "add":
+		if len(s.Vertices) >= maxVertices {
+			return nil, "", fmt.Errorf("the graph already has the maximum %d vertices", maxVertices)
+		}
+		z, err := location()
+		if err != nil {
+			return nil, "", err
+		}
+		next.Vertices = append(next.Vertices, z)
+		return &next, fmt.Sprintf("add vertex %d at (%g, %g)", len(s.Vertices), z.X, z.Y), nil
+	case "move":
+		v, err := vertex()
+		if err != nil {
+			return nil, "", err
+		}
+		z, err := location()
+		if err != nil {
+			return nil, "", err
+		}
+		z.Name = s.Vertices[v].Name
+		next.Vertices[v] = z
+		return &next, fmt.Sprintf("move vertex %d to (%g, %g)", v, z.X, z.Y), nil
+	case "delete":
+		v, err := vertex()
+		if err != nil {
+			return nil, "", err
+		}
+		if len(s.Vertices) <= minVertices {
+			return nil, "", fmt.Errorf("the graph needs at least %d vertices", minVertices)
+		}
+		next.Vertices = append(next.Vertices[:v], next.Vertices[v+1:]...)
+		switch {
+		case next.Start == v:
+			next.Start = 0
+		case next.Start > v:
+			next.Start--
+		}
+		return &next, fmt.Sprintf("delete vertex %d", v), nil
+	}
+	return nil, "", fmt.Errorf("unknown edit %s", op)
+}
+
+// editState applies the form's vertex edit, undo, or redo to the graph state of the graph ID
+// and records it in the session's edit history
+func (p *PrimMST) editState(r *http.Request, graph string, s *GraphState) (*GraphState, error) {
+	h, err := sessionHistory(r, graph)
+	if err != nil {
+		return nil, err
+	}
+	editHistories.Lock()
+	defer editHistories.Unlock()
+
+	var next *GraphState
+	switch editOp(r) {
+	case "undo":
+		if len(h.undo) == 0 {
+			return nil, errors.New("there is no edit to undo")
+		}
+		step := h.undo[len(h.undo)-1]
+		h.undo = h.undo[:len(h.undo)-1]
+		h.redo = append(h.redo, editStep{state: s, label: step.label})
+		next = step.state
+		p.edited = "Undo " + step.label
+	case "redo":
+		if len(h.redo) == 0 {
+			return nil, errors.New("there is no edit to redo")
+		}
+		step := h.redo[len(h.redo)-1]
+		h.redo = h.redo[:len(h.redo)-1]
+		h.undo = append(h.undo, editStep{state: s, label: step.label})
+		next = step.state
+		p.edited = "Redo " + step.label
+	default:
+		var label string
+		if next, label, err = editVertices(r, s); err != nil {
+			return nil, err
+		}
+		h.undo = append(h.undo, editStep{state: s, label: label})
+		if len(h.undo) > maxEditHistory {
+			h.undo = h.undo[len(h.undo)-maxEditHistory:]
+		}
+		h.redo = nil
+		p.edited = "Edit: " + label
+	}
+	if err := p.restoreState(next); err != nil {
+		return nil, err
+	}
+	h.graph = p.graphID()
+	return next, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+// EditDiff is the change in the MST edges made by a vertex edit, undo, or redo
+type EditDiff struct {
+	Removed int // MST edges before the edit that are not in the MST after it
+	Added   int // MST edges after the edit that were not in the MST before it
+	Edges   int // MST edges after the edit
+}
+
+// String formats the edit diff for the status
+func (d EditDiff) String() string {
+	return fmt.Sprintf("the edit removed %d and added %d of %d MST edges", d.Removed, d.Added, d.Edges)
+}
+
+// pointEdge is an edge by the locations of its vertices.  An add or delete renumbers the
+// vertices, so the MSTs before and after an edit are compared by location.
+type pointEdge [2]complex128
+
+// newPointEdge returns the edge with its locations in x, then y order so that a-b and b-a are equal
+func newPointEdge(a, b complex128) pointEdge {
+	if real(b) < real(a) || real(b) == real(a) && imag(b) < imag(a) {
+		a, b = b, a
+	}
+	return pointEdge{a, b}
+}
+
+// findEditDiff finds the MST of the graph before the edit and compares it with the MST,
+// marking the added edges and keeping the removed edges for the editdiff layer
+func (p *PrimMST) findEditDiff() (EditDiff, error) {
+	before := &PrimMST{model: p.model}
+	if err := before.restoreState(p.beforeEdit); err != nil {
+		return EditDiff{}, err
+	}
+	before.graph = p.beforeGraph
+	if before.graph == nil {
+		if err := before.findDistances(); err != nil {
+			return EditDiff{}, err
+		}
+	}
+	parent, _ := solver.Prim(before.graph, before.start)
+	edges := make(map[pointEdge]bool, len(parent))
+	for w, v := range parent {
+		if v >= 0 {
+			edges[newPointEdge(before.location[v], before.location[w])] = true
+		}
+	}
+
+	var d EditDiff
+	p.editAdded = make(map[Edge]bool)
+	for _, e := range p.mst {
+		if e == nil {
+			continue
+		}
+		d.Edges++
+		pe := newPointEdge(p.location[e.v], p.location[e.w])
+		if edges[pe] {
+			delete(edges, pe)
+			continue
+		}
+		p.editAdded[e.key()] = true
+		d.Added++
+	}
+	// The edges left were removed by the edit
+	p.editRemoved = make([]pointEdge, 0, len(edges))
+	for pe := range edges {
+		p.editRemoved = append(p.editRemoved, pe)
+	}
+	d.Removed = len(p.editRemoved)
+	p.layers["editdiff"] = true
+	return d, nil
+}
+
+// drawEditDiff draws the MST edges removed by the edit under the MST.  The edges added by the
+// edit are drawn in the mst layer.
+func drawEditDiff(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for _, pe := range p.editRemoved {
+		g.line(real(pe[0]), imag(pe[0]), real(pe[1]), imag(pe[1]), "removededge")
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	metricCustom  = "Custom" // edge weights from a user expression
+	maxExpression = 256      // longest expression accepted from the form
+)
+
+// Variables of an edge weight expression, evaluated once per vertex pair v < w
+const (
+	varX1 = iota // x of vertex v
+	varY1        // y of vertex v
+	varX2        // x of vertex w
+	varY2        // y of vertex w
+	varDx        // x2 - x1
+	varDy        // y2 - y1
+	varD         // Euclidean distance
+	nvars
+)
+
+var exprVariables = map[string]int{
+	"x1": varX1, "y1": varY1, "x2": varX2, "y2": varY2, "dx": varDx, "dy": varDy, "d": varD,
+}
+
+var exprConstants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+// exprFunc is a function callable from an expression with a fixed number of arguments, at most two
+type exprFunc struct {
+	args int
+	f    func(a [2]float64) float64
+}
+
+var exprFunctions = map[string]exprFunc{
+	"sqrt":  {1, func(a [2]float64) float64 { return math.Sqrt(a[0]) }},
+	"abs":   {1, func(a [2]float64) float64 { return math.Abs(a[0]) }},
+	"exp":   {1, func(a [2]float64) float64 { return math.Exp(a[0]) }},
+	"log":   {1, func(a [2]float64) float64 { return math.Log(a[0]) }},
+	"sin":   {1, func(a [2]float64) float64 { return math.Sin(a[0]) }},
+	"cos":   {1, func(a [2]float64) float64 { return math.Cos(a[0]) }},
+	"tan":   {1, func(a [2]float64) float64 { return math.Tan(a[0]) }},
+	"floor": {1, func(a [2]float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, func(a [2]float64) float64 { return math.Ceil(a[0]) }},
+	"min":   {2, func(a [2]float64) float64 { return math.Min(a[0], a[1]) }},
+	"max":   {2, func(a [2]float64) float64 { return math.Max(a[0], a[1]) }},
+	"pow":   {2, func(a [2]float64) float64 { return math.Pow(a[0], a[1]) }},
+	"hypot": {2, func(a [2]float64) float64 { return math.Hypot(a[0], a[1]) }},
+	"atan2": {2, func(a [2]float64) float64 { return math.Atan2(a[0], a[1]) }},
+}
+
+// expr is a compiled expression evaluated with the variables of one vertex pair
+type expr func(vars *[nvars]float64) float64
+
+// exprParser is a recursive descent parser of arithmetic expressions:
+//
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary }
+//	unary   = ("-" | "+") unary | power
+//	power   = primary [ "^" unary ]
+//	primary = number | variable | constant | function "(" sum { "," sum } ")" | "(" sum ")"
+type exprParser struct {
+	src string
+	pos int
+}
+
+// parseExpr compiles the expression source
+func parseExpr(src string) (expr, error) {
+	if len(src) > maxExpression {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxExpression)
+	}
+	ep := &exprParser{src: src}
+	e, err := ep.sum()
+	if err != nil {
+		return nil, err
+	}
+	if ep.skipSpace(); ep.pos < len(ep.src) {
+		return nil, ep.errorf("unexpected %c", ep.src[ep.pos])
+	}
+	return e, nil
+}
+
+func (ep *exprParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("expression at column %d: %s", ep.pos+1, fmt.Sprintf(format, a...))
+}
+
+func (ep *exprParser) skipSpace() {
+	for ep.pos < len(ep.src) && ep.src[ep.pos] == ' ' {
+		ep.pos++
+	}
+}
+
+// accept consumes the operator c if it is next
+func (ep *exprParser) accept(c byte) bool {
+	ep.skipSpace()
+	if ep.pos < len(ep.src) && ep.src[ep.pos] == c {
+		ep.pos++
+		return true
+	}
+	return false
+}
+
+func (ep *exprParser) sum() (expr, error) {
+	left, err := ep.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case ep.accept('+'):
+			right, err := ep.product()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v *[nvars]float64) float64 { return l(v) + right(v) }
+		case ep.accept('-'):
+			right, err := ep.product()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v *[nvars]float64) float64 { return l(v) - right(v) }
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (ep *exprParser) product() (expr, error) {
+	left, err := ep.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case ep.accept('*'):
+			right, err := ep.unary()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v *[nvars]float64) float64 { return l(v) * right(v) }
+		case ep.accept('/'):
+			right, err := ep.unary()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v *[nvars]float64) float64 { return l(v) / right(v) }
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (ep *exprParser) unary() (expr, error) {
+	if ep.accept('-') {
+		e, err := ep.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(v *[nvars]float64) float64 { return -e(v) }, nil
+	}
+	if ep.accept('+') {
+		return ep.unary()
+	}
+	return ep.power()
+}
+
+func (ep *exprParser) power() (expr, error) {
+	base, err := ep.primary()
+	if err != nil {
+		return nil, err
+	}
+	if !ep.accept('^') {
+		return base, nil
+	}
+	exponent, err := ep.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(v *[nvars]float64) float64 { return math.Pow(base(v), exponent(v)) }, nil
+}
+
+func (ep *exprParser) primary() (expr, error) {
+	ep.skipSpace()
+	if ep.pos == len(ep.src) {
+		return nil, ep.errorf("unexpected end")
+	}
+	if ep.accept('(') {
+		e, err := ep.sum()
+		if err != nil {
+			return nil, err
+		}
+		if !ep.accept(')') {
+			return nil, ep.errorf("missing )")
+		}
+		return e, nil
+	}
+
+	start := ep.pos
+	c := rune(ep.src[ep.pos])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for ep.pos < len(ep.src) && (unicode.IsDigit(rune(ep.src[ep.pos])) || ep.src[ep.pos] == '.') {
+			ep.pos++
+		}
+		// Exponent of a number like 1e-3
+		if ep.pos < len(ep.src) && (ep.src[ep.pos] == 'e' || ep.src[ep.pos] == 'E') {
+			ep.pos++
+			if ep.pos < len(ep.src) && (ep.src[ep.pos] == '-' || ep.src[ep.pos] == '+') {
+				ep.pos++
+			}
+			for ep.pos < len(ep.src) && unicode.IsDigit(rune(ep.src[ep.pos])) {
+				ep.pos++
+			}
+		}
+		number := ep.src[start:ep.pos]
+		x, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			ep.pos = start
+			return nil, ep.errorf("bad number %s", number)
+		}
+		return func(*[nvars]float64) float64 { return x }, nil
+	case unicode.IsLetter(c):
+		for ep.pos < len(ep.src) && (unicode.IsLetter(rune(ep.src[ep.pos])) || unicode.IsDigit(rune(ep.src[ep.pos]))) {
+			ep.pos++
+		}
+		return ep.identifier(strings.ToLower(ep.src[start:ep.pos]))
+	}
+	return nil, ep.errorf("unexpected %c", c)
+}
+
+// identifier compiles a variable, constant, or function call
+func (ep *exprParser) identifier(name string) (expr, error) {
+	if i, ok := exprVariables[name]; ok {
+		return func(v *[nvars]float64) float64 { return v[i] }, nil
+	}
+	if x, ok := exprConstants[name]; ok {
+		return func(*[nvars]float64) float64 { return x }, nil
+	}
+	fn, ok := exprFunctions[name]
+	if !ok {
+		return nil, ep.errorf("unknown name %s", name)
+	}
+	if !ep.accept('(') {
+		return nil, ep.errorf("missing ( after %s", name)
+	}
+	args := make([]expr, 0, fn.args)
+	for {
+		arg, err := ep.sum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !ep.accept(',') {
+			break
+		}
+	}
+	if !ep.accept(')') {
+		return nil, ep.errorf("missing ) after the arguments of %s", name)
+	}
+	if len(args) != fn.args {
+		return nil, ep.errorf("%s takes %d arguments, not %d", name, fn.args, len(args))
+	}
+	return func(v *[nvars]float64) float64 {
+		var a [2]float64
+		for i, arg := range args {
+			a[i] = arg(v)
+		}
+		return fn.f(a)
+	}, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxFacilities   = 32 // most facility vertices
+	facilityClasses = 8  // number of CSS classes for the facility area colors
+)
+
+// Facility distances, the first is the default
+const (
+	facilityMST       = "MST path"
+	facilityEuclidean = "Euclidean"
+)
+
+var facilityDistances = []string{facilityMST, facilityEuclidean}
+
+// Facility is the service area of a facility vertex
+type Facility struct {
+	Vertex   int    `json:"vertex"`   // facility vertex index
+	Name     string `json:"name"`     // facility vertex name, or v and the index when unnamed
+	Class    string `json:"-"`        // CSS class of the area
+	Attached int    `json:"attached"` // vertices attached, including the facility
+	Mean     string `json:"mean"`     // mean attachment distance in the label format
+	Max      string `json:"max"`      // largest attachment distance in the label format
+}
+
+// formFacilities gets the facility vertices, as indexes or names separated by commas or
+// spaces, and the attachment distance from the HTML form
+func (p *PrimMST) formFacilities(r *http.Request) error {
+	text := strings.TrimSpace(r.FormValue("facilities"))
+	var err error
+	if p.attachBy, err = formChoice(r, "facilitydistance", facilityDistances); err != nil {
+		return err
+	}
+	if len(text) == 0 {
+		return nil
+	}
+	p.facilityList = strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == ';' })
+	if len(p.facilityList) > maxFacilities {
+		return fmt.Errorf("%d facilities are more than %d", len(p.facilityList), maxFacilities)
+	}
+	return nil
+}
+
+// facilityVertices resolves the facility list to vertex indexes
+func (p *PrimMST) facilityVertices() ([]int, error) {
+	seen := make(map[int]bool)
+	var facilities []int
+	for _, f := range p.facilityList {
+		v := -1
+		if i, err := strconv.Atoi(f); err == nil && i >= 0 && i < len(p.location) {
+			v = i
+		} else {
+			for i, name := range p.names {
+				if name == f {
+					v = i
+					break
+				}
+			}
+		}
+		if v < 0 {
+			return nil, fmt.Errorf("facility %s is not a vertex index or name", f)
+		}
+		if !seen[v] {
+			seen[v] = true
+			facilities = append(facilities, v)
+		}
+	}
+	return facilities, nil
+}
+
+// findFacilities attaches every vertex to its nearest facility through the MST or in the plane
+func (p *PrimMST) findFacilities() error {
+	facilities, err := p.facilityVertices()
+	if err != nil {
+		return err
+	}
+	n := len(p.location)
+	p.attached = make([]int, n)
+	dist := make([]float64, n)
+	for v := range dist {
+		dist[v] = math.Inf(1)
+		p.attached[v] = -1
+	}
+
+	if p.attachBy == facilityEuclidean {
+		for v, z := range p.location {
+			for i, f := range facilities {
+				if d := cmplx.Abs(z - p.location[f]); d < dist[v] {
+					dist[v], p.attached[v] = d, i
+				}
+			}
+		}
+	} else {
+		// Multi-source shortest paths in the tree, which has a unique path between vertices
+		adj := make([][]int, n)
+		for _, e := range p.mst {
+			if e != nil {
+				adj[e.v] = append(adj[e.v], e.w)
+				adj[e.w] = append(adj[e.w], e.v)
+			}
+		}
+		for i, f := range facilities {
+			dist[f], p.attached[f] = 0, i
+			stack := []int{f}
+			for len(stack) > 0 {
+				v := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, w := range adj[v] {
+					if d := dist[v] + p.weight(v, w); d < dist[w] {
+						dist[w], p.attached[w] = d, i
+						stack = append(stack, w)
+					}
+				}
+			}
+		}
+	}
+
+	p.facilities = make([]Facility, len(facilities))
+	sum := make([]float64, len(facilities))
+	max := make([]float64, len(facilities))
+	for v, i := range p.attached {
+		if i < 0 {
+			continue // not connected to a facility in a partial MST
+		}
+		p.facilities[i].Attached++
+		sum[i] += dist[v]
+		max[i] = math.Max(max[i], dist[v])
+	}
+	for i, f := range facilities {
+		a := &p.facilities[i]
+		a.Vertex, a.Name, a.Class = f, p.name(f), fmt.Sprintf("area%d", i%facilityClasses)
+		if a.Attached > 0 {
+			a.Mean = p.format.format(sum[i] / float64(a.Attached))
+		}
+		a.Max = p.format.format(max[i])
+	}
+	return nil
+}
+
+// drawFacilities colors the vertices by their facility and marks the facilities
+func drawFacilities(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for v, z := range p.location {
+		if i := p.attached[v]; i >= 0 {
+			g.point(real(z), imag(z), p.facilities[i].Class)
+		}
+	}
+	for _, a := range p.facilities {
+		z := p.location[a.Vertex]
+		g.mark(real(z), imag(z), "facilityvertex")
+	}
+	return nil
+}
+
+// drawBoundaries shades the boundaries between the facility areas behind the other layers.
+// Each grid cell belongs to the area of its nearest facility in the Euclidean mode, or of
+// its nearest vertex in the MST path mode.
+func drawBoundaries(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	sites, areas := p.location, p.attached
+	if p.attachBy == facilityEuclidean {
+		sites = make([]complex128, len(p.facilities))
+		areas = make([]int, len(p.facilities))
+		for i, a := range p.facilities {
+			sites[i], areas[i] = p.location[a.Vertex], i
+		}
+	}
+	area := make([]int, g.rows*g.columns)
+	for row := 0; row < g.rows; row++ {
+		y := g.ymax - float64(row)/g.yscale
+		for col := 0; col < g.columns; col++ {
+			z := complex(g.xmin+float64(col)/g.xscale, y)
+			best, nearest := math.Inf(1), -1
+			for i, s := range sites {
+				d := real(z-s)*real(z-s) + imag(z-s)*imag(z-s)
+				if d < best {
+					best, nearest = d, areas[i]
+				}
+			}
+			area[row*g.columns+col] = nearest
+		}
+	}
+	for row := 0; row < g.rows; row++ {
+		for col := 0; col < g.columns; col++ {
+			i := row*g.columns + col
+			if (col+1 < g.columns && area[i] != area[i+1]) || (row+1 < g.rows && area[i] != area[i+g.columns]) {
+				g.background(row, col, "areaboundary")
+			}
+		}
+	}
+	return nil
+}
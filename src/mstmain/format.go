@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Number styles for the axis labels and distances, the first is the default
+const (
+	styleFixed       = "fixed"      // fixed point, 12.34
+	styleScientific  = "scientific" // scientific notation, 1.23e+01
+	styleSI          = "SI"         // SI suffixes, 12.34k
+	defaultPrecision = 2            // digits after the decimal point
+	maxPrecision     = 10           // maximum digits after the decimal point
+)
+
+var labelStyles = []string{styleFixed, styleScientific, styleSI}
+
+// SI prefixes from 1e-24 to 1e24 in steps of 1e3
+var siPrefixes = []string{"y", "z", "a", "f", "p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+
+// labelFormat formats the axis labels and the reported distances
+type labelFormat struct {
+	style     string // fixed, scientific, or SI
+	precision int    // digits after the decimal point
+	lang      locale // decimal and thousands separators and status messages
+	locale    string // locale choice of the form
+}
+
+// defaultLabelFormat is the original fixed %.2f format
+var defaultLabelFormat = labelFormat{style: styleFixed, precision: defaultPrecision, locale: localeAuto}
+
+// formLabelFormat gets the label style, precision, and locale from the HTML form
+func formLabelFormat(r *http.Request) (labelFormat, error) {
+	f := defaultLabelFormat
+	var err error
+	if f.lang, f.locale, err = formLocale(r); err != nil {
+		return f, err
+	}
+	if f.style, err = formChoice(r, "labelstyle", labelStyles); err != nil {
+		return f, err
+	}
+	if f.precision, err = formInt(r, "precision", defaultPrecision); err != nil {
+		return f, err
+	}
+	if f.precision < 0 || f.precision > maxPrecision {
+		err = fmt.Errorf("label precision %d is not in the range 0-%d", f.precision, maxPrecision)
+		f.precision = defaultPrecision
+	}
+	return f, err
+}
+
+// format formats x in the label style and locale
+func (f labelFormat) format(x float64) string {
+	return f.lang.number(f.unlocalized(x))
+}
+
+// unlocalized formats x in the label style
+func (f labelFormat) unlocalized(x float64) string {
+	switch f.style {
+	case styleScientific:
+		return strconv.FormatFloat(x, 'e', f.precision, 64)
+	case styleSI:
+		if x == 0 || math.IsInf(x, 0) || math.IsNaN(x) {
+			return strconv.FormatFloat(x, 'f', f.precision, 64)
+		}
+		// Index of the prefix for the power of 1000 at or below |x|
+		i := int(math.Floor(math.Log10(math.Abs(x))/3)) + 8
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(siPrefixes) {
+			i = len(siPrefixes) - 1
+		}
+		mantissa := x / math.Pow(1000, float64(i-8))
+		// Rounding can carry the mantissa to 1000, use the next prefix
+		rounded, _ := strconv.ParseFloat(strconv.FormatFloat(math.Abs(mantissa), 'f', f.precision, 64), 64)
+		if rounded >= 1000 && i < len(siPrefixes)-1 {
+			i++
+			mantissa /= 1000
+		}
+		return strconv.FormatFloat(mantissa, 'f', f.precision, 64) + siPrefixes[i]
+	default:
+		return strconv.FormatFloat(x, 'f', f.precision, 64)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	svgWidth  = 600 // SVG frame width in pixels, same as the html grid
+	svgHeight = 600 // SVG frame height in pixels, same as the html grid
+)
+
+// svgPoint translates complex coordinates to x,y pixels in the SVG frame
+func (p *PrimMST) svgPoint(z complex128) (float64, float64) {
+	ep := p.plotEndpoints()
+	x := (real(z) - ep.xmin) * svgWidth / (ep.xmax - ep.xmin)
+	y := (ep.ymax - imag(z)) * svgHeight / (ep.ymax - ep.ymin)
+	return x, y
+}
+
+// writeFrame writes an SVG image of the MST with the first nedges edges added by Prim
+func (p *PrimMST) writeFrame(w io.Writer, nedges int) error {
+	_, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		svgWidth, svgHeight, svgWidth, svgHeight)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "<rect width=\"%d\" height=\"%d\" fill=\"white\" stroke=\"black\" stroke-width=\"2\"/>\n",
+		svgWidth, svgHeight)
+
+	// Draw the edges in the order Prim added them, skipping the start vertex at order[0]
+	for _, v := range p.order[1 : nedges+1] {
+		e := p.mst[v]
+		x1, y1 := p.svgPoint(p.location[e.v])
+		x2, y2 := p.svgPoint(p.location[e.w])
+		fmt.Fprintf(w, "<line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" stroke=\"#ddd\" stroke-width=\"2\"/>\n",
+			x1, y1, x2, y2)
+	}
+
+	// Draw all the vertices on top of the edges, the start vertex in green
+	for i, z := range p.location {
+		x, y := p.svgPoint(z)
+		if i == p.start {
+			fmt.Fprintf(w, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"4\" fill=\"#0f0\"/>\n", x, y)
+		} else {
+			fmt.Fprintf(w, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"2\" fill=\"#000\"/>\n", x, y)
+		}
+	}
+
+	p.writeAnnotations(w)
+	_, err = fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// writeFrames writes a zip archive of SVG frames, one per edge added to the MST
+func (p *PrimMST) writeFrames(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	// Frame 0 has the vertices only, frame k has the first k edges
+	for k := 0; k < len(p.order); k++ {
+		f, err := zw.Create(fmt.Sprintf("frame%04d.svg", k))
+		if err != nil {
+			return err
+		}
+		if err := p.writeFrame(f, k); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// HTTP handler for /primmstframes connections, manifest=1 downloads its manifest
+func handleFrames(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+
+	p.serveExport(w, r, export{"primmstframes.zip", "application/zip", p.writeFrames})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+const defaultGraphCache = 32 // default number of graphs kept in memory
+
+// cachedGraph is a generated or uploaded graph with its distance matrix
+type cachedGraph struct {
+	id    string
+	state GraphState  // vertices, endpoints, metric, and the latest start vertex
+	graph [][]float64 // distance matrix, shared read-only
+}
+
+// graphCache is a least recently used cache of graphs by graph ID, so that a new start
+// vertex or perturbation of a recent graph skips reading the state file and the O(V²) distances
+type graphCache struct {
+	mu       sync.Mutex
+	capacity int
+	lru      *list.List               // most recently used at the front
+	entries  map[string]*list.Element // values are *cachedGraph
+}
+
+// graphs is the cache of recent graphs, sized by -graph-cache
+var graphs = newGraphCache(defaultGraphCache)
+
+func newGraphCache(capacity int) *graphCache {
+	return &graphCache{capacity: capacity, lru: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the graph and marks it most recently used
+func (c *graphCache) get(id string) (*cachedGraph, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	cg := *el.Value.(*cachedGraph)
+	return &cg, true
+}
+
+// put adds or replaces the graph and evicts the least recently used beyond the capacity.
+// A nil distance matrix, from the approximate MST, keeps the matrix already cached.
+func (c *graphCache) put(id string, state *GraphState, graph [][]float64) {
+	if c.capacity <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cg := &cachedGraph{id: id, state: *state, graph: graph}
+	if el, ok := c.entries[id]; ok {
+		if graph == nil {
+			cg.graph = el.Value.(*cachedGraph).graph
+		}
+		el.Value = cg
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[id] = c.lru.PushFront(cg)
+	for c.lru.Len() > c.capacity {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*cachedGraph).id)
+	}
+}
+
+// setStart records the latest start vertex of the cached graph
+func (c *graphCache) setStart(id string, start int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[id]; ok {
+		el.Value.(*cachedGraph).state.Start = start
+	}
+}
+
+// graphID identifies the graph by a hash of its endpoints, vertices, and edge weights,
+// but not its start vertex
+func (p *PrimMST) graphID() string {
+	s := p.state()
+	s.Start, s.Seed, s.Algorithm = 0, 0, ""
+	b, err := json.Marshal(struct {
+		*GraphState
+		Expression string
+	}{s, p.meta.Expression})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// cachedState returns the cached graph state and distances of the graph ID, or the
+// state file and no distances if the graph is no longer cached and the state file is
+// still that graph
+func cachedState(id string) (*GraphState, [][]float64, error) {
+	if cg, ok := graphs.get(id); ok {
+		return &cg.state, cg.graph, nil
+	}
+	if readOnly {
+		return nil, nil, fmt.Errorf("graph %s is no longer in memory, generate a new graph", id)
+	}
+	state, err := loadState()
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.Graph != id {
+		return nil, nil, fmt.Errorf("graph %s is no longer in memory, generate a new graph", id)
+	}
+	return state, nil, nil
+}
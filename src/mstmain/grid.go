@@ -0,0 +1,166 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+const alphaLevels = 4 // number of CSS opacity classes of the density-based alpha
+
+// gridPlot rasterizes points and lines in the Euclidean graph onto the rows x columns html grid
+type gridPlot struct {
+	Endpoints          // Euclidean graph endpoints mapped to the grid
+	grid      []string // CSS class of each grid cell
+	xscale    float64  // columns per unit x
+	yscale    float64  // rows per unit y
+	locked    []bool   // cells that later layers do not overwrite, nil until lock
+	changed   []int    // cells colored since lock that have not been streamed
+	bg        []bool   // background cells that are not locked, nil without a background
+	occupied  [][]int  // vertices rasterized into each cell, nil until a vertex is drawn
+	rows      int      // #rows in the grid
+	columns   int      // #columns in the grid
+}
+
+// newGridPlot creates an empty grid of the default size for the endpoints
+func newGridPlot(ep Endpoints) *gridPlot {
+	return newGridPlotSize(ep, rows, columns)
+}
+
+// newGridPlotSize creates an empty grid with rows x columns cells for the endpoints
+func newGridPlotSize(ep Endpoints, rows, columns int) *gridPlot {
+	return &gridPlot{
+		Endpoints: ep,
+		grid:      make([]string, rows*columns),
+		xscale:    float64(columns-1) / (ep.xmax - ep.xmin),
+		yscale:    float64(rows-1) / (ep.ymax - ep.ymin),
+		rows:      rows,
+		columns:   columns,
+	}
+}
+
+// rowCol translates x,y coordinates to the grid row and column
+func (g *gridPlot) rowCol(x, y float64) (int, int) {
+	row := int((g.ymax-y)*g.yscale + .5)
+	col := int((x-g.xmin)*g.xscale + .5)
+	return row, col
+}
+
+// set colors the grid cell at row, col if it is inside the grid
+func (g *gridPlot) set(row, col int, class string) {
+	if row >= 0 && row < g.rows && col >= 0 && col < g.columns {
+		i := row*g.columns + col
+		if g.locked != nil {
+			if g.locked[i] {
+				return
+			}
+			g.changed = append(g.changed, i)
+		}
+		g.grid[i] = class
+		if g.bg != nil {
+			g.bg[i] = false
+		}
+	}
+}
+
+// background colors the grid cell at row, col as a background that lock does not keep on top
+func (g *gridPlot) background(row, col int, class string) {
+	if g.bg == nil {
+		g.bg = make([]bool, len(g.grid))
+	}
+	i := row*g.columns + col
+	g.grid[i] = class
+	g.bg[i] = true
+}
+
+// lock keeps the cells colored so far on top of later layers and starts recording
+// the cells colored by the later layers so they can be streamed to the client
+func (g *gridPlot) lock() {
+	g.locked = make([]bool, len(g.grid))
+	for i, class := range g.grid {
+		g.locked[i] = len(class) > 0 && (g.bg == nil || !g.bg[i])
+	}
+	g.changed = nil
+}
+
+// point colors the grid cell at x,y
+func (g *gridPlot) point(x, y float64, class string) {
+	row, col := g.rowCol(x, y)
+	g.set(row, col, class)
+}
+
+// mark colors a plus sign of grid cells centered at x,y
+func (g *gridPlot) mark(x, y float64, class string) {
+	row, col := g.rowCol(x, y)
+	g.set(row, col, class)
+	g.set(row+1, col, class)
+	g.set(row-1, col, class)
+	g.set(row, col+1, class)
+	g.set(row, col-1, class)
+}
+
+// line colors the grid cells on the line from x1,y1 to x2,y2
+func (g *gridPlot) line(x1, y1, x2, y2 float64, class string) {
+	g.lineCells(x1, y1, x2, y2, func(row, col int) { g.set(row, col, class) })
+}
+
+// lineCells calls cell with the row and column of each grid cell on the line from x1,y1
+// to x2,y2, possibly more than once
+func (g *gridPlot) lineCells(x1, y1, x2, y2 float64, cell func(row, col int)) {
+	row1, col1 := g.rowCol(x1, y1)
+	row2, col2 := g.rowCol(x2, y2)
+	ncells := int(math.Max(math.Abs(float64(row2-row1)), math.Abs(float64(col2-col1)))) + 1
+	for i := 0; i <= ncells; i++ {
+		t := float64(i) / float64(ncells)
+		cell(g.rowCol(x1+t*(x2-x1), y1+t*(y2-y1)))
+	}
+}
+
+// densityLines colors the grid cells on the lines, each x1,y1,x2,y2, with the class and an
+// opacity class alpha0 (faint) to alpha3 (opaque) by the number of lines through the cell
+// on a log scale up to the densest cell, so overlapping lines show their density instead
+// of a solid block
+func (g *gridPlot) densityLines(lines [][4]float64, class string) {
+	count := make([]int, len(g.grid))
+	stamp := make([]int, len(g.grid)) // line+1 that last counted the cell
+	most := 0
+	for k, l := range lines {
+		g.lineCells(l[0], l[1], l[2], l[3], func(row, col int) {
+			if row < 0 || row >= g.rows || col < 0 || col >= g.columns {
+				return
+			}
+			i := row*g.columns + col
+			if stamp[i] != k+1 {
+				stamp[i] = k + 1
+				count[i]++
+				if count[i] > most {
+					most = count[i]
+				}
+			}
+		})
+	}
+	for i, c := range count {
+		if c == 0 {
+			continue
+		}
+		level := alphaLevels - 1
+		if most > 1 {
+			level = int(alphaLevels * math.Log(float64(c)) / math.Log(float64(most+1)))
+		}
+		g.set(i/g.columns, i%g.columns, class+" alpha"+strconv.Itoa(level))
+	}
+}
+
+// labels constructs the x-axis and y-axis labels in the label format
+func (g *gridPlot) labels(f labelFormat) ([]string, []string) {
+	xlabel := make([]string, xlabels)
+	ylabel := make([]string, ylabels)
+	incr := (g.xmax - g.xmin) / (xlabels - 1)
+	for i := range xlabel {
+		xlabel[i] = f.format(g.xmin + float64(i)*incr)
+	}
+	incr = (g.ymax - g.ymin) / (ylabels - 1)
+	for i := range ylabel {
+		ylabel[i] = f.format(g.ymin + float64(i)*incr)
+	}
+	return xlabel, ylabel
+}
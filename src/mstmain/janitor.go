@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// keepFiles are data files that are configuration or the current graph rather than
+// artifacts and never pruned
+var keepFiles = map[string]bool{
+	filePresets: true,
+	fileState:   true,
+}
+
+// pruneDataFiles removes the files in dir that were last modified before the
+// retention period and any directories left empty, returning the number of files removed
+func pruneDataFiles(dir string, retention time.Duration) (int, error) {
+	cutoff := time.Now().Add(-retention)
+	removed := 0
+	dirs := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if keepFiles[d.Name()] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	// Remove the empty directories, deepest first, ignoring the non-empty ones
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+	return removed, err
+}
+
+// janitor prunes the data directory every interval, a zero retention keeps the files forever
+func janitor(dir string, retention, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		removed, err := pruneDataFiles(dir, retention)
+		if err != nil {
+			fmt.Printf("pruneDataFiles error: %v\n", err)
+		}
+		if removed > 0 {
+			fmt.Printf("Janitor removed %d data files older than %v.\n", removed, retention)
+		}
+		<-ticker.C
+	}
+}
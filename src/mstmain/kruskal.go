@@ -0,0 +1,240 @@
+package main
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kruskal algorithm names in the algorithms list
+const (
+	algorithmKruskal         = "Kruskal"
+	algorithmKruskalParallel = "Kruskal parallel"
+)
+
+// WeightedEdge is a candidate edge for Kruskal's algorithm
+type WeightedEdge struct {
+	Edge
+	distance float64
+}
+
+// unionFind is a disjoint set forest with path halving and union by size
+type unionFind struct {
+	parent []int
+	size   []int
+	finds  int // find operations
+	unions int // union operations
+}
+
+func newUnionFind(n int) *unionFind {
+	uf := &unionFind{parent: make([]int, n), size: make([]int, n)}
+	for i := range uf.parent {
+		uf.parent[i] = i
+		uf.size[i] = 1
+	}
+	return uf
+}
+
+// find returns the root of the set containing v
+func (uf *unionFind) find(v int) int {
+	uf.finds++
+	for uf.parent[v] != v {
+		uf.parent[v] = uf.parent[uf.parent[v]]
+		v = uf.parent[v]
+	}
+	return v
+}
+
+// union merges the sets containing v and w and returns false if they were already the same set
+func (uf *unionFind) union(v, w int) bool {
+	uf.unions++
+	rv, rw := uf.find(v), uf.find(w)
+	if rv == rw {
+		return false
+	}
+	if uf.size[rv] < uf.size[rw] {
+		rv, rw = rw, rv
+	}
+	uf.parent[rw] = rv
+	uf.size[rv] += uf.size[rw]
+	return true
+}
+
+// count adds the union-find operations to ops
+func (uf *unionFind) count(ops *Operations) {
+	ops.Find += uf.finds
+	ops.Union += uf.unions
+}
+
+// candidateEdges returns every edge of the complete graph
+func (p *PrimMST) candidateEdges() []WeightedEdge {
+	n := len(p.location)
+	edges := make([]WeightedEdge, 0, n*(n-1)/2)
+	for v := 0; v < n; v++ {
+		for w := v + 1; w < n; w++ {
+			edges = append(edges, WeightedEdge{Edge: Edge{v: v, w: w}, distance: p.graph[v][w]})
+		}
+	}
+	return edges
+}
+
+// sortEdges sorts the edges by increasing distance
+func sortEdges(edges []WeightedEdge) {
+	sort.Slice(edges, func(i, j int) bool { return edges[i].distance < edges[j].distance })
+}
+
+// mergeEdges merges the sorted slices a and b into dst
+func mergeEdges(dst, a, b []WeightedEdge) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if b[j].distance < a[i].distance {
+			dst[k] = b[j]
+			j++
+		} else {
+			dst[k] = a[i]
+			i++
+		}
+		k++
+	}
+	k += copy(dst[k:], a[i:])
+	copy(dst[k:], b[j:])
+}
+
+// parallelSortEdges sorts chunks of the edges concurrently, one per CPU, then merges
+// pairs of adjacent sorted runs concurrently until one run remains
+func parallelSortEdges(edges []WeightedEdge) {
+	chunks := runtime.NumCPU()
+	if chunks < 2 || len(edges) < 2*chunks {
+		sortEdges(edges)
+		return
+	}
+
+	// Boundaries of the sorted runs
+	bounds := make([]int, chunks+1)
+	for i := range bounds {
+		bounds[i] = i * len(edges) / chunks
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < chunks; i++ {
+		wg.Add(1)
+		go func(run []WeightedEdge) {
+			defer wg.Done()
+			sortEdges(run)
+		}(edges[bounds[i]:bounds[i+1]])
+	}
+	wg.Wait()
+
+	// Merge adjacent runs back and forth between edges and a buffer
+	src, dst := edges, make([]WeightedEdge, len(edges))
+	for len(bounds) > 2 {
+		merged := []int{0}
+		for i := 0; i+1 < len(bounds); i += 2 {
+			lo := bounds[i]
+			if i+2 < len(bounds) {
+				mid, hi := bounds[i+1], bounds[i+2]
+				wg.Add(1)
+				go func(lo, mid, hi int) {
+					defer wg.Done()
+					mergeEdges(dst[lo:hi], src[lo:mid], src[mid:hi])
+				}(lo, mid, hi)
+				merged = append(merged, hi)
+			} else {
+				// Odd run out is copied to the destination unchanged
+				hi := bounds[i+1]
+				copy(dst[lo:hi], src[lo:hi])
+				merged = append(merged, hi)
+			}
+		}
+		wg.Wait()
+		src, dst = dst, src
+		bounds = merged
+	}
+	if &src[0] != &edges[0] {
+		copy(edges, src)
+	}
+}
+
+// findKruskal finds the MST using Kruskal's algorithm, sorting the candidate edges
+// concurrently if parallel is true
+func (p *PrimMST) findKruskal(parallel bool) error {
+	n := len(p.location)
+	edges := p.candidateEdges()
+
+	if parallel {
+		// Time the single-threaded sort on a copy to show the speedup
+		sequential := make([]WeightedEdge, len(edges))
+		copy(sequential, edges)
+		start := time.Now()
+		sortEdges(sequential)
+		p.meta.timePhase("sort (sequential)", start)
+
+		start = time.Now()
+		parallelSortEdges(edges)
+		p.meta.timePhase("sort (parallel)", start)
+	} else {
+		start := time.Now()
+		sortEdges(edges)
+		p.meta.timePhase("sort", start)
+	}
+
+	p.countOperations(p.meta.Algorithm, len(edges))
+	p.rootTree(kruskal(n, edges, p.ops))
+	return nil
+}
+
+// kruskal returns the edges, sorted by increasing distance, that do not make a cycle,
+// adding the edges scanned and union-find operations to ops.  The result is a spanning
+// tree if the edges connect all n vertices, otherwise a forest.
+func kruskal(n int, edges []WeightedEdge, ops *Operations) []Edge {
+	uf := newUnionFind(n)
+	defer uf.count(ops)
+	added := make([]Edge, 0, n-1)
+	for _, e := range edges {
+		ops.Scanned++
+		if uf.union(e.v, e.w) {
+			added = append(added, e.Edge)
+			if len(added) == n-1 {
+				break
+			}
+		}
+	}
+	return added
+}
+
+// rootTree roots the spanning tree at the start vertex so that p.mst holds the edge to
+// each vertex's parent, and p.order lists the start vertex followed by the child vertex
+// of each edge in the order they were added
+func (p *PrimMST) rootTree(added []Edge) {
+	n := len(p.location)
+	p.mst = make(MST, n)
+	adj := make([][]int, n)
+	for _, e := range added {
+		adj[e.v] = append(adj[e.v], e.w)
+		adj[e.w] = append(adj[e.w], e.v)
+	}
+	visited := make([]bool, n)
+	visited[p.start] = true
+	queue := []int{p.start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range adj[v] {
+			if !visited[w] {
+				visited[w] = true
+				p.mst[w] = &Edge{v: v, w: w}
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	p.order = make([]int, 0, n)
+	p.order = append(p.order, p.start)
+	for _, e := range added {
+		if p.mst[e.w] != nil && p.mst[e.w].v == e.v {
+			p.order = append(p.order, e.w)
+		} else {
+			p.order = append(p.order, e.v)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"math/cmplx"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+// plotLayer is a layer of the MST plot.  Front layers are drawn into the grid before it is
+// streamed and stack over every streamed layer.  The other layers are streamed as they are
+// rasterized, highest first, each locked so lower layers do not overwrite it.
+type plotLayer struct {
+	name  string // form value of the layer checkbox
+	label string // checkbox label, empty for layers enabled by other options
+	z     int    // stacking order, higher layers are drawn over lower layers
+	front bool   // drawn before the grid is streamed
+	draw  func(p *PrimMST, g *gridPlot, w *streamWriter) error
+}
+
+// LayerChoice is a layer checkbox in the graph options form
+type LayerChoice struct {
+	Name     string
+	Label    string
+	Selected bool
+	Notes    []string // sizes and summaries of the layer when it was drawn
+}
+
+// plotLayers are the layers of the MST plot in checkbox order
+var plotLayers = []plotLayer{
+	{"vertices", "Vertices", 80, true, drawVertices},
+	{"mst", "MST", 50, false, drawMST},
+	{"hull", "Convex hull", 30, false, drawHull},
+	{"delaunay", "Delaunay triangulation", 10, false, drawDelaunay},
+	{"gabriel", "Gabriel graph", 12, false, drawGabriel},
+	{"rng", "RNG", 14, false, drawRNG},
+	{"tour", "MST preorder tour", 40, false, drawTour},
+	{"orderpath", "Prim order path", 20, false, drawOrderPath},
+	{"clusters", "MST clusters", 85, true, drawClusters},
+	{"stretch", "Path stretch", 87, true, drawStretch},
+	{"outliers", "", 88, true, drawOutliers},
+	{"annotations", "", 89, true, drawAnnotations},
+	{"start", "", 90, true, drawStart},
+	{"prize", "", 55, false, drawPrize},
+	{"skipped", "", 86, true, drawSkipped},
+	{"facilities", "", 84, true, drawFacilities},
+	{"boundaries", "", 1, true, drawBoundaries},
+	{"editdiff", "", 45, false, drawEditDiff},
+}
+
+// defaultLayers are drawn when the form selects no layers
+var defaultLayers = []string{"vertices", "mst"}
+
+// defaultLayerSet returns the default layers as a set
+func defaultLayerSet() map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range defaultLayers {
+		set[name] = true
+	}
+	return set
+}
+
+// layerChoices creates the layer checkboxes with the selected layers checked
+func layerChoices(selected map[string]bool) []LayerChoice {
+	var c []LayerChoice
+	for _, l := range plotLayers {
+		if len(l.label) > 0 {
+			c = append(c, LayerChoice{Name: l.name, Label: l.label, Selected: selected[l.name]})
+		}
+	}
+	return c
+}
+
+// formLayers gets the layer checkboxes from the HTML form.  The overlay and orderpath
+// form values of earlier versions also select their layers.
+func (p *PrimMST) formLayers(r *http.Request) error {
+	p.layers = map[string]bool{"start": true, "outliers": p.outlierSigma != nil,
+		"prize": p.prize != nil, "skipped": p.prize != nil,
+		"facilities": len(p.facilityList) > 0, "boundaries": len(p.facilityList) > 0,
+		"annotations": len(p.annotations) > 0}
+	names := r.Form["layer"]
+	if len(names) == 0 {
+		names = defaultLayers
+	}
+	var err error
+	for _, name := range names {
+		found := false
+		for _, l := range plotLayers {
+			if l.name == name && len(l.label) > 0 {
+				found = true
+			}
+		}
+		if !found {
+			err = fmt.Errorf("unknown layer %s", name)
+			continue
+		}
+		p.layers[name] = true
+	}
+	overlay, e := formChoice(r, "overlay", overlays)
+	if e != nil && err == nil {
+		err = e
+	}
+	switch overlay {
+	case proximityGabriel:
+		p.layers["gabriel"] = true
+	case proximityRNG:
+		p.layers["rng"] = true
+	}
+	if len(r.FormValue("orderpath")) > 0 {
+		p.layers["orderpath"] = true
+	}
+	return err
+}
+
+// compose draws the selected layers in z-order.  The front layers are drawn lowest
+// first and streamed with the grid, then the other layers are streamed highest first.
+func (p *PrimMST) compose(g *gridPlot, w *streamWriter) error {
+	var front, back []plotLayer
+	for _, l := range plotLayers {
+		if !p.layers[l.name] {
+			continue
+		}
+		if l.front {
+			front = append(front, l)
+		} else {
+			back = append(back, l)
+		}
+	}
+	sort.SliceStable(front, func(i, j int) bool { return front[i].z < front[j].z })
+	sort.SliceStable(back, func(i, j int) bool { return back[i].z > back[j].z })
+
+	for _, l := range front {
+		if err := l.draw(p, g, w); err != nil {
+			return err
+		}
+	}
+
+	// Stream the grid rows, flushing periodically
+	if err := w.writeGrid(g.grid, g.columns); err != nil {
+		return err
+	}
+
+	for _, l := range back {
+		g.lock()
+		start := time.Now()
+		if err := l.draw(p, g, w); err != nil {
+			return err
+		}
+		if l.name != "mst" {
+			p.meta.timePhase(l.name+" layer", start)
+		}
+		if err := w.writeCells(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawPairs draws the edges between the vertex pairs, with the density-based alpha when
+// it was requested for dense layers
+func (p *PrimMST) drawPairs(g *gridPlot, pairs [][2]int, class string) {
+	if !p.densityAlpha {
+		for _, e := range pairs {
+			a, b := p.location[e[0]], p.location[e[1]]
+			g.line(real(a), imag(a), real(b), imag(b), class)
+		}
+		return
+	}
+	lines := make([][4]float64, len(pairs))
+	for i, e := range pairs {
+		a, b := p.location[e[0]], p.location[e[1]]
+		lines[i] = [4]float64{real(a), imag(a), real(b), imag(b)}
+	}
+	g.densityLines(lines, class)
+}
+
+// drawVertices draws the vertices, colored by the bipartition or Prim order when requested.
+// CSS selectors for background-color are "vertex", "startvertex", and "edge".
+func drawVertices(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for v, z := range p.location {
+		g.vertex(v, real(z), imag(z), p.classOf(v))
+	}
+	return nil
+}
+
+// drawClusters colors the vertices by MST cluster over the vertex colors
+func drawClusters(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	p.plotClusters(g)
+	p.note("clusters", p.clusterSummary())
+	return nil
+}
+
+// drawOutliers marks the outliers, whose only MST edge is anomalously long
+func drawOutliers(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for _, o := range p.outliers {
+		z := p.location[o.Vertex]
+		g.mark(real(z), imag(z), "outliervertex")
+	}
+	return nil
+}
+
+// drawStart marks the MST start vertex.  CSS colors the vertex green.
+func drawStart(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	z := p.location[p.start]
+	g.mark(real(z), imag(z), "startvertex")
+	return nil
+}
+
+// drawMST draws the MST edges, streaming the edges rasterized so far every flushEdges edges.
+// CSS colors the edge gray, orange if it changed in the perturbation experiment, or teal if
+// the vertex edit added it.
+func drawMST(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	nedges := 0
+	for _, e := range p.mst {
+		// The start vertex has no edge to it
+		if e == nil {
+			continue
+		}
+		class := "edge"
+		switch {
+		case p.changed[e.key()]:
+			class = "changededge"
+		case p.editAdded[e.key()]:
+			class = "addededge"
+		}
+		a := p.location[e.v]
+		b := p.location[e.w]
+		g.line(real(a), imag(a), real(b), imag(b), class)
+
+		nedges++
+		if nedges%flushEdges == 0 {
+			if err := w.writeCells(g); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// drawHull draws the convex hull of the vertices
+func drawHull(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	hull := solver.ConvexHull(p.location)
+	var perimeter float64
+	for i, v := range hull {
+		a := p.location[v]
+		b := p.location[hull[(i+1)%len(hull)]]
+		g.line(real(a), imag(a), real(b), imag(b), "hull")
+		perimeter += cmplx.Abs(b - a)
+	}
+	p.note("hull", fmt.Sprintf("convex hull: %d vertices, perimeter %s",
+		len(hull), p.format.format(perimeter)))
+	return nil
+}
+
+// drawDelaunay draws the Delaunay triangulation of the vertices
+func drawDelaunay(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	pairs := solver.Delaunay(p.location)
+	p.drawPairs(g, pairs, "delaunayedge")
+	p.note("delaunay", p.proximitySummary("Delaunay", len(pairs)))
+	return nil
+}
+
+// drawGabriel draws the Gabriel graph
+func drawGabriel(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	p.plotOverlay(g, proximityGabriel)
+	return nil
+}
+
+// drawRNG draws the relative neighborhood graph
+func drawRNG(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	p.plotOverlay(g, proximityRNG)
+	return nil
+}
+
+// drawTour draws the tour visiting the vertices in MST preorder
+func drawTour(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	tour := p.preorderTour()
+	pairs := make([][2]int, len(tour))
+	for i, v := range tour {
+		pairs[i] = [2]int{v, tour[(i+1)%len(tour)]}
+	}
+	p.drawPairs(g, pairs, "tour")
+	p.note("tour", fmt.Sprintf("MST preorder tour: length %s",
+		p.format.format(p.tourLength(tour))))
+	return nil
+}
+
+// drawOrderPath draws the Prim insertion order path
+func drawOrderPath(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	p.plotOrderPath(g)
+	return nil
+}
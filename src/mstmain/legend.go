@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LegendEntry is a plot layer shown in the legend, Class is the CSS class of its grid cells
+type LegendEntry struct {
+	Class string
+	Label string
+}
+
+// legendEntries returns the labels of the grid CSS classes in legend order
+func legendEntries() []LegendEntry {
+	entries := []LegendEntry{
+		{"startvertex", "start vertex"},
+		{"vertex", "vertex"},
+		{"outliervertex", "outlier vertex"},
+		{"skippedvertex", "vertex skipped for its prize"},
+		{"evenvertex", "even depth vertex"},
+		{"oddvertex", "odd depth vertex"},
+		{"facilityvertex", "facility"},
+	}
+	for i := 0; i < facilityClasses; i++ {
+		entries = append(entries, LegendEntry{fmt.Sprintf("area%d", i), fmt.Sprintf("facility area %d", i+1)})
+	}
+	for i := 0; i < clusterClasses; i++ {
+		entries = append(entries, LegendEntry{fmt.Sprintf("cluster%d", i), fmt.Sprintf("cluster %d", i+1)})
+	}
+	for i := 0; i <= len(stretchBounds); i++ {
+		label := fmt.Sprintf("stretch over %g", stretchBounds[len(stretchBounds)-1])
+		if i < len(stretchBounds) {
+			label = fmt.Sprintf("stretch up to %g", stretchBounds[i])
+		}
+		entries = append(entries, LegendEntry{fmt.Sprintf("stretch%d", i), label})
+	}
+	for i := 0; i < orderBuckets; i++ {
+		entries = append(entries, LegendEntry{fmt.Sprintf("order%d", i),
+			fmt.Sprintf("added %d-%d%%", i*100/orderBuckets, (i+1)*100/orderBuckets)})
+	}
+	for i := 0; i < sweepBuckets; i++ {
+		entries = append(entries, LegendEntry{fmt.Sprintf("sweep%d", i),
+			fmt.Sprintf("first k edge, %d-%d%% of the most frequent", i*100/sweepBuckets, (i+1)*100/sweepBuckets)})
+	}
+	return append(entries,
+		LegendEntry{"edge", "MST edge"},
+		LegendEntry{"edgea", "edge only in run A"},
+		LegendEntry{"edgeb", "edge only in run B"},
+		LegendEntry{"edgeboth", "edge in both runs"},
+		LegendEntry{"changededge", "edge changed by perturbation"},
+		LegendEntry{"addededge", "edge added by the edit"},
+		LegendEntry{"removededge", "edge removed by the edit"},
+		LegendEntry{"prizeedge", "prize-collecting tree edge"},
+		LegendEntry{"orderpath", "Prim order path"},
+		LegendEntry{"tour", "tour"},
+		LegendEntry{"hull", "convex hull"},
+		LegendEntry{"delaunayedge", "Delaunay edge"},
+		LegendEntry{"gabrieledge", "Gabriel graph edge"},
+		LegendEntry{"rngedge", "RNG edge"},
+		LegendEntry{"letterbox", "outside the bounds"},
+		LegendEntry{"elev0", "low elevation"},
+		LegendEntry{"elev9", "high elevation"},
+		LegendEntry{"contour", "elevation contour"},
+		LegendEntry{"areaboundary", "facility area boundary"},
+		LegendEntry{"annotation", "annotation"},
+	)
+}
+
+// legend returns the legend entries of the layers drawn on the grid
+func legend(grid []string) []LegendEntry {
+	drawn := make(map[string]bool)
+	for _, class := range grid {
+		// The first class of a cell is its layer, the others modify its style
+		if i := strings.IndexByte(class, ' '); i >= 0 {
+			class = class[:i]
+		}
+		drawn[class] = true
+	}
+	var entries []LegendEntry
+	for _, e := range legendEntries() {
+		if drawn[e.Class] {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
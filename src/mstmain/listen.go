@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+const (
+	unixPrefix = "unix:" // listen address prefix of a Unix domain socket path
+	socketMode = 0660    // permissions of the Unix domain socket, for a reverse proxy in the group
+)
+
+// listen opens the listener of the address: host:port, [ipv6]:port, a bare IP address on
+// the default port, or unix:/path for a Unix domain socket.  A stale socket file left by an
+// earlier server is removed first.
+func listen(address string) (net.Listener, error) {
+	if strings.HasPrefix(address, unixPrefix) {
+		path := address[len(unixPrefix):]
+		if len(path) == 0 {
+			return nil, fmt.Errorf("listen address %s needs a socket path", address)
+		}
+		if fi, err := os.Lstat(path); err == nil {
+			if fi.Mode()&os.ModeSocket == 0 {
+				return nil, fmt.Errorf("%s exists and is not a socket", path)
+			}
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, socketMode); err != nil {
+			ln.Close()
+			return nil, err
+		}
+		return ln, nil
+	}
+
+	// An IPv6 address needs brackets with a port, a bare address gets the default port
+	if ip := net.ParseIP(strings.Trim(address, "[]")); ip != nil {
+		_, port, _ := net.SplitHostPort(addr)
+		address = net.JoinHostPort(ip.String(), port)
+	}
+	return net.Listen("tcp", address)
+}
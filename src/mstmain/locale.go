@@ -0,0 +1,164 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const localeAuto = "auto" // locale from the browser's Accept-Language header
+
+// locale is the number format and status messages of a language.  The zero locale
+// formats numbers as Go does and keeps the English messages.
+type locale struct {
+	name     string            // language subtag, empty for the zero locale
+	decimal  string            // decimal separator
+	group    string            // thousands separator
+	messages map[string]string // status messages by the English message
+}
+
+// locales are the languages of the number formats and the message catalog
+var locales = []locale{
+	{"en", ".", ",", nil},
+	{"de", ",", ".", map[string]string{
+		msgNewStart:  "Neuen Startknoten wählen für einen weiteren MST mit denselben Knoten",
+		msgSameAxes:  "Beide Diagramme haben dieselben Achsen",
+		msgScaling:   "MST-Gewicht (schwarz) und Laufzeit (rot) über der Anzahl der Knoten",
+		msgNoSweep:   "Es wurden keine Startknoten durchlaufen",
+		msgIdentical: "Die MSTs sind identisch",
+	}},
+	{"fr", ",", "\u202f", map[string]string{
+		msgNewStart:  "Cochez nouveau sommet de départ pour un autre ARM avec les mêmes sommets",
+		msgSameAxes:  "Les deux graphiques ont les mêmes axes",
+		msgScaling:   "Poids de l’ARM (noir) et temps d’exécution (rouge) selon le nombre de sommets",
+		msgNoSweep:   "Aucun sommet de départ n’a été parcouru",
+		msgIdentical: "Les ARM sont identiques",
+	}},
+	{"es", ",", ".", map[string]string{
+		msgNewStart:  "Marque nuevo vértice inicial para otro AEM con los mismos vértices",
+		msgSameAxes:  "Ambos gráficos tienen los mismos ejes",
+		msgScaling:   "Peso del AEM (negro) y tiempo de ejecución (rojo) según el número de vértices",
+		msgNoSweep:   "No se recorrió ningún vértice inicial",
+		msgIdentical: "Los AEM son idénticos",
+	}},
+}
+
+// Status messages of the catalog, in English
+const (
+	msgNewStart  = "Check new start vertex for another MST using the same vertices"
+	msgSameAxes  = "Both plots have the same axes"
+	msgScaling   = "MST weight (black) and runtime (red) versus number of vertices"
+	msgNoSweep   = "No start vertices were swept"
+	msgIdentical = "The MSTs are identical"
+)
+
+// localeNames are the locale choices of the form, the first is the default
+var localeNames = func() []string {
+	names := []string{localeAuto}
+	for _, l := range locales {
+		names = append(names, l.name)
+	}
+	return names
+}()
+
+// findLocale returns the locale of the language subtag, the zero locale when there is none
+func findLocale(name string) locale {
+	for _, l := range locales {
+		if l.name == name {
+			return l
+		}
+	}
+	return locale{}
+}
+
+// acceptLocale returns the preferred locale of the Accept-Language header, the zero
+// locale when it names none of the locales
+func acceptLocale(header string) locale {
+	type language struct {
+		name string
+		q    float64
+	}
+	var langs []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			if f, err := strconv.ParseFloat(params[2:], 64); err == nil {
+				q = f
+			}
+		}
+		name, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		langs = append(langs, language{name, q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	for _, lang := range langs {
+		if l := findLocale(lang.name); len(l.name) > 0 && lang.q > 0 {
+			return l
+		}
+	}
+	return locale{}
+}
+
+// formLocale gets the locale from the HTML form, or from the Accept-Language header
+// when it is auto.  It returns the locale choice to keep in the form.
+func formLocale(r *http.Request) (locale, string, error) {
+	name, err := formChoice(r, "locale", localeNames)
+	if err != nil || name == localeAuto {
+		return acceptLocale(r.Header.Get("Accept-Language")), localeAuto, err
+	}
+	return findLocale(name), name, nil
+}
+
+// message returns the status message in the language, English when it is not in the catalog
+func (l locale) message(msg string) string {
+	if m, ok := l.messages[msg]; ok {
+		return m
+	}
+	return msg
+}
+
+// number localizes a number formatted by strconv: the decimal separator, and the thousands
+// separator of the integer part of fixed and SI numbers
+func (l locale) number(s string) string {
+	if len(l.name) == 0 {
+		return s
+	}
+	// Split off the sign, and the exponent or SI prefix after the digits
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	end := strings.LastIndexAny(s, "0123456789") + 1
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		end = i
+	}
+	digits, suffix := s[:end], s[end:]
+	whole, frac, hasFrac := strings.Cut(digits, ".")
+
+	// Group the whole part, unless the number is scientific
+	if len(suffix) == 0 || !strings.ContainsAny(suffix[:1], "eE") {
+		var b strings.Builder
+		for i, c := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				b.WriteString(l.group)
+			}
+			b.WriteRune(c)
+		}
+		whole = b.String()
+	}
+	if hasFrac {
+		whole += l.decimal + frac
+	}
+	return sign + whole + suffix
+}
+
+// point formats the x,y location, separating the coordinates with a semicolon when the
+// decimal separator is a comma
+func (f labelFormat) point(x, y float64) string {
+	sep := ", "
+	if f.lang.decimal == "," {
+		sep = "; "
+	}
+	return "(" + f.format(x) + sep + f.format(y) + ")"
+}
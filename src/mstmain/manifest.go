@@ -0,0 +1,162 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	patternExport   = "/primmstexport" // http handler for the zip of every export with its manifest
+	manifestVersion = 1                // version of the manifest document
+)
+
+// Artifact is an exported file listed in a manifest
+type Artifact struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Bytes       int    `json:"bytes"`
+	SHA256      string `json:"sha256"` // hex SHA-256 checksum of the file
+}
+
+// Manifest records the parameters of the MST and the checksums of the exported artifacts
+// so that the exports can be verified and the MST reproduced later
+type Manifest struct {
+	Version   int        `json:"version"`
+	Created   time.Time  `json:"created"`
+	Xmin      float64    `json:"xmin"`
+	Xmax      float64    `json:"xmax"`
+	Ymin      float64    `json:"ymin"`
+	Ymax      float64    `json:"ymax"`
+	Metadata  Metadata   `json:"metadata"` // seed, algorithm, metric, and start vertex
+	RunID     string     `json:"runID,omitempty"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// export is a file that can be downloaded and listed in a manifest
+type export struct {
+	name        string
+	contentType string
+	write       func(io.Writer) error
+}
+
+// exports returns the MST's export formats included in the export zip
+func (p *PrimMST) exports() []export {
+	return []export{
+		{"p.json", "application/json", func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(p.nodeLink(false))
+		}},
+		{"p.nwk", "text/plain; charset=utf-8", func(w io.Writer) error {
+			_, err := io.WriteString(w, p.newick())
+			return err
+		}},
+		{"p.mtx", "text/plain; charset=utf-8", p.writeMatrixMarket},
+		{"distances.csv", "text/csv; charset=utf-8", p.writeDistancesCSV},
+		{"primmstframes.zip", "application/zip", p.writeFrames},
+	}
+}
+
+// artifact writes the export and returns its contents and manifest entry
+func (e export) artifact() ([]byte, Artifact, error) {
+	var buf bytes.Buffer
+	if err := e.write(&buf); err != nil {
+		return nil, Artifact{}, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), Artifact{Name: e.name, ContentType: e.contentType, Bytes: buf.Len(),
+		SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+// manifest creates the manifest of the artifacts
+func (p *PrimMST) manifest(artifacts ...Artifact) *Manifest {
+	return &Manifest{
+		Version:   manifestVersion,
+		Created:   time.Now().UTC(),
+		Xmin:      p.xmin,
+		Xmax:      p.xmax,
+		Ymin:      p.ymin,
+		Ymax:      p.ymax,
+		Metadata:  p.meta,
+		RunID:     p.runID,
+		Artifacts: artifacts,
+	}
+}
+
+// serveExport writes the export as a download with its SHA-256 checksum in the Digest header.
+// With the manifest query parameter it writes the export's manifest instead.
+func (p *PrimMST) serveExport(w http.ResponseWriter, r *http.Request, e export) {
+	b, a, err := e.artifact()
+	if err != nil {
+		fmt.Printf("Write %s error: %v\n", e.name, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(r.FormValue("manifest")) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+e.name+".manifest.json\"")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(p.manifest(a)); err != nil {
+			fmt.Printf("JSON encode error: %v\n", err)
+		}
+		return
+	}
+	sum, _ := hex.DecodeString(a.SHA256)
+	w.Header().Set("Content-Type", e.contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+e.name+"\"")
+	w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+	if _, err := w.Write(b); err != nil {
+		fmt.Printf("Write %s error: %v\n", e.name, err)
+	}
+}
+
+// writeExport writes a zip archive of every export and manifest.json with their checksums
+func (p *PrimMST) writeExport(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	var artifacts []Artifact
+	for _, e := range p.exports() {
+		b, a, err := e.artifact()
+		if err != nil {
+			return err
+		}
+		f, err := zw.Create(e.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(b); err != nil {
+			return err
+		}
+		artifacts = append(artifacts, a)
+	}
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(p.manifest(artifacts...)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// HTTP handler for /primmstexport connections
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"primmstexport.zip\"")
+	if err := p.writeExport(w); err != nil {
+		fmt.Printf("writeExport error: %v\n", err)
+	}
+}
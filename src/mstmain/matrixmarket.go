@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const patternMatrixMarket = "/primmstmtx" // http handler for the Matrix Market adjacency matrix download
+
+// writeMatrixMarket writes the MST as a sparse symmetric adjacency matrix in Matrix Market
+// coordinate format, for MATLAB, SciPy, and other numerical tools.  The entries are the
+// edge distances in the lower triangle, rows and columns are the 1-based vertex indexes.
+func (p *PrimMST) writeMatrixMarket(w io.Writer) error {
+	type entry struct {
+		row, col int
+	}
+	var entries []entry
+	for _, e := range p.mst {
+		if e == nil {
+			continue
+		}
+		row, col := e.v, e.w
+		if row < col {
+			row, col = col, row
+		}
+		entries = append(entries, entry{row, col})
+	}
+	// Column-major order, as MATLAB and SciPy write them
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].col != entries[j].col {
+			return entries[i].col < entries[j].col
+		}
+		return entries[i].row < entries[j].row
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "%%MatrixMarket matrix coordinate real symmetric")
+	fmt.Fprintf(bw, "%% Prim MST adjacency matrix: %s, %s metric, seed %d, start vertex %d\n",
+		p.meta.Algorithm, p.meta.Metric, p.meta.Seed, p.start+1)
+	if units := p.distanceUnits(); len(units) > 0 {
+		fmt.Fprintf(bw, "%% distances in %s\n", units)
+	}
+	if p.names != nil {
+		for v := range p.location {
+			fmt.Fprintf(bw, "%% vertex %d %s\n", v+1, p.name(v))
+		}
+	}
+	n := len(p.location)
+	fmt.Fprintf(bw, "%d %d %d\n", n, n, len(entries))
+	for _, e := range entries {
+		fmt.Fprintf(bw, "%d %d %s\n", e.row+1, e.col+1, strconv.FormatFloat(p.weight(e.row, e.col), 'g', -1, 64))
+	}
+	return bw.Flush()
+}
+
+// HTTP handler for /primmstmtx connections, manifest=1 downloads its manifest
+func handleMatrixMarket(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	p.serveExport(w, r, export{"p.mtx", "text/plain; charset=utf-8", p.writeMatrixMarket})
+}
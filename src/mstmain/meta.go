@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// PhaseTiming is the elapsed time of one phase of the computation
+type PhaseTiming struct {
+	Phase    string        `json:"phase"`    // generate, distances, mst, plot
+	Duration time.Duration `json:"duration"` // elapsed time in nanoseconds
+}
+
+// Metadata describes how a result was computed so that it can be reproduced
+type Metadata struct {
+	Algorithm  string        `json:"algorithm"`            // MST algorithm
+	Seed       int64         `json:"seed"`                 // random number generator seed
+	Metric     string        `json:"metric"`               // distance metric between vertices
+	Expression string        `json:"expression,omitempty"` // edge weight expression of the Custom metric
+	Projection string        `json:"projection,omitempty"` // projection of the lon,lat vertex list to meters
+	Units      string        `json:"units,omitempty"`      // coordinate units declared by the vertex list header
+	Vertices   int           `json:"vertices"`             // number of vertices
+	Graph      string        `json:"graph,omitempty"`      // graph ID of the vertices and edge weights
+	Start      int           `json:"start"`                // start vertex index
+	Partial    string        `json:"partial,omitempty"`    // phase the computation stopped in, empty when complete
+	TieBreak   string        `json:"tieBreak,omitempty"`   // lexicographic, empty for the priority queue order
+	Tree       string        `json:"tree,omitempty"`       // canonical hash of the MST edges
+	Timings    []PhaseTiming `json:"timings"`              // per-phase elapsed times
+}
+
+// String formats the phase timing for the html template
+func (pt PhaseTiming) String() string {
+	return fmt.Sprintf("%s: %v", pt.Phase, pt.Duration.Round(time.Microsecond))
+}
+
+// timePhase records the elapsed time of a phase that began at start
+func (m *Metadata) timePhase(phase string, start time.Time) {
+	m.Timings = append(m.Timings, PhaseTiming{Phase: phase, Duration: time.Since(start)})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+)
+
+const maxRepeats = 1000 // most Monte Carlo repetitions of the random graph
+
+// tQuantiles are the two-sided 95% Student t quantiles for 1-30 degrees of freedom
+var tQuantiles = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// MonteCarlo is the distribution of the MST weight over repeated random graphs
+type MonteCarlo struct {
+	Repeats    int     `json:"repeats"`    // random graphs drawn
+	Mean       float64 `json:"mean"`       // mean MST weight
+	StdDev     float64 `json:"stdDev"`     // sample standard deviation of the MST weight
+	Low        float64 `json:"low"`        // lower end of the 95% confidence interval of the mean
+	High       float64 `json:"high"`       // upper end of the 95% confidence interval of the mean
+	Percentile float64 `json:"percentile"` // percent of the draws lighter than this graph's MST
+}
+
+// String formats the Monte Carlo result for the html template
+func (mc MonteCarlo) String() string {
+	return fmt.Sprintf("%d random graphs: mean MST weight %.2f, 95%% CI %.2f-%.2f, std dev %.2f, this MST is heavier than %.0f%% of them",
+		mc.Repeats, mc.Mean, mc.Low, mc.High, mc.StdDev, mc.Percentile)
+}
+
+// formRepeats gets the number of Monte Carlo repetitions from the HTML form, 0 does not repeat
+func (p *PrimMST) formRepeats(r *http.Request) error {
+	repeats, err := formInt(r, "repeats", 0)
+	if err != nil {
+		return err
+	}
+	if repeats < 0 || repeats > maxRepeats {
+		return fmt.Errorf("repetitions %d is not in the range 0-%d", repeats, maxRepeats)
+	}
+	p.repeats = repeats
+	return nil
+}
+
+// monteCarlo draws p.repeats random graphs with the same bounds, vertex count, and metric,
+// seeded from the graph's seed, and compares the distribution of their MST weights with
+// the weight of this graph's MST
+func (p *PrimMST) monteCarlo() (*MonteCarlo, error) {
+	if len(p.imported) > 0 {
+		return nil, errors.New("Monte Carlo repetitions need random vertices, not a vertex list")
+	}
+	if p.repeats < 2 {
+		return nil, errors.New("Monte Carlo needs at least 2 repetitions")
+	}
+	n := len(p.location)
+	weights := make([]float64, p.repeats)
+	rnd := rand.New(rand.NewSource(p.meta.Seed))
+	for i := range weights {
+		if p.checkpoint(fmt.Sprintf("monte carlo after %d of %d repetitions", i, p.repeats)) {
+			if i < 2 {
+				return nil, errors.New("Monte Carlo stopped before 2 repetitions")
+			}
+			weights = weights[:i]
+			break
+		}
+		q := &PrimMST{Endpoints: p.Endpoints, model: p.model, terrain: p.terrain,
+			rnd: rand.New(rand.NewSource(rnd.Int63()))}
+		q.randomVertices(n)
+		if err := q.findDistances(); err != nil {
+			return nil, err
+		}
+		if err := q.findMST(); err != nil {
+			return nil, err
+		}
+		weights[i] = q.totalDistance()
+	}
+
+	repeats := len(weights)
+	mc := &MonteCarlo{Repeats: repeats}
+	for _, w := range weights {
+		mc.Mean += w
+	}
+	mc.Mean /= float64(repeats)
+	for _, w := range weights {
+		mc.StdDev += (w - mc.Mean) * (w - mc.Mean)
+	}
+	mc.StdDev = math.Sqrt(mc.StdDev / float64(repeats-1))
+
+	// Student t interval for small samples, normal for large
+	t := 1.96
+	if df := repeats - 1; df <= len(tQuantiles) {
+		t = tQuantiles[df-1]
+	}
+	half := t * mc.StdDev / math.Sqrt(float64(repeats))
+	mc.Low, mc.High = mc.Mean-half, mc.Mean+half
+
+	weight := p.totalDistance()
+	sort.Float64s(weights)
+	mc.Percentile = 100 * float64(sort.SearchFloat64s(weights, weight)) / float64(repeats)
+	return mc, nil
+}
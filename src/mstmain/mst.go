@@ -11,19 +11,21 @@ The user can select a different starting vertex.  The total distance of the MST
 package main
 
 import (
-	"bufio"
-	"container/heap"
+	"context"
+	"flag"
 	"fmt"
+	"html/template"
 	"log"
-	"math"
-	"math/cmplx"
 	"math/rand"
 	"net/http"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
-	"text/template"
+	"sync"
 	"time"
+
+	"github.com/thomasteplick/primmst/solver"
 )
 
 const (
@@ -32,47 +34,86 @@ const (
 	fileGraphOptions    = "templates/graphoptions.html" // html for Graph Options
 	patternPrimMST      = "/primmst"                    // http handler for Prim MST
 	patternGraphOptions = "/graphoptions"               // http handler for Graph Options
-	rows                = 300                           // #rows in grid
-	columns             = rows                          // #columns in grid
+	rows                = 300                           // default #rows in grid
+	columns             = rows                          // default #columns in grid
 	xlabels             = 11                            // # labels on x axis
 	ylabels             = 11                            // # labels on y axis
-	dataDir             = "data/"                       // directory for the data files
 	fileVerts           = "vertices.csv"                // bounds and complex locations of vertices
+	patternFrames       = "/primmstframes"              // http handler for the SVG frames zip
+	patternAPIPrimMST   = "/api/primmst"                // http handler for the Prim MST JSON API
 )
 
+// dataDir is the directory for the data files, set by -data-dir
+var dataDir = "data/"
+
 // Edges are the vertices of the edge endpoints
 type Edge struct {
 	v int // one vertix
 	w int // the other vertix
 }
 
-// Items are stored in the Priority Queue
-type Item struct {
-	Edge             // embedded field accessed with v,w
-	index    int     // The index is used by Priority Queue update and is maintained by the heap.Interface
-	distance float64 // Edge distance between vertices
-}
-
-// Priority Queue is a map of indexes and queue items and implements the heap.Interface
-// A map is used instead of a slice so that it can be easily determined if an edge is in the queue
-type PriorityQueue map[int]*Item
-
 // Minimum spanning tree holds the edge vertices
 type MST []*Edge
 
 // Type to contain all the HTML template actions
 type PlotT struct {
-	Grid          []string // plotting grid
-	Status        string   // status of the plot
-	Xlabel        []string // x-axis labels
-	Ylabel        []string // y-axis labels
-	Distance      string   // MST total distance
-	Vertices      string   // number of vertices
-	Xmin          string   // x minimum endpoint in Euclidean graph
-	Xmax          string   // x maximum endpoint in Euclidean graph
-	Ymin          string   // y minimum endpoint in Euclidean graph
-	Ymax          string   // y maximum endpoint in Euclidean graph
-	StartLocation string   // start vertex location in x,y coordinates
+	Grid           []string      // plotting grid
+	Status         string        // status of the plot
+	Xlabel         []string      // x-axis labels
+	Ylabel         []string      // y-axis labels
+	Distance       string        // MST total distance
+	Vertices       string        // number of vertices
+	Xmin           string        // x minimum endpoint in Euclidean graph
+	Xmax           string        // x maximum endpoint in Euclidean graph
+	Ymin           string        // y minimum endpoint in Euclidean graph
+	Ymax           string        // y maximum endpoint in Euclidean graph
+	StartLocation  string        // start vertex location in x,y coordinates
+	Meta           Metadata      // computation metadata
+	Theme          string        // page theme
+	Layers         PlotLayerView // plot layer checkboxes with their notes, legend, and colors
+	Clusters       int           // number of MST clusters
+	ClusterScores  ClusterScores // cluster quality by number of clusters
+	Undos          int           // vertex edits that can be undone
+	Redos          int           // vertex edits that can be redone
+	Proximity      string        // proximity graph sizes
+	Nearest        string        // nearest neighbor statistics
+	Randomness     string        // spatial randomness test and verdict
+	Snapshots      []Snapshot    // pinned snapshots of the session
+	Operations     string        // solver operation counts
+	Ties           string        // tie-breaks of Prim's algorithm
+	TieBreak       bool          // ties broken in lexicographic vertex order
+	Terrain        string        // terrain elevation surface
+	Repeats        int           // Monte Carlo repetitions
+	MonteCarlo     string        // MST weight confidence interval
+	Prizes         []Choice      // prize modes
+	PrizeValue     string        // uniform or mean random prize
+	Prize          string        // prize-collecting tradeoff
+	Cost           string        // MST weight split into the distance and fixed components
+	MaxResolution  int           // server budget of the grid rows or columns
+	CostPerKm      string        // cost per km of the Cost per km metric
+	SetupCost      string        // fixed cost per edge of the Cost per km metric
+	FacilityList   string        // facility vertex indexes or names
+	AttachBy       []Choice      // facility attachment distances
+	Facilities     []Facility    // facility service areas
+	Perturbation   string        // perturbation experiment result
+	Bipartition    string        // MST 2-coloring partition sizes
+	OrderColors    bool          // vertices colored by Prim insertion order
+	Approximation  string        // approximate mode sample and optimality gap
+	EqualAspect    bool          // x and y are plotted at the same scale
+	DensityAlpha   bool          // dense edge layers shaded by density
+	Resolution     Resolution    // grid size range of the auto-sized plot
+	GridStyle      template.CSS  // CSS of the grid size and axis ticks
+	RunID          string        // stored run ID
+	TreeCount      string        // number of spanning trees
+	OutlierSigma   string        // outlier threshold in standard deviations
+	OutlierSummary string        // number of outliers flagged
+	Outliers       []Outlier     // outlier report
+	Annotations    []Annotation  // text annotations placed over the grid
+	AnnotationList string        // annotations, one x, y, text per line
+	Runs           []string      // recent stored runs to diff with
+	LabelStyle     string        // axis label and distance number style
+	Precision      int           // axis label and distance precision
+	Locale         string        // number format and status message locale
 }
 
 // Type to hold the minimum and maximum data values of the Euclidean graph
@@ -85,111 +126,265 @@ type Endpoints struct {
 
 // PrimMST type used by the http handler methods to create the MST
 type PrimMST struct {
-	graph     [][]float64  // matrix of vertices and their distance from each other
-	location  []complex128 // complex point(x,y) coordinates of vertices
-	mst       MST
-	Endpoints // Euclidean graph endpoints
+	graph         [][]float64  // matrix of vertices and their distance from each other
+	location      []complex128 // complex point(x,y) coordinates of vertices
+	names         []string     // vertex names from the imported vertex list, nil when unnamed
+	mst           MST
+	order         []int             // vertices in the order Prim's algorithm added them to the MST
+	rnd           *rand.Rand        // random number generator seeded with meta.Seed
+	meta          Metadata          // computation metadata
+	theme         string            // page theme
+	layers        map[string]bool   // names of the plot layers drawn
+	layerNotes    []layerNote       // sizes and summaries of the drawn layers
+	proximity     []string          // proximity graph sizes
+	nearest       *NearestNeighbors // nearest neighbor statistics
+	randomness    *Randomness       // quadrat test of complete spatial randomness, nil for too few vertices
+	model         WeightModel       // edge weight model of the metric, nil for Euclidean distances
+	cost          *CostBreakdown    // MST weight split by the model, nil without a fixed component
+	ctx           context.Context   // done when the computation is cancelled or times out, nil never cancels
+	terrain       *Terrain          // elevation surface of the Terrain metric, nil for a flat plane
+	repeats       int               // Monte Carlo repetitions of the random graph, 0 does not repeat
+	montecarlo    *MonteCarlo       // MST weight distribution of the repetitions
+	prize         *PrizeCollecting  // prize-collecting tree, nil when the vertices have no prizes
+	facilityList  []string          // facility vertex indexes or names from the form
+	attachBy      string            // distance attaching the vertices to the facilities
+	facilities    []Facility        // service areas of the facilities
+	attached      []int             // facility area of each vertex, -1 when unattached
+	start         int               // start vertex index
+	changed       map[Edge]bool     // MST edges changed by the perturbation
+	perturbation  Perturbation      // perturbation experiment result
+	vertexClass   []string          // CSS class of each vertex when colored
+	bipart        *Bipartition      // MST 2-coloring when requested
+	cluster       []int             // MST cluster of each vertex when the clusters layer is drawn
+	clusterCount  int               // number of MST clusters
+	clusterScores ClusterScores     // cluster quality by number of clusters when the clusters layer is drawn
+	neighbors     int               // nearest neighbors sampled per vertex in approximate mode
+	randomEdges   int               // random edges sampled per vertex in approximate mode
+	approx        *Approximation    // approximate mode result
+	imported      string            // summary of the pasted vertex list, empty for random vertices
+	edited        string            // vertex edit, undo, or redo applied, empty without an edit
+	beforeEdit    *GraphState       // graph state before the edit, nil without an edit
+	beforeGraph   [][]float64       // cached distances of the graph before the edit, nil when not cached
+	editAdded     map[Edge]bool     // MST edges added by the edit
+	editRemoved   []pointEdge       // MST edges removed by the edit, by location
+	undos         int               // edits that can be undone in the session's history
+	redos         int               // edits that can be redone in the session's history
+	separation    Separation        // duplicate and near-coincident vertex report
+	snap          float64           // snap-to-grid spacing of the vertex coordinates, 0 does not snap
+	autofit       bool              // fit the endpoints to the pasted vertices
+	margin        float64           // auto-fit margin, percent of the vertex extent
+	projection    string            // projection of the lon,lat vertex list
+	utmZone       int               // UTM zone of the projection, 0 picks the zone of the vertices
+	format        labelFormat       // axis label and distance format
+	aspect        bool              // plot x and y at the same scale, letterboxing the shorter dimension
+	densityAlpha  bool              // shade dense edge layers by the number of edges through each cell
+	resolution    Resolution        // auto-sized grid range
+	ops           *Operations       // solver operation counts, nil until the MST is found
+	ties          *TieReport        // tie-breaks of Prim's algorithm, nil for the other algorithms
+	stretch       *Stretch          // MST path stretch of each vertex, nil without the stretch layer
+	snapshots     []Snapshot        // pinned snapshots of the session
+	colors        []LayerColor      // user layer colors, nil uses the theme colors
+	runID         string            // stored run ID, empty when the run was not stored
+	treeThreshold *float64          // edge threshold of the spanning tree count, nil does not count
+	trees         string            // spanning tree count
+	outlierSigma  *float64          // outlier threshold in standard deviations, nil does not flag outliers
+	outliers      []Outlier         // MST leaves with an anomalously long edge
+	outlier       []bool            // vertices flagged as outliers
+	annotations   []Annotation      // text annotations at plot coordinates
+	Endpoints                       // Euclidean graph endpoints
 }
 
 // global variables for parse and execution of the html template and MST construction
 var (
-	tmplForm *template.Template
-	primmst  *PrimMST
+	tmplForm       *template.Template
+	tmplOptions    *template.Template
+	tmplScaling    *template.Template
+	tmplCompare    *template.Template
+	tmplDiff       *template.Template
+	tmplRobustness *template.Template
+	tmplTour       *template.Template
+	tmplSweep      *template.Template
+	tmplCanvas     *template.Template
+	tmplAdmin      *template.Template
+	tmplTable      *template.Template
+	tmplStep       *template.Template
+	tmplTimeSlice  *template.Template
+	tmplBudget     *template.Template
+	tmplDemo       *template.Template
 )
 
+// latest is the MST of the latest successful run, which the export and analysis pages use
+var latest struct {
+	sync.Mutex
+	p *PrimMST
+}
+
+// latestMST returns the MST of the latest successful run, nil before the first
+func latestMST() *PrimMST {
+	latest.Lock()
+	defer latest.Unlock()
+	return latest.p
+}
+
+// setLatestMST makes p the MST of the latest successful run
+func setLatestMST(p *PrimMST) {
+	latest.Lock()
+	latest.p = p
+	latest.Unlock()
+}
+
 // init parses the html template fileS
 func init() {
-	tmplForm = template.Must(template.ParseFiles(filePrimMST))
+	tmplForm = parseTemplate(filePrimMST)
+	tmplOptions = parseTemplate(fileGraphOptions)
+	tmplScaling = parseTemplate(fileScaling)
+	tmplCompare = parseTemplate(fileCompare)
+	tmplDiff = parseTemplate(fileDiff)
+	tmplRobustness = parseTemplate(fileRobustness)
+	tmplTour = parseTemplate(fileTour)
+	tmplSweep = parseTemplate(fileSweep)
+	tmplCanvas = parseTemplate(fileCanvas)
+	tmplAdmin = parseTemplate(fileAdmin)
+	tmplTable = parseTemplate(fileTable)
+	tmplStep = parseTemplate(fileStep)
+	tmplTimeSlice = parseTemplate(fileTimeSlice)
+	tmplBudget = parseTemplate(fileBudget)
+	tmplDemo = parseTemplate(fileDemo)
 }
 
 // generateVertices creates random vertices in the complex plane
 func (p *PrimMST) generateVertices(r *http.Request) error {
 
-	// new start vertex using saved vertices in csv file
-	newstartvert := r.PostFormValue("newstartvert")
-	if len(newstartvert) > 0 {
-		f, err := os.Open(fileVerts)
+	// vertex edit, undo, or redo of the previous graph, whose distances are recomputed
+	if len(editOp(r)) > 0 {
+		id := r.PostFormValue("graph")
+		state, graph, err := cachedState(id)
 		if err != nil {
-			fmt.Printf("Open file %s error: %v\n", fileVerts, err)
-		}
-		defer f.Close()
-		input := bufio.NewScanner(f)
-		input.Scan()
-		line := input.Text()
-		// Each line has comma-separated values
-		values := strings.Split(line, ",")
-		var xmin, ymin, xmax, ymax float64
-		if xmin, err = strconv.ParseFloat(values[0], 64); err != nil {
-			fmt.Printf("String %s conversion to float error: %v\n", values[0], err)
+			fmt.Printf("loadState error: %v\n", err)
 			return err
 		}
-
-		if ymin, err = strconv.ParseFloat(values[1], 64); err != nil {
-			fmt.Printf("String %s conversion to float error: %v\n", values[1], err)
+		p.beforeEdit, p.beforeGraph = state, graph
+		if state, err = p.editState(r, id, state); err != nil {
 			return err
 		}
-		if xmax, err = strconv.ParseFloat(values[2], 64); err != nil {
-			fmt.Printf("String %s conversion to float error: %v\n", values[2], err)
+		state.Graph = p.graphID()
+		if err := saveState(state); err != nil {
+			fmt.Printf("saveState error: %v\n", err)
 			return err
 		}
+		return nil
+	}
 
-		if ymax, err = strconv.ParseFloat(values[3], 64); err != nil {
-			fmt.Printf("String %s conversion to float error: %v\n", values[3], err)
+	// new start vertex or perturbation using the saved state of the previous graph
+	newstartvert := r.PostFormValue("newstartvert")
+	epsilon := r.PostFormValue("epsilon")
+	if len(newstartvert) > 0 || len(epsilon) > 0 {
+		id := r.PostFormValue("graph")
+		state, graph, err := cachedState(id)
+		if err != nil {
+			fmt.Printf("loadState error: %v\n", err)
 			return err
 		}
-		p.Endpoints = Endpoints{xmin: xmin, ymin: ymin, xmax: xmax, ymax: ymax}
-
-		p.location = make([]complex128, 0)
-		for input.Scan() {
-			line := input.Text()
-			// Each line has comma-separated values
-			values := strings.Split(line, ",")
-			var x, y float64
-			if x, err = strconv.ParseFloat(values[0], 64); err != nil {
-				fmt.Printf("String %s conversion to float error: %v\n", values[0], err)
-				continue
-			}
-			if y, err = strconv.ParseFloat(values[1], 64); err != nil {
-				fmt.Printf("String %s conversion to float error: %v\n", values[1], err)
-				continue
+		if err := p.restoreState(state); err != nil {
+			return err
+		}
+		if graph != nil {
+			p.graph = graph
+			p.meta.Graph = id
+		}
+
+		// Change starting vertex
+		if len(newstartvert) > 0 {
+			p.start = p.rnd.Intn(len(p.location))
+			p.meta.Start = p.start
+			graphs.setStart(id, p.start)
+			s := p.state()
+			s.Graph = id
+			if err := saveState(s); err != nil {
+				fmt.Printf("saveState error: %v\n", err)
+				return err
 			}
-			p.location = append(p.location, complex(x, y))
 		}
-		// Change starting vertex at 0 index
-		swap := rand.Intn(len(p.location))
-		p.location[0], p.location[swap] = p.location[swap], p.location[0]
 
 		return nil
 	}
 	// Generate V vertices and locations randomly, get from HTML form
 	// or read in from a previous graph when using a new start vertex.
 	// Insert vertex complex coordinates into locations
+	var err error
+	if p.Endpoints, err = formEndpoints(r); err != nil {
+		return err
+	}
+	if err = p.formTerrain(r); err != nil {
+		return err
+	}
+
+	// Use the pasted vertex list instead of random vertices
+	if list := r.PostFormValue("vertexlist"); len(strings.TrimSpace(list)) > 0 {
+		if err := p.pastedVertices(list); err != nil {
+			return err
+		}
+	} else {
+		vertices := r.FormValue("vertices")
+		verts, err := strconv.Atoi(vertices)
+		if err != nil {
+			fmt.Printf("String %s conversion to int error: %v\n", vertices, err)
+			return err
+		}
+		if verts < minVertices || verts > maxVertices {
+			return fmt.Errorf("number of vertices %d is not in the range %d-%d", verts, minVertices, maxVertices)
+		}
+
+		p.randomVertices(verts)
+	}
+
+	// Round to the snap-to-grid spacing, then merge or re-sample the duplicate
+	// and near-coincident vertices
+	p.snapToGrid()
+	p.separate()
+	if len(p.location) < minVertices {
+		return fmt.Errorf("%d vertices remain after merging, fewer than %d", len(p.location), minVertices)
+	}
+
+	// Save the endpoints, seed, vertex locations, and graph ID to the state file
+	s := p.state()
+	s.Graph = p.graphID()
+	if err := saveState(s); err != nil {
+		fmt.Printf("saveState error: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+// formEndpoints gets the Euclidean graph endpoints from the HTML form
+func formEndpoints(r *http.Request) (Endpoints, error) {
 	str := r.FormValue("xmin")
 	xmin, err := strconv.ParseFloat(str, 64)
 	if err != nil {
 		fmt.Printf("String %s conversion to float error: %v\n", str, err)
-		return err
+		return Endpoints{}, err
 	}
 
 	str = r.FormValue("ymin")
 	ymin, err := strconv.ParseFloat(str, 64)
 	if err != nil {
 		fmt.Printf("String %s conversion to float error: %v\n", str, err)
-		return err
+		return Endpoints{}, err
 	}
 
 	str = r.FormValue("xmax")
 	xmax, err := strconv.ParseFloat(str, 64)
 	if err != nil {
 		fmt.Printf("String %s conversion to float error: %v\n", str, err)
-		return err
+		return Endpoints{}, err
 	}
 
 	str = r.FormValue("ymax")
 	ymax, err := strconv.ParseFloat(str, 64)
 	if err != nil {
 		fmt.Printf("String %s conversion to float error: %v\n", str, err)
-		return err
+		return Endpoints{}, err
 	}
 
 	// Check if xmin < xmax and ymin < ymax and correct if necessary
@@ -200,273 +395,192 @@ func (p *PrimMST) generateVertices(r *http.Request) error {
 		ymin, ymax = ymax, ymin
 	}
 
-	p.Endpoints = Endpoints{xmin: xmin, ymin: ymin, xmax: xmax, ymax: ymax}
-
-	vertices := r.FormValue("vertices")
-	verts, err := strconv.Atoi(vertices)
-	if err != nil {
-		fmt.Printf("String %s conversion to int error: %v\n", vertices, err)
-		return err
-	}
-
-	delx := xmax - xmin
-	dely := ymax - ymin
-	// Generate vertices
-	p.location = make([]complex128, verts)
-	for i := 0; i < verts; i++ {
-		x := xmin + delx*rand.Float64()
-		y := ymin + dely*rand.Float64()
-		p.location[i] = complex(x, y)
-	}
-
-	// Save the endpoints and vertex locations to a csv file
-	f, err := os.Create(fileVerts)
-	if err != nil {
-		fmt.Printf("Create file %s error: %v\n", fileVerts, err)
-		return err
-	}
-	defer f.Close()
-	// Save the endpoints
-	fmt.Fprintf(f, "%f,%f,%f,%f\n", p.xmin, p.ymin, p.xmax, p.ymax)
-	// Save the vertex locations as x,y
-	for _, z := range p.location {
-		fmt.Fprintf(f, "%f,%f\n", real(z), imag(z))
-	}
+	return Endpoints{xmin: xmin, ymin: ymin, xmax: xmax, ymax: ymax}, nil
+}
 
-	return nil
+// randomVertices generates verts random vertices within the endpoints
+func (p *PrimMST) randomVertices(verts int) {
+	p.location = solver.RandomPoints(p.rnd, verts, p.xmin, p.xmax, p.ymin, p.ymax)
 }
 
 // findDistances find distances between vertices and insert into graph
 func (p *PrimMST) findDistances() error {
 
-	verts := len(p.location)
-	// Store distances between vertices for Euclidean graph
-	p.graph = make([][]float64, verts)
-	for i := 0; i < verts; i++ {
-		p.graph[i] = make([]float64, verts)
+	// Measure the edges over the terrain
+	if p.terrain != nil {
+		p.terrainDistances()
+		return nil
 	}
 
-	for i := 0; i < verts; i++ {
-		for j := i + 1; j < verts; j++ {
-			distance := cmplx.Abs(p.location[i] - p.location[j])
-			p.graph[i][j] = distance
-			p.graph[j][i] = distance
+	// Evaluate the weight model of the metric
+	if !p.euclidean() {
+		err := p.modelDistances()
+		if err == nil {
+			return nil
 		}
+		err = p.useEuclidean(err)
+		p.graph = solver.Distances(p.location)
+		return err
 	}
-	for i := 0; i < verts; i++ {
-		p.graph[i][i] = math.MaxFloat64
-	}
-
-	return nil
-}
 
-// A PriorityQueue implements heap.Interface and holds Items
-func (pq PriorityQueue) Len() int {
-	return len(pq)
-}
-
-func (pq PriorityQueue) Less(i, j int) bool {
-	return pq[i].distance < pq[j].distance
-}
+	// Store distances between vertices for Euclidean graph
+	p.graph = solver.Distances(p.location)
 
-func (pq PriorityQueue) Swap(i, j int) {
-	pq[i], (pq)[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
+	return nil
 }
 
-// Push inserts an Item in the queue
-func (pq *PriorityQueue) Push(x any) {
-	n := len(*pq)
-	item := x.(*Item)
-	item.index = n
-	(*pq)[n] = item
+// useEuclidean falls back to the Euclidean metric when the weight model fails
+func (p *PrimMST) useEuclidean(err error) error {
+	p.model = nil
+	p.meta.Metric, p.meta.Expression = metrics[0], ""
+	return fmt.Errorf("%v, using %s", err, metrics[0])
 }
 
-// Pop removes an Item from the queue and returns it
-func (pq *PriorityQueue) Pop() any {
-	old := *pq
-	n := len(old)
-	item := old[n-1]
-	old[n-1] = nil
-	item.index = -1
-	delete(*pq, n-1)
-	return item
+// solve finds the MST using the selected algorithm
+func (p *PrimMST) solve() error {
+	switch p.meta.Algorithm {
+	case algorithmKruskal:
+		return p.findKruskal(false)
+	case algorithmKruskalParallel:
+		return p.findKruskal(true)
+	case algorithmApproximate:
+		return p.findApproximate(p.neighbors, p.randomEdges)
+	case algorithmGabriel, algorithmRNG:
+		return p.findProximity(proximityAlgorithms[p.meta.Algorithm])
+	}
+	return p.findMST()
 }
 
-// update modifies the distance and value of an Item in the queue
-func (pq *PriorityQueue) update(item *Item, distance float64) {
-	item.distance = distance
-	heap.Fix(pq, item.index)
+// euclidean returns true if the edge weights are Euclidean distances
+func (p *PrimMST) euclidean() bool {
+	_, ok := p.model.(euclideanModel)
+	return (p.model == nil || ok) && p.terrain == nil
 }
 
 // findMST finds the minimum spanning tree (MST) using Prim's algorithm
 func (p *PrimMST) findMST() error {
-	vertices := len(p.location)
-	p.mst = make(MST, vertices)
-	marked := make([]bool, vertices)
-	distTo := make([]float64, vertices)
-	for i := range distTo {
-		distTo[i] = math.MaxFloat64
-	}
-	// Create a priority queue, put the items in it, and establish
-	// the priority queue (heap) invariants.
-	pq := make(PriorityQueue)
-
-	visit := func(v int) {
-		marked[v] = true
-		// find shortest distance from vertex v to w
-		for w, dist := range p.graph[v] {
-			// Check if already in the MST
-			if marked[w] {
-				continue
-			}
-			if dist < distTo[w] {
-				// Edge to w is new best connection from MST to w
-				p.mst[w] = &Edge{v: v, w: w}
-				distTo[w] = dist
-				// Check if already in the queue and update
-				item, ok := pq[w]
-				// update
-				if ok {
-					pq.update(item, dist)
-				} else { // insert
-					item = &Item{Edge: Edge{v: v, w: w}, distance: dist}
-					heap.Push(&pq, item)
-				}
-			}
-		}
+	pq, err := solver.NewQueue(priorityQueue, len(p.graph))
+	if err != nil {
+		return err
 	}
-
-	// Starting index is 0, distance is MaxFloat64, put it in the queue
-	distTo[0] = math.MaxFloat64
-	pq[0] = &Item{index: 0, distance: math.MaxFloat64, Edge: Edge{v: 0, w: 0}}
-	heap.Init(&pq)
-
-	// Loop until the queue is empty and the MST is finished
-	for len(pq) > 0 {
-		item := heap.Pop(&pq).(*Item)
-		visit(item.w)
+	q := solver.NewCountingQueue(pq)
+	parent, order, ties, err := solver.PrimTies(p.context(), p.graph, p.start, q, p.meta.TieBreak == tieLexicographic)
+	p.ties = newTieReport(ties, p.meta.TieBreak == tieLexicographic)
+	n := len(p.graph)
+	p.countOperations("Prim "+priorityQueue+" heap", n*(n-1)/2)
+	p.ops.Comparisons = q.Comparisons()
+	p.ops.Push, p.ops.Pop, p.ops.DecreaseKey = q.Counts.Push, q.Counts.Pop, q.Counts.DecreaseKey
+	p.ops.Scanned = q.Counts.Scanned
+	if err != nil {
+		p.checkpoint(fmt.Sprintf("mst with %d of %d vertices in the tree", len(order), len(parent)))
 	}
+	p.mst = make(MST, len(parent))
+	for w, v := range parent {
+		if v >= 0 {
+			p.mst[w] = &Edge{v: v, w: w}
+		}
+	}
+	p.order = order
 
 	return nil
 }
 
 // plotMST draws the MST onto the grid
-func (p *PrimMST) plotMST(w http.ResponseWriter, status []string) error {
+func (p *PrimMST) plotMST(w *streamWriter, status []string) error {
 
 	// Apply the parsed HTML template to plot object
 	// Construct x-axis labels, y-axis labels, status message
 
-	var (
-		plot     PlotT
-		xscale   float64
-		yscale   float64
-		distance float64
-	)
-	plot.Grid = make([]string, rows*columns)
-	plot.Xlabel = make([]string, xlabels)
-	plot.Ylabel = make([]string, ylabels)
-
-	// Calculate scale factors for x and y
-	xscale = (columns - 1) / (p.xmax - p.xmin)
-	yscale = (rows - 1) / (p.ymax - p.ymin)
-
-	// Insert the mst vertices and edges in the grid
-	// loop over the MST vertices
-
-	// color the vertices black
-	// color the edges connecting the vertices gray
-	// color the MST start vertex green
-	// create the line y = mx + b for each edge
-	// translate complex coordinates to row/col on the grid
-	// translate row/col to slice data object []string Grid
-	// CSS selectors for background-color are "vertex", "startvertex", and "edge"
-
-	beginEP := complex(p.xmin, p.ymin)  // beginning of the Euclidean graph
-	endEP := complex(p.xmax, p.ymax)    // end of the Euclidean graph
-	lenEP := cmplx.Abs(endEP - beginEP) // length of the Euclidean graph
-
-	for _, e := range p.mst[1:] {
-
-		// Insert the edge between the vertices v, w.  Do this before marking the vertices.
-		// CSS colors the edge gray.
-		beginEdge := p.location[e.v]
-		endEdge := p.location[e.w]
-		lenEdge := cmplx.Abs(endEdge - beginEdge)
-		distance += lenEdge
-		ncells := int(columns * lenEdge / lenEP) // number of points to plot in the edge
-
-		beginX := real(beginEdge)
-		endX := real(endEdge)
-		deltaX := endX - beginX
-		stepX := deltaX / float64(ncells)
-
-		beginY := imag(beginEdge)
-		endY := imag(endEdge)
-		deltaY := endY - beginY
-		stepY := deltaY / float64(ncells)
-
-		// loop to draw the edge
-		x := beginX
-		y := beginY
-		for i := 0; i < ncells; i++ {
-			row := int((p.ymax-y)*yscale + .5)
-			col := int((x-p.xmin)*xscale + .5)
-			plot.Grid[row*columns+col] = "edge"
-			x += stepX
-			y += stepY
-		}
+	var plot PlotT
+	start := time.Now()
+	plot.Theme = p.theme
+	plot.LabelStyle = p.format.style
+	plot.Precision = p.format.precision
+	plot.Locale = p.format.locale
+	nrows, ncols := p.gridSize()
+	g := newGridPlotSize(p.plotEndpoints(), nrows, ncols)
+	plot.Grid = g.grid
+	plot.GridStyle = template.CSS(g.style())
+	plot.Resolution = p.resolution
+	plot.EqualAspect = p.aspect
+	plot.DensityAlpha = p.densityAlpha
+	plot.Layers.Colors = p.colors
+	plot.RunID = p.runID
+	plot.TreeCount = p.trees
+	plot.Meta.Partial = p.meta.Partial
+
+	// Construct the x-axis and y-axis labels
+	plot.Xlabel, plot.Ylabel = g.labels(p.format)
+
+	// Write the page up to the grid and flush it so the browser can start rendering
+	if err := tmplForm.ExecuteTemplate(w, "gridstart", plot); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Shade the terrain elevation behind the tree
+	if p.terrain != nil {
+		p.plotTerrain(g)
+	}
 
-		// Mark the edge start vertex v.  CSS colors the vertex black.
-		row := int((p.ymax-beginY)*yscale + .5)
-		col := int((beginX-p.xmin)*xscale + .5)
-		plot.Grid[row*columns+col] = "vertex"
-
-		// Mark the edge end vertex w.  CSS colors the vertex black.
-		row = int((p.ymax-endY)*yscale + .5)
-		col = int((endX-p.xmin)*xscale + .5)
-		plot.Grid[row*columns+col] = "vertex"
-	}
-
-	// Mark the MST start vertex.  CSS colors the vertex green.
-	x := real(p.location[0])
-	y := imag(p.location[0])
-	plot.StartLocation = fmt.Sprintf("(%.2f, %.2f)", x, y)
-	row := int((p.ymax-y)*yscale + .5)
-	col := int((x-p.xmin)*xscale + .5)
-	plot.Grid[row*columns+col] = "startvertex"
-	plot.Grid[(row+1)*columns+col] = "startvertex"
-	plot.Grid[(row-1)*columns+col] = "startvertex"
-	plot.Grid[row*columns+col+1] = "startvertex"
-	plot.Grid[row*columns+col-1] = "startvertex"
-
-	// Construct x-axis labels
-	incr := (p.xmax - p.xmin) / (xlabels - 1)
-	x = p.xmin
-	// First label is empty for alignment purposes
-	for i := range plot.Xlabel {
-		plot.Xlabel[i] = fmt.Sprintf("%.2f", x)
-		x += incr
-	}
-
-	// Construct the y-axis labels
-	incr = (p.ymax - p.ymin) / (ylabels - 1)
-	y = p.ymin
-	for i := range plot.Ylabel {
-		plot.Ylabel[i] = fmt.Sprintf("%.2f", y)
-		y += incr
+	// Shade the letterbox outside the endpoints in equal aspect mode
+	if p.aspect {
+		g.letterbox(p.Endpoints)
+	}
+
+	// Draw the selected layers in z-order, streaming them as they are rasterized
+	if err := p.compose(g, w); err != nil {
+		return err
+	}
+
+	// Warn about the vertices that share a plot cell and disappear
+	if o := g.occupancy(p.location); o.Hidden > 0 {
+		status = append(status, o.String())
+	}
+
+	// Status, distances, and the results of the other options
+	p.results(&plot, status)
+
+	// Layers with their notes and the legend of the layers that were drawn
+	plot.Layers = p.layerView(g.grid)
+
+	// Computation metadata, including the time to plot the grid
+	p.meta.timePhase("plot", start)
+	plot.Meta = p.meta
+
+	// Write the rest of the page to HTTP using template
+	if err := tmplForm.ExecuteTemplate(w, "gridend", plot); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// results fills in the results of the MST shared by the plot and table views: the status,
+// start vertex, distance, endpoints, the results of the other options, and recent runs
+func (p *PrimMST) results(plot *PlotT, status []string) {
+	// Record the MST start vertex location
+	x := real(p.location[p.start])
+	y := imag(p.location[p.start])
+	plot.StartLocation = p.format.point(x, y)
+	if p.names != nil {
+		plot.StartLocation = p.name(p.start) + " " + plot.StartLocation
 	}
 
 	// Status
 	if len(status) > 0 {
 		plot.Status = strings.Join(status, ", ")
 	} else {
-		plot.Status = "Check new start vertex for another MST using the same vertices"
+		plot.Status = p.format.lang.message(msgNewStart)
 	}
 
 	// Distance of the MST
-	plot.Distance = fmt.Sprintf("%.2f", distance)
+	plot.Distance = p.format.format(p.totalDistance())
+	if units := p.distanceUnits(); len(units) > 0 {
+		plot.Distance += " " + units
+	}
 
 	// Endpoints and Vertices
 	plot.Vertices = strconv.Itoa(len(p.location))
@@ -475,66 +589,608 @@ func (p *PrimMST) plotMST(w http.ResponseWriter, status []string) error {
 	plot.Ymin = fmt.Sprintf("%.2f", p.ymin)
 	plot.Ymax = fmt.Sprintf("%.2f", p.ymax)
 
-	// Write to HTTP using template and grid
-	if err := tmplForm.Execute(w, plot); err != nil {
-		log.Fatalf("Write to HTTP output using template with grid error: %v\n", err)
+	if p.perturbation.Edges > 0 {
+		plot.Perturbation = p.perturbation.String()
+	}
+	if p.bipart != nil {
+		plot.Bipartition = p.bipart.String()
+	} else {
+		plot.OrderColors = p.vertexClass != nil
+	}
+	if p.approx != nil {
+		plot.Approximation = p.approx.String()
+	}
+	plot.Clusters = p.clusterCount
+	plot.ClusterScores = p.clusterScores
+	plot.FacilityList = strings.Join(p.facilityList, ", ")
+	plot.AttachBy = choices(facilityDistances, p.attachBy)
+	plot.Facilities = p.facilities
+	plot.Prizes = choices(prizeModes, prizeModes[0])
+	if p.prize != nil {
+		plot.Prizes = choices(prizeModes, p.prize.Mode)
+		plot.PrizeValue = fmt.Sprintf("%g", p.prize.Value)
+		plot.Prize = p.prize.String()
+	}
+	if m, ok := p.model.(costModel); ok {
+		plot.CostPerKm, plot.SetupCost = fmt.Sprintf("%g", m.perKm), fmt.Sprintf("%g", m.setup)
+	}
+	plot.MaxResolution = maxResolution
+	if p.cost != nil {
+		plot.Cost = fmt.Sprintf("%s distance + %s fixed", p.format.format(p.cost.Distance), p.format.format(p.cost.Fixed))
+	}
+	plot.Undos, plot.Redos = p.undos, p.redos
+	if p.nearest != nil {
+		plot.Nearest = p.nearest.String()
+	}
+	if p.randomness != nil {
+		plot.Randomness = p.randomness.String()
+	}
+	if p.ops != nil {
+		plot.Operations = p.ops.String()
+	}
+	if p.ties != nil {
+		plot.Ties = p.ties.String()
+	}
+	plot.TieBreak = p.meta.TieBreak == tieLexicographic
+	plot.Snapshots = p.snapshots
+	if p.terrain != nil {
+		plot.Terrain = p.terrain.String()
+	}
+	plot.Repeats = p.repeats
+	if p.montecarlo != nil {
+		plot.MonteCarlo = p.montecarlo.String()
+	}
+	plot.Proximity = strings.Join(p.proximity, ", ")
+	if p.outlierSigma != nil {
+		plot.OutlierSigma = fmt.Sprintf("%g", *p.outlierSigma)
+		plot.OutlierSummary = p.outlierSummary()
+		plot.Outliers = p.outliers
 	}
 
-	return nil
+	plot.Annotations = p.placedAnnotations()
+	plot.AnnotationList = p.annotationList()
+
+	// Recent runs to diff with, other than this one
+	runs, err := recentRuns(listRuns + 1)
+	if err != nil {
+		fmt.Printf("recentRuns error: %v\n", err)
+	}
+	for _, id := range runs {
+		if id != p.runID && len(plot.Runs) < listRuns {
+			plot.Runs = append(plot.Runs, id)
+		}
+	}
 }
 
 // HTTP handler for /graphoptions connections
 func handleGraphOptions(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, "templates/graphoptions.html")
+	options := defaultOptions()
+
+	// Apply the selected preset and list the saved presets
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Printf("loadPresets error: %v\n", err)
+		options.Status = err.Error()
+	}
+	name := r.FormValue("preset")
+	if preset, ok := presets[name]; ok {
+		options.applyPreset(preset)
+	} else if len(name) > 0 {
+		options.Status = fmt.Sprintf("preset %q not found", name)
+	}
+	options.Presets = choices(presetNames(presets), name)
+
+	if err := tmplOptions.Execute(w, options); err != nil {
+		fmt.Printf("Write to HTTP output using template with graph options error: %v\n", err)
+	}
 }
 
-// HTTP handler for /primmst connections
-func handlePrimMST(w http.ResponseWriter, r *http.Request) {
+// createMST generates the vertices, finds the distances and the MST, and records the
+// computation metadata.  Errors are accumulated in the returned status.
+func createMST(r *http.Request) (*PrimMST, []string) {
 
 	// Create the Prim MST instance
-	primmst = &PrimMST{}
+	p := &PrimMST{}
 
 	// Accumulate error
 	status := make([]string, 0)
 
+	// Algorithm and metric selected in the HTML form
+	var err error
+	if p.meta.Algorithm, err = formChoice(r, "algorithm", algorithms); err != nil {
+		fmt.Printf("formChoice error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if p.meta.Metric, err = formChoice(r, "metric", metrics); err != nil {
+		fmt.Printf("formChoice error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if p.theme, err = formChoice(r, "theme", themes); err != nil {
+		fmt.Printf("formChoice error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formApproximate(r); err != nil {
+		fmt.Printf("formApproximate error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formSeparation(r); err != nil {
+		fmt.Printf("formSeparation error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formSnap(r); err != nil {
+		fmt.Printf("formSnap error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formAutoFit(r); err != nil {
+		fmt.Printf("formAutoFit error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formProjection(r); err != nil {
+		fmt.Printf("formProjection error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formWeightModel(r); err != nil {
+		fmt.Printf("formWeightModel error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if p.format, err = formLabelFormat(r); err != nil {
+		fmt.Printf("formLabelFormat error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if p.colors, err = formColors(r); err != nil {
+		fmt.Printf("formColors error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formTreeCount(r); err != nil {
+		fmt.Printf("formTreeCount error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formOutliers(r); err != nil {
+		fmt.Printf("formOutliers error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formRepeats(r); err != nil {
+		fmt.Printf("formRepeats error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formPrizes(r); err != nil {
+		fmt.Printf("formPrizes error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formFacilities(r); err != nil {
+		fmt.Printf("formFacilities error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formAnnotations(r); err != nil {
+		fmt.Printf("formAnnotations error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formLayers(r); err != nil {
+		fmt.Printf("formLayers error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if err = p.formClusters(r); err != nil {
+		fmt.Printf("formClusters error: %v\n", err)
+		status = append(status, err.Error())
+	}
+
+	// Seed the random number generator from the HTML form or the clock
+	p.meta.Seed = newSeed()
+	if seed := r.FormValue("seed"); len(seed) > 0 {
+		s, err := strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			fmt.Printf("String %s conversion to int error: %v\n", seed, err)
+			status = append(status, err.Error())
+		} else {
+			p.meta.Seed = s
+		}
+	}
+	p.rnd = rand.New(rand.NewSource(p.meta.Seed))
+	p.aspect = len(r.FormValue("equalaspect")) > 0
+	p.densityAlpha = len(r.FormValue("densityalpha")) > 0
+	if len(r.FormValue("tiebreak")) > 0 {
+		p.meta.TieBreak = tieLexicographic
+	}
+	if err := p.formResolution(r); err != nil {
+		fmt.Printf("formResolution error: %v\n", err)
+		status = append(status, err.Error())
+	}
+
+	// Stop the computation when the client goes away or the time limit passes,
+	// keeping the phases completed so far
+	ctx, cancel, err := computeContext(r)
+	if err != nil {
+		fmt.Printf("computeContext error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	defer cancel()
+	p.ctx = ctx
+
 	// Generate V vertices and locations randomly, get from HTML form
 	// or read in from a previous graph when using a new start vertex.
 	// Insert vertex complex coordinates into locations
-	err := primmst.generateVertices(r)
+	start := time.Now()
+	err = p.generateVertices(r)
 	if err != nil {
 		fmt.Printf("generateVertices error: %v\n", err)
 		status = append(status, err.Error())
 	}
+	p.meta.timePhase("generate", start)
+	p.meta.Vertices = len(p.location)
+	if len(p.imported) > 0 {
+		status = append(status, p.imported)
+	}
+	if len(p.edited) > 0 {
+		status = append(status, p.edited)
+	}
+	if p.separation.Affected > 0 {
+		status = append(status, p.separation.String())
+	}
+	if len(p.location) == 0 {
+		return p, status
+	}
+
+	// Insert distances into graph, unless the graph was cached or the approximate MST
+	// weighs only the edges it samples
+	start = time.Now()
+	if p.graph != nil {
+		p.meta.timePhase("distances (cached)", start)
+	} else if p.meta.Algorithm == algorithmApproximate {
+		p.meta.Graph = p.graphID()
+		graphs.put(p.meta.Graph, p.state(), nil)
+	} else {
+		err = p.findDistances()
+		if err != nil {
+			fmt.Printf("findDistances error: %v", err)
+			status = append(status, err.Error())
+		}
+		p.meta.timePhase("distances", start)
+		p.meta.Graph = p.graphID()
+		graphs.put(p.meta.Graph, p.state(), p.graph)
+	}
+	p.undos, p.redos = editCounts(r, p.meta.Graph)
+	if p.checkpoint("distances") {
+		return p, status
+	}
 
-	// Insert distances into graph
-	err = primmst.findDistances()
+	// Nearest neighbor distances and the Clark-Evans ratio of the Euclidean graph
+	if p.euclidean() {
+		start = time.Now()
+		p.nearest = p.nearestNeighbors()
+		p.meta.timePhase("nearest neighbors", start)
+	}
+	p.randomness = p.randomnessTest()
+
+	// Find MST and save in PrimMST.mst
+	start = time.Now()
+	err = p.solve()
 	if err != nil {
-		fmt.Printf("findDistances error: %v", err)
+		fmt.Printf("solve error: %v", err)
 		status = append(status, err.Error())
 	}
+	p.meta.timePhase("mst", start)
+	if p.checkpoint("mst") {
+		return p, status
+	}
+	p.cost = p.costBreakdown()
+	p.meta.Tree = p.treeHash()
 
-	// Find MST and save in PrimMST.mst
-	err = primmst.findMST()
+	// Compare the MST with the MST before the vertex edit
+	if p.beforeEdit != nil && len(p.edited) > 0 {
+		start = time.Now()
+		d, err := p.findEditDiff()
+		if err != nil {
+			fmt.Printf("findEditDiff error: %v\n", err)
+			status = append(status, err.Error())
+		} else {
+			status = append(status, d.String())
+		}
+		p.meta.timePhase("edit diff", start)
+	}
+
+	// Count the spanning trees with the matrix-tree theorem
+	if p.treeThreshold != nil {
+		start = time.Now()
+		p.trees = p.treeCountString()
+		p.meta.timePhase("tree count", start)
+		if p.checkpoint("tree count") {
+			return p, status
+		}
+	}
+
+	// Repeat the random graph for the confidence interval of the MST weight
+	if p.repeats > 0 {
+		start = time.Now()
+		if p.montecarlo, err = p.monteCarlo(); err != nil {
+			fmt.Printf("monteCarlo error: %v\n", err)
+			status = append(status, err.Error())
+		}
+		p.meta.timePhase("monte carlo", start)
+		if p.checkpoint("monte carlo") {
+			return p, status
+		}
+	}
+
+	// Flag the vertices whose only MST edge is anomalously long
+	if p.outlierSigma != nil {
+		p.findOutliers(*p.outlierSigma)
+	}
+
+	// Trade the vertex prizes against the edge costs
+	if p.prize != nil {
+		start = time.Now()
+		p.prizeCollecting()
+		p.meta.timePhase("prize collecting", start)
+	}
+
+	// Attach the vertices to their nearest facility
+	if len(p.facilityList) > 0 {
+		if err = p.findFacilities(); err != nil {
+			fmt.Printf("findFacilities error: %v\n", err)
+			status = append(status, err.Error())
+			p.layers["facilities"], p.layers["boundaries"] = false, false
+		}
+	}
+
+	// Cut the heaviest MST edges into single linkage clusters
+	if p.layers["clusters"] {
+		p.findClusters()
+		start = time.Now()
+		p.clusterScores = p.scoreClusters()
+		p.meta.timePhase("cluster scores", start)
+	}
+
+	// Compare the MST path from the start vertex to each vertex with the straight line
+	if p.layers["stretch"] {
+		p.findStretch()
+	}
+
+	// Jitter the vertices and find the MST again for the perturbation experiment
+	if epsilon := r.PostFormValue("epsilon"); len(epsilon) > 0 {
+		eps, err := strconv.ParseFloat(epsilon, 64)
+		if err != nil {
+			fmt.Printf("String %s conversion to float error: %v\n", epsilon, err)
+			status = append(status, err.Error())
+			return p, status
+		}
+		start = time.Now()
+		if p.perturbation, err = p.perturb(eps); err != nil {
+			fmt.Printf("perturb error: %v\n", err)
+			status = append(status, err.Error())
+		}
+		p.meta.timePhase("perturb", start)
+		if p.checkpoint("perturb") {
+			return p, status
+		}
+	}
+
+	// Color the vertices by the MST 2-coloring or the Prim insertion order
+	if len(r.FormValue("bipartition")) > 0 {
+		b := p.bipartition()
+		p.bipart = &b
+	} else if len(r.FormValue("ordercolors")) > 0 {
+		p.orderColoring()
+	}
+
+	return p, status
+}
+
+// HTTP handler for /primmst connections
+func handlePrimMST(w http.ResponseWriter, r *http.Request) {
+
+	if err := checkBudget(r); err != nil {
+		writeBudgetError(w, r, err)
+		return
+	}
+	editSession(w, r)
+
+	// A repeated run with a seed is served from the response cache
+	view, err := formChoice(r, "view", views)
+	p, status := cachedCreateMST(w, r)
 	if err != nil {
-		fmt.Printf("findMST error: %v", err)
+		fmt.Printf("formChoice error: %v\n", err)
+		status = append(status, err.Error())
+	}
+	if len(p.location) == 0 {
+		http.Error(w, strings.Join(status, ", "), http.StatusBadRequest)
+		return
+	}
+	setLatestMST(p)
+
+	// Store the run so it can be compared with later runs
+	if p.runID, err = p.saveRun(status, r.Form); err != nil {
+		fmt.Printf("saveRun error: %v\n", err)
 		status = append(status, err.Error())
 	}
 
-	// Draw MST into 300 x 300 cell 2px grid
+	// Draw MST into the grid with the session's pinned snapshots
 	// Construct x-axis labels, y-axis labels, status message
-	err = primmst.plotMST(w, status)
+	p.snapshots = snapshots(r)
+	if view == viewTable {
+		if err := p.writeTable(w, status); err != nil {
+			fmt.Printf("writeTable error: %v\n", err)
+		}
+		return
+	}
+	err = p.plotMST(newStreamWriter(w, r), status)
 	if err != nil {
-		fmt.Printf("plotMST error: %v", err)
+		fmt.Printf("plotMST error: %v\n", err)
 	}
 
 }
 
 // main sets up the http handlers, listens, and serves http clients
 func main() {
-	rand.Seed(time.Now().Unix())
+	retention := flag.Duration("retention", 7*24*time.Hour, "remove data files older than this, 0 keeps them forever")
+	janitorInterval := flag.Duration("janitor", time.Hour, "interval between data directory cleanups")
+	adminUser := flag.String("admin-user", "admin", "HTTP Basic user for the admin pages")
+	adminPassword := flag.String("admin-password", "", "HTTP Basic password for the admin pages, empty disables them")
+	auth := flag.String("auth", "", "authentication for the whole app: basic, oidc, or empty for none")
+	authUser := flag.String("auth-user", "", "HTTP Basic user when -auth basic")
+	authPassword := flag.String("auth-password", "", "HTTP Basic password when -auth basic")
+	oidcIssuer := flag.String("oidc-issuer", "", "OpenID Connect issuer URL when -auth oidc")
+	oidcClientID := flag.String("oidc-client-id", "", "OpenID Connect client ID when -auth oidc")
+	oidcClientSecret := flag.String("oidc-client-secret", "", "OpenID Connect client secret when -auth oidc")
+	oidcRedirect := flag.String("oidc-redirect", "http://127.0.0.1:8080"+patternOIDCCallback,
+		"OpenID Connect redirect URL registered with the provider when -auth oidc")
+	flag.StringVar(&priorityQueue, "pq", priorityQueue,
+		"priority queue used by Prim's algorithm: "+strings.Join(solver.QueueNames(), ", "))
+	pqBench := flag.Int("pq-bench", 0, "benchmark the priority queues on a random graph with this many vertices and exit")
+	flag.DurationVar(&computeTimeout, "timeout", 0, "longest computation of a request before returning a partial result, 0 has no limit")
+	stress := flag.Duration("stress", 0, "solve random graphs for this long, report latency percentiles and memory growth, and exit")
+	stressWorkers := flag.Int("stress-workers", runtime.NumCPU(), "concurrent solvers of the -stress test")
+	flag.IntVar(&maxVertices, "max-vertices", maxVertices, "most vertices of a graph, requests over it get the budget error page")
+	flag.IntVar(&maxResolution, "max-grid", maxResolution, "most rows or columns of an auto-sized grid")
+	selftest := flag.Bool("selftest", false, "check the priority queues and MST algorithms against a brute-force MST at startup")
+	listenAddr := flag.String("listen", addr, "listen address: host:port, [ipv6]:port, or unix:/path for a Unix domain socket")
+	responseCacheSize := flag.Int("response-cache", defaultResponseCache, "MSTs of repeated runs with a seed kept in memory, 0 disables the cache")
+	apiListen := flag.String("api-listen", "", "separate listen address of the JSON API, empty serves it with the UI")
+	apiAuthKind := flag.String("api-auth", "", "authentication for the JSON API: basic, token, or empty for the UI's")
+	apiUser := flag.String("api-user", "", "HTTP Basic user when -api-auth basic")
+	apiPassword := flag.String("api-password", "", "HTTP Basic password when -api-auth basic")
+	apiToken := flag.String("api-token", "", "bearer token when -api-auth token")
+	graphCache := flag.Int("graph-cache", defaultGraphCache, "recent graphs kept in memory by graph ID, 0 disables the cache")
+	flag.StringVar(&dataDir, "data-dir", dataDir, "directory for the saved graphs, runs, presets, and exports")
+	theme := flag.String("theme", themes[0], "default page theme: "+strings.Join(themes, ", "))
+	flag.BoolVar(&readOnly, "read-only", false, "public demo mode: keep graphs in memory only, write no data files, and reject uploads")
+	config := flag.String("config", "", "TOML or YAML file of flag settings, the command line flags override it")
+	flag.Parse()
+
+	if len(*config) > 0 {
+		if err := loadConfig(flag.CommandLine, *config); err != nil {
+			log.Fatalf("config error: %v\n", err)
+		}
+	}
+	if err := preferChoice(themes, *theme); err != nil {
+		log.Fatalf("-theme: %v\n", err)
+	}
+
+	if readOnly && *graphCache <= 0 {
+		log.Fatalf("-read-only keeps the graphs in the graph cache, it needs -graph-cache above 0\n")
+	}
+	graphs = newGraphCache(*graphCache)
+	responses = newResponseCache(*responseCacheSize)
+	if maxVertices < minVertices || maxResolution < minResolution {
+		log.Fatalf("-max-vertices must be at least %d and -max-grid at least %d", minVertices, minResolution)
+	}
+	fmt.Printf("Server budget: %d vertices, a %s distance matrix, and %d grid rows or columns.\n",
+		maxVertices, budgetMemory(), maxResolution)
+	if _, err := solver.NewQueue(priorityQueue, 0); err != nil {
+		log.Fatal(err)
+	}
+	if *pqBench > 0 {
+		if err := benchmarkQueues(os.Stdout, *pqBench, 1); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *selftest {
+		if err := selfTest(os.Stdout, selfTestTrials, newSeed()); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *stress > 0 {
+		if err := stressTest(os.Stdout, *stress, *stressWorkers, newSeed()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Prune old saved graphs, exports, and job artifacts in the background, the read-only
+	// demo leaves the data directory alone
+	if !readOnly {
+		go janitor(dataDir, *retention, *janitorInterval)
+	}
+
 	// Set up http servers with handler for Graph Options and Prim MST
 	http.HandleFunc(patternPrimMST, handlePrimMST)
 	http.HandleFunc(patternGraphOptions, handleGraphOptions)
-	fmt.Printf("Prim MST Server listening on %v.\n", addr)
-	http.ListenAndServe(addr, nil)
+	http.HandleFunc(patternFrames, handleFrames)
+	http.HandleFunc(patternStatic, handleStatic)
+	http.HandleFunc(patternPresets, handlePresets)
+	http.HandleFunc(patternScaling, handleScaling)
+	http.HandleFunc(patternNodeLink, handleNodeLink)
+	http.HandleFunc(patternCanvas, handleCanvas)
+	http.HandleFunc(patternCompare, handleCompare)
+	http.HandleFunc(patternDiff, handleDiff)
+	http.HandleFunc(patternRobustness, handleRobustness)
+	http.HandleFunc(patternRandomTree, handleRandomTree)
+	http.HandleFunc(patternTour, handleTour)
+	http.HandleFunc(patternSweep, handleSweep)
+	http.HandleFunc(patternExport, handleExport)
+	http.HandleFunc(patternPin, handlePin)
+	http.HandleFunc(patternArchive, handleArchive)
+	http.HandleFunc(patternStepNext, handleStep)
+	http.HandleFunc(patternStepPrev, handleStep)
+	http.HandleFunc(patternStepReset, handleStep)
+	http.HandleFunc(patternTimeSlice, handleTimeSlice)
+	http.HandleFunc(patternReplay, handleReplay)
+	http.HandleFunc(patternBatch, handleBatch)
+	http.HandleFunc(patternDistances, handleDistances)
+	http.HandleFunc(patternNewick, handleNewick)
+	http.HandleFunc(patternMatrixMarket, handleMatrixMarket)
+	http.HandleFunc(patternDemo, handleDemo)
+	http.HandleFunc(patternDemoEvents, handleDemoEvents)
+	if len(*adminPassword) > 0 {
+		http.Handle(patternAdmin, basicAuth(*adminUser, *adminPassword, adminRealm, http.HandlerFunc(handleAdmin)))
+		http.Handle(patternAdminDownload, basicAuth(*adminUser, *adminPassword, adminRealm, http.HandlerFunc(handleAdminDownload)))
+	}
+
+	// Optionally protect the whole app, the admin pages have their own HTTP Basic user
+	handler := compress(http.DefaultServeMux)
+	protected := handler
+	switch *auth {
+	case "":
+	case "basic":
+		if len(*authUser) == 0 || len(*authPassword) == 0 {
+			log.Fatalf("-auth basic requires -auth-user and -auth-password\n")
+		}
+		protected = basicAuth(*authUser, *authPassword, "Prim MST", handler)
+	case "oidc":
+		o, err := newOIDCAuth(*oidcIssuer, *oidcClientID, *oidcClientSecret, *oidcRedirect)
+		if err != nil {
+			log.Fatalf("OIDC configuration error: %v\n", err)
+		}
+		protected = o.middleware(handler)
+	default:
+		log.Fatalf("Unknown -auth %s, use basic or oidc\n", *auth)
+	}
+	if len(*adminPassword) > 0 {
+		protected = exceptPrefix(patternAdmin, protected, handler)
+	}
+
+	// The JSON API has its own authentication, and optionally its own listener without the UI.
+	// Sharing the UI listener without -api-auth, it has the UI's authentication.
+	apiMux := http.NewServeMux()
+	apiMux.HandleFunc(patternAPIPrimMST, handleAPIPrimMST)
+	apiMux.HandleFunc(patternVertexStream, handleVertexStream)
+	api, err := apiAuth(*apiAuthKind, *apiUser, *apiPassword, *apiToken, compress(apiMux))
+	if err != nil {
+		log.Fatalf("API authentication error: %v\n", err)
+	}
+	if readOnly {
+		protected, api = rejectUploads(protected), rejectUploads(api)
+	}
+	switch {
+	case len(*apiListen) > 0:
+		apiLn, err := listen(*apiListen)
+		if err != nil {
+			log.Fatalf("API listen error: %v\n", err)
+		}
+		fmt.Printf("Prim MST API listening on %v.\n", apiLn.Addr())
+		go func() {
+			log.Fatal(http.Serve(apiLn, api))
+		}()
+	case len(*apiAuthKind) > 0:
+		protected = exceptPrefix(patternAPI, protected, api)
+	default:
+		http.Handle(patternAPI, apiMux)
+	}
+
+	ln, err := listen(*listenAddr)
+	if err != nil {
+		log.Fatalf("listen error: %v\n", err)
+	}
+	fmt.Printf("Prim MST Server listening on %v.\n", ln.Addr())
+	http.Serve(ln, protected)
 }
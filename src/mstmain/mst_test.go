@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// kruskalWeight returns the MST weight of the graph by Kruskal's algorithm, the reference
+func kruskalWeight(graph [][]float64) float64 {
+	var edges [][2]int
+	for v := range graph {
+		for w := v + 1; w < len(graph); w++ {
+			edges = append(edges, [2]int{v, w})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return graph[edges[i][0]][edges[i][1]] < graph[edges[j][0]][edges[j][1]]
+	})
+	component := make([]int, len(graph))
+	for i := range component {
+		component[i] = i
+	}
+	var find func(v int) int
+	find = func(v int) int {
+		if component[v] != v {
+			component[v] = find(component[v])
+		}
+		return component[v]
+	}
+	weight := 0.0
+	for _, e := range edges {
+		if a, b := find(e[0]), find(e[1]); a != b {
+			component[a] = b
+			weight += graph[e[0]][e[1]]
+		}
+	}
+	return weight
+}
+
+// TestFindMST checks Prim's algorithm against Kruskal's on random graphs.  The decrease-key
+// of a queued vertex must find its item by vertex: looking it up by heap index updates the
+// wrong item once a vertex is not at the heap index of its number, and the tree is not minimal.
+func TestFindMST(t *testing.T) {
+	tests := []struct {
+		n    int
+		seed int64
+	}{
+		{2, 1},
+		{5, 2},
+		{12, 3},
+		{40, 4},
+		{100, 5},
+	}
+	for _, tt := range tests {
+		rnd := rand.New(rand.NewSource(tt.seed))
+		graph := make([][]float64, tt.n)
+		for v := range graph {
+			graph[v] = make([]float64, tt.n)
+			graph[v][v] = math.MaxFloat64
+		}
+		for v := 0; v < tt.n; v++ {
+			for w := v + 1; w < tt.n; w++ {
+				d := 1 + rnd.Float64()
+				graph[v][w], graph[w][v] = d, d
+			}
+		}
+		p := &PrimMST{location: make([]complex128, tt.n), graph: graph}
+		if err := p.findMST(); err != nil {
+			t.Fatalf("n %d: %v", tt.n, err)
+		}
+		edges, weight := 0, 0.0
+		for _, e := range p.mst {
+			if e != nil {
+				edges++
+				weight += graph[e.v][e.w]
+			}
+		}
+		want := kruskalWeight(graph)
+		if edges != tt.n-1 || math.Abs(weight-want) > 1e-9 {
+			t.Errorf("n %d: %d edges of weight %g, want %d edges of weight %g", tt.n, edges, weight, tt.n-1, want)
+		}
+	}
+}
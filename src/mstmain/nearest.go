@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// NearestNeighbors summarizes the distance from each vertex to its nearest neighbor and
+// compares the mean with the mean of a random (Poisson) point set of the same density
+type NearestNeighbors struct {
+	Min      float64 `json:"min"`
+	Mean     float64 `json:"mean"`
+	Max      float64 `json:"max"`
+	Expected float64 `json:"expected"` // mean nearest neighbor distance of random points, 0.5/sqrt(density)
+	Ratio    float64 `json:"ratio"`    // Clark-Evans ratio R = mean/expected, < 1 clustered, > 1 dispersed
+	Z        float64 `json:"z"`        // standard normal deviate of R under complete spatial randomness
+}
+
+// String formats the nearest neighbor statistics for the html template
+func (nn NearestNeighbors) String() string {
+	pattern := "random"
+	switch {
+	case nn.Z < -1.96:
+		pattern = "clustered"
+	case nn.Z > 1.96:
+		pattern = "dispersed"
+	}
+	return fmt.Sprintf("nearest neighbor min %.3g, mean %.3g, max %.3g, Clark-Evans R %.3f (z %.2f, %s)",
+		nn.Min, nn.Mean, nn.Max, nn.Ratio, nn.Z, pattern)
+}
+
+// nearestNeighbors computes the nearest neighbor statistics of the vertices within the endpoints
+func (p *PrimMST) nearestNeighbors() *NearestNeighbors {
+	n := len(p.location)
+	if n < 2 {
+		return nil
+	}
+	nn := &NearestNeighbors{Min: math.MaxFloat64}
+	for _, nearest := range p.nearestDistances() {
+		nn.Min = math.Min(nn.Min, nearest)
+		nn.Max = math.Max(nn.Max, nearest)
+		nn.Mean += nearest
+	}
+	nn.Mean /= float64(n)
+
+	// Clark and Evans (1954): for density ρ the expected mean is 1/(2√ρ)
+	// with standard error 0.26136/√(nρ)
+	density := float64(n) / ((p.xmax - p.xmin) * (p.ymax - p.ymin))
+	nn.Expected = 0.5 / math.Sqrt(density)
+	nn.Ratio = nn.Mean / nn.Expected
+	nn.Z = (nn.Mean - nn.Expected) / (0.26136 / math.Sqrt(float64(n)*density))
+	return nn
+}
+
+// nearestDistances returns the distance from each vertex to its nearest neighbor, from the
+// distance matrix or, without one, the Euclidean distance found with a grid of the vertices
+func (p *PrimMST) nearestDistances() []float64 {
+	n := len(p.location)
+	nearest := make([]float64, n)
+	if p.graph == nil {
+		g := newVertexGrid(p.location)
+		for v := range nearest {
+			nearest[v] = math.MaxFloat64
+			if nb := g.nearest(v, 1, math.Inf(1), nil); len(nb) > 0 {
+				nearest[v] = nb[0].distance
+			}
+		}
+		return nearest
+	}
+	for v := 0; v < n; v++ {
+		// p.graph[v][v] is MaxFloat64 so v is not its own nearest neighbor
+		nearest[v] = math.MaxFloat64
+		for w := 0; w < n; w++ {
+			nearest[v] = math.Min(nearest[v], p.graph[v][w])
+		}
+	}
+	return nearest
+}
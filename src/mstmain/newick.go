@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const patternNewick = "/primmstnewick" // http handler for the Newick download
+
+// newickLabel quotes the label if it has Newick punctuation or whitespace
+func newickLabel(label string) string {
+	if strings.ContainsAny(label, " \t()[]':;,") {
+		return "'" + strings.ReplaceAll(label, "'", "''") + "'"
+	}
+	return label
+}
+
+// newick returns the MST rooted at the start vertex in Newick format.  The vertices are
+// labeled with their names or v<index> and the branch lengths are the edge distances.
+func (p *PrimMST) newick() string {
+	adj := p.adjacency()
+	var sb strings.Builder
+	var write func(v, parent int)
+	write = func(v, parent int) {
+		children := make([]int, 0, len(adj[v]))
+		for _, w := range adj[v] {
+			if w != parent {
+				children = append(children, w)
+			}
+		}
+		sort.Ints(children)
+		if len(children) > 0 {
+			sb.WriteByte('(')
+			for i, w := range children {
+				if i > 0 {
+					sb.WriteByte(',')
+				}
+				write(w, v)
+			}
+			sb.WriteByte(')')
+		}
+		sb.WriteString(newickLabel(p.name(v)))
+		if parent >= 0 {
+			sb.WriteString(":" + strconv.FormatFloat(p.weight(parent, v), 'g', -1, 64))
+		}
+	}
+	write(p.start, -1)
+	sb.WriteString(";\n")
+	return sb.String()
+}
+
+// HTTP handler for /primmstnewick connections, manifest=1 downloads its manifest
+func handleNewick(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	p.serveExport(w, r, export{"primmst.nwk", "text/plain; charset=utf-8", func(w io.Writer) error {
+		_, err := io.WriteString(w, p.newick())
+		return err
+	}})
+}
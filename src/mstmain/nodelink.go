@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const patternNodeLink = "/primmstnodelink" // http handler for the node-link JSON of the last MST
+
+// NodeJSON is a vertex in the node-link format
+type NodeJSON struct {
+	ID      int     `json:"id"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Start   bool    `json:"start,omitempty"`   // the MST start vertex
+	Order   int     `json:"order"`             // Prim insertion order
+	Name    string  `json:"name,omitempty"`    // name from the imported vertex list
+	Outlier bool    `json:"outlier,omitempty"` // the only MST edge of the vertex is anomalously long
+}
+
+// LinkJSON is an edge in the node-link format
+type LinkJSON struct {
+	Source int     `json:"source"`
+	Target int     `json:"target"`
+	Weight float64 `json:"weight"`
+	MST    bool    `json:"mst"` // the edge is in the MST
+}
+
+// NodeLinkJSON is the node-link format read by d3.js force layouts and NetworkX json_graph.node_link_graph
+type NodeLinkJSON struct {
+	Directed   bool           `json:"directed"`
+	Multigraph bool           `json:"multigraph"`
+	Graph      map[string]any `json:"graph"`
+	Nodes      []NodeJSON     `json:"nodes"`
+	Links      []LinkJSON     `json:"links"`
+}
+
+// nodeLink creates the node-link format of the MST, and of the complete graph if complete is true
+func (p *PrimMST) nodeLink(complete bool) *NodeLinkJSON {
+	nl := &NodeLinkJSON{
+		Graph: map[string]any{
+			"algorithm": p.meta.Algorithm,
+			"metric":    p.meta.Metric,
+			"seed":      p.meta.Seed,
+			"distance":  p.totalDistance(),
+		},
+		Nodes: make([]NodeJSON, len(p.location)),
+		Links: make([]LinkJSON, 0, len(p.location)),
+	}
+	if len(p.meta.Projection) > 0 {
+		nl.Graph["projection"] = p.meta.Projection
+	}
+	if len(p.meta.Units) > 0 {
+		nl.Graph["units"] = p.meta.Units
+	}
+	if len(p.annotations) > 0 {
+		nl.Graph["annotations"] = p.annotations
+	}
+	for i, z := range p.location {
+		nl.Nodes[i] = NodeJSON{ID: i, X: real(z), Y: imag(z), Start: i == p.start}
+		if p.names != nil {
+			nl.Nodes[i].Name = p.names[i]
+		}
+		if p.outlier != nil {
+			nl.Nodes[i].Outlier = p.outlier[i]
+		}
+	}
+	for rank, v := range p.order {
+		nl.Nodes[v].Order = rank
+	}
+
+	inMST := p.edgeSet()
+	if complete {
+		for v := range p.location {
+			for w := v + 1; w < len(p.location); w++ {
+				nl.Links = append(nl.Links, LinkJSON{Source: v, Target: w, Weight: p.weight(v, w), MST: inMST[Edge{v: v, w: w}]})
+			}
+		}
+		return nl
+	}
+	for _, v := range p.order {
+		if e := p.mst[v]; e != nil {
+			nl.Links = append(nl.Links, LinkJSON{Source: e.v, Target: e.w, Weight: p.weight(e.v, e.w), MST: true})
+		}
+	}
+	return nl
+}
+
+// writeNodeLink writes the node-link JSON, complete=1 in the request includes every graph edge
+func (p *PrimMST) writeNodeLink(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p.nodeLink(len(r.FormValue("complete")) > 0)); err != nil {
+		fmt.Printf("JSON encode error: %v\n", err)
+	}
+}
+
+// HTTP handler for /primmstnodelink connections, complete=1 includes every graph edge and
+// manifest=1 downloads its manifest
+func handleNodeLink(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	complete := len(r.FormValue("complete")) > 0
+	p.serveExport(w, r, export{"primmst.json", "application/json", func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(p.nodeLink(complete))
+	}})
+}
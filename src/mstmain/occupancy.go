@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+const maxSuggestedFactor = 16 // largest resolution or zoom factor suggested to separate vertices
+
+// Occupancy reports the plot cells that more than one vertex was rasterized into
+type Occupancy struct {
+	Cells  int     // cells with more than one vertex
+	Hidden int     // vertices drawn over by another vertex in the same cell
+	Factor float64 // resolution or zoom factor that separates the vertices, 0 if they coincide
+	Rows   int     // #rows in the grid
+	Cols   int     // #columns in the grid
+}
+
+// String formats the occupancy warning for the status
+func (o Occupancy) String() string {
+	s := fmt.Sprintf("%d vertices are hidden in %d shared plot cells", o.Hidden, o.Cells)
+	switch {
+	case o.Factor == 0:
+		return s + ", they coincide at any resolution"
+	case o.Factor > maxSuggestedFactor:
+		return s + fmt.Sprintf(", zoom in more than %dx to separate them", maxSuggestedFactor)
+	}
+	r := int(math.Ceil(o.Factor * float64(o.Rows)))
+	c := int(math.Ceil(o.Factor * float64(o.Cols)))
+	return s + fmt.Sprintf(", a %dx%d grid or zooming in %.1fx would separate them", r, c, o.Factor)
+}
+
+// vertex colors the grid cell of vertex v at x,y and records the cell's occupancy
+func (g *gridPlot) vertex(v int, x, y float64, class string) {
+	row, col := g.rowCol(x, y)
+	g.set(row, col, class)
+	if row < 0 || row >= g.rows || col < 0 || col >= g.columns {
+		return
+	}
+	if g.occupied == nil {
+		g.occupied = make([][]int, len(g.grid))
+	}
+	i := row*g.columns + col
+	g.occupied[i] = append(g.occupied[i], v)
+}
+
+// occupancy counts the cells that hold more than one of the rasterized vertices, and finds
+// the smallest factor that puts the closest pair in a cell at least one cell apart
+func (g *gridPlot) occupancy(location []complex128) Occupancy {
+	o := Occupancy{Rows: g.rows, Cols: g.columns}
+	closest := math.Inf(1)
+	for _, vs := range g.occupied {
+		if len(vs) < 2 {
+			continue
+		}
+		o.Cells++
+		o.Hidden += len(vs) - 1
+		for i, v := range vs {
+			for _, w := range vs[i+1:] {
+				d := location[v] - location[w]
+				cells := math.Max(math.Abs(real(d))*g.xscale, math.Abs(imag(d))*g.yscale)
+				if cells > 0 && cells < closest {
+					closest = cells
+				}
+			}
+		}
+	}
+	if !math.IsInf(closest, 1) {
+		o.Factor = 1 / closest
+	}
+	return o
+}
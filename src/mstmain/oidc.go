@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	patternOIDCCallback = "/oidc/callback" // http handler for the OpenID Connect authorization code
+	oidcSessionCookie   = "primmst_session"
+	oidcStateCookie     = "primmst_oidc_state"
+	oidcSessionLifetime = 12 * time.Hour
+)
+
+// oidcAuth authenticates users with the OpenID Connect authorization code flow, with PKCE
+// and a nonce, and keeps them signed in with an HMAC-signed session cookie
+type oidcAuth struct {
+	issuer        string
+	clientID      string
+	clientSecret  string
+	redirectURL   string // external URL of patternOIDCCallback registered with the provider
+	authEndpoint  string
+	tokenEndpoint string
+	key           []byte // session cookie signing key, new for each server start
+	secure        bool   // cookies are Secure unless the redirect URL is plain http, for local testing
+	client        *http.Client
+}
+
+// newOIDCAuth reads the provider endpoints from the issuer discovery document
+func newOIDCAuth(issuer, clientID, clientSecret, redirectURL string) (*oidcAuth, error) {
+	o := &oidcAuth{
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		key:          make([]byte, 32),
+		secure:       !strings.HasPrefix(strings.ToLower(redirectURL), "http://"),
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+	if _, err := rand.Read(o.key); err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Get(o.issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery for %s returned %s", o.issuer, resp.Status)
+	}
+	var discovery struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != o.issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer %s does not match %s", discovery.Issuer, o.issuer)
+	}
+	o.authEndpoint = discovery.AuthorizationEndpoint
+	o.tokenEndpoint = discovery.TokenEndpoint
+	return o, nil
+}
+
+// randomToken returns a random URL-safe string of 43 characters, long enough for a PKCE
+// code verifier
+func randomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// codeChallenge returns the PKCE S256 code challenge of the code verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// cookie returns a cookie of the value with the app's cookie attributes, HttpOnly,
+// SameSite=Lax, and Secure unless the redirect URL is plain http
+func (o *oidcAuth) cookie(name, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{Name: name, Value: value, Path: "/", HttpOnly: true, Secure: o.secure,
+		SameSite: http.SameSiteLaxMode, MaxAge: maxAge}
+}
+
+// sign returns the HMAC of value with the session key
+func (o *oidcAuth) sign(value string) string {
+	mac := hmac.New(sha256.New, o.key)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validSession checks the signature and expiry of the session cookie
+func (o *oidcAuth) validSession(r *http.Request) bool {
+	c, err := r.Cookie(oidcSessionCookie)
+	if err != nil {
+		return false
+	}
+	// The cookie is subject|expiry|signature
+	parts := strings.Split(c.Value, "|")
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[2]), []byte(o.sign(parts[0]+"|"+parts[1]))) {
+		return false
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	return err == nil && time.Now().Unix() < expiry
+}
+
+// exchange trades the authorization code and the PKCE code verifier for the ID token,
+// checks that it carries the nonce of the sign in, and returns its subject.  The ID token
+// comes directly from the token endpoint over TLS, which OpenID Connect Core 3.1.3.7 allows
+// in place of checking its signature.
+func (o *oidcAuth) exchange(code, verifier, nonce string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.redirectURL},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, o.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.clientID), url.QueryEscape(o.clientSecret))
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OIDC token endpoint returned %s", resp.Status)
+	}
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(token.IDToken, ".")
+	if len(parts) != 3 {
+		return "", errors.New("OIDC token endpoint returned a malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+	var claims struct {
+		Issuer   string          `json:"iss"`
+		Subject  string          `json:"sub"`
+		Audience json.RawMessage `json:"aud"`
+		Expiry   int64           `json:"exp"`
+		Nonce    string          `json:"nonce"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", err
+	}
+	// The audience is a string or an array of strings
+	var audiences []string
+	if err := json.Unmarshal(claims.Audience, &audiences); err != nil {
+		var aud string
+		if err := json.Unmarshal(claims.Audience, &aud); err != nil {
+			return "", err
+		}
+		audiences = []string{aud}
+	}
+	audienceOK := false
+	for _, aud := range audiences {
+		audienceOK = audienceOK || aud == o.clientID
+	}
+	switch {
+	case strings.TrimSuffix(claims.Issuer, "/") != o.issuer:
+		return "", fmt.Errorf("ID token issuer %s does not match %s", claims.Issuer, o.issuer)
+	case !audienceOK:
+		return "", errors.New("ID token audience does not include the client ID")
+	case time.Now().Unix() >= claims.Expiry:
+		return "", errors.New("ID token has expired")
+	case len(nonce) == 0 || !hmac.Equal([]byte(claims.Nonce), []byte(nonce)):
+		return "", errors.New("ID token nonce does not match the sign in")
+	case strings.ContainsRune(claims.Subject, '|'):
+		return "", errors.New("ID token subject is invalid")
+	}
+	return claims.Subject, nil
+}
+
+// handleCallback completes the authorization code flow and sets the session cookie
+func (o *oidcAuth) handleCallback(w http.ResponseWriter, r *http.Request) {
+	// The state cookie is state|nonce|verifier|target
+	c, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		http.Error(w, "Invalid OIDC state", http.StatusBadRequest)
+		return
+	}
+	parts := strings.SplitN(c.Value, "|", 4)
+	if len(parts) != 4 || len(parts[0]) == 0 || !hmac.Equal([]byte(r.FormValue("state")), []byte(parts[0])) {
+		http.Error(w, "Invalid OIDC state", http.StatusBadRequest)
+		return
+	}
+	nonce, verifier, target := parts[1], parts[2], parts[3]
+	subject, err := o.exchange(r.FormValue("code"), verifier, nonce)
+	if err != nil {
+		fmt.Printf("OIDC exchange error: %v\n", err)
+		http.Error(w, "OIDC sign in failed", http.StatusUnauthorized)
+		return
+	}
+
+	value := subject + "|" + strconv.FormatInt(time.Now().Add(oidcSessionLifetime).Unix(), 10)
+	http.SetCookie(w, o.cookie(oidcSessionCookie, value+"|"+o.sign(value), int(oidcSessionLifetime.Seconds())))
+	http.SetCookie(w, o.cookie(oidcStateCookie, "", -1))
+
+	// Return to the page that was requested before signing in
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		target = patternGraphOptions
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// middleware redirects requests without a valid session to the provider to sign in
+func (o *oidcAuth) middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == patternOIDCCallback {
+			o.handleCallback(w, r)
+			return
+		}
+		if o.validSession(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		state, nonce, verifier := randomToken(), randomToken(), randomToken()
+		target := r.URL.Path
+		if r.Method != http.MethodGet {
+			target = patternGraphOptions
+		}
+		http.SetCookie(w, o.cookie(oidcStateCookie, state+"|"+nonce+"|"+verifier+"|"+target, 600))
+		q := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {o.clientID},
+			"redirect_uri":          {o.redirectURL},
+			"scope":                 {"openid"},
+			"state":                 {state},
+			"nonce":                 {nonce},
+			"code_challenge":        {codeChallenge(verifier)},
+			"code_challenge_method": {"S256"},
+		}
+		http.Redirect(w, r, o.authEndpoint+"?"+q.Encode(), http.StatusFound)
+	})
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeProvider is an OpenID Connect provider that checks the PKCE code verifier and returns
+// an ID token with the nonce of the authorization request, or with nonce when it is set
+type fakeProvider struct {
+	*httptest.Server
+	clientID  string
+	challenge string // code challenge of the authorization request
+	nonce     string // nonce of the authorization request
+}
+
+func newFakeProvider(t *testing.T, clientID, nonce string) *fakeProvider {
+	fp := &fakeProvider{clientID: clientID}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 fp.URL,
+			"authorization_endpoint": fp.URL + "/authorize",
+			"token_endpoint":         fp.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != fp.challenge || r.PostFormValue("code") != "code" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		claims := map[string]interface{}{"iss": fp.URL, "sub": "user", "aud": fp.clientID,
+			"exp": time.Now().Add(time.Hour).Unix(), "nonce": fp.nonce}
+		if len(nonce) > 0 {
+			claims["nonce"] = nonce
+		}
+		b, _ := json.Marshal(claims)
+		json.NewEncoder(w).Encode(map[string]string{
+			"id_token": "e30." + base64.RawURLEncoding.EncodeToString(b) + ".sig"})
+	})
+	fp.Server = httptest.NewServer(mux)
+	t.Cleanup(fp.Close)
+	return fp
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	tests := []struct {
+		name        string
+		redirect    string
+		tokenNonce  string // nonce the provider puts in the ID token, empty for the request's
+		wrongState  bool
+		wantCode    int
+		wantSecure  bool
+		wantSession bool
+	}{
+		{"https", "https://app.example/oidc/callback", "", false, http.StatusFound, true, true},
+		{"local http", "http://127.0.0.1:8080/oidc/callback", "", false, http.StatusFound, false, true},
+		{"replayed nonce", "https://app.example/oidc/callback", "other", false, http.StatusUnauthorized, true, false},
+		{"wrong state", "https://app.example/oidc/callback", "", true, http.StatusBadRequest, true, false},
+	}
+	for _, tt := range tests {
+		fp := newFakeProvider(t, "client", tt.tokenNonce)
+		o, err := newOIDCAuth(fp.URL, "client", "secret", tt.redirect)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		h := o.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		// The first request is sent to the provider with a nonce and an S256 code challenge
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/primmst", nil))
+		loc, err := url.Parse(w.Header().Get("Location"))
+		if err != nil || w.Code != http.StatusFound {
+			t.Fatalf("%s: sign in redirect %d %v", tt.name, w.Code, err)
+		}
+		q := loc.Query()
+		if q.Get("code_challenge_method") != "S256" || len(q.Get("nonce")) == 0 {
+			t.Fatalf("%s: authorization request %v has no S256 challenge or nonce", tt.name, q)
+		}
+		fp.challenge, fp.nonce = q.Get("code_challenge"), q.Get("nonce")
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Secure != tt.wantSecure || cookies[0].SameSite != http.SameSiteLaxMode ||
+			!cookies[0].HttpOnly {
+			t.Fatalf("%s: state cookie %+v", tt.name, cookies)
+		}
+
+		// The provider redirects back with the code and the state
+		state := q.Get("state")
+		if tt.wrongState {
+			state = "forged"
+		}
+		r := httptest.NewRequest(http.MethodGet, patternOIDCCallback+"?code=code&state="+url.QueryEscape(state), nil)
+		r.AddCookie(cookies[0])
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: callback status %d, want %d", tt.name, w.Code, tt.wantCode)
+		}
+		session := false
+		for _, c := range w.Result().Cookies() {
+			if c.Name == oidcSessionCookie && len(c.Value) > 0 {
+				session = true
+				if c.Secure != tt.wantSecure || c.SameSite != http.SameSiteLaxMode || !c.HttpOnly {
+					t.Errorf("%s: session cookie %+v", tt.name, c)
+				}
+			}
+		}
+		if session != tt.wantSession {
+			t.Errorf("%s: session cookie set %v, want %v", tt.name, session, tt.wantSession)
+		}
+		if tt.wantSession && w.Header().Get("Location") != "/primmst" {
+			t.Errorf("%s: redirect to %q, want /primmst", tt.name, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	verifier := randomToken()
+	if len(verifier) < 43 || strings.ContainsAny(verifier, "+/=") {
+		t.Errorf("code verifier %q is not 43 URL-safe characters", verifier)
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if got, want := codeChallenge(verifier), base64.RawURLEncoding.EncodeToString(sum[:]); got != want {
+		t.Errorf("codeChallenge = %s, want %s", got, want)
+	}
+}
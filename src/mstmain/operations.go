@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Operations are the solver operations counted while finding the MST, to compare with the
+// complexity of the algorithm as the number of vertices grows
+type Operations struct {
+	Algorithm   string  `json:"algorithm"`             // algorithm and priority queue
+	Vertices    int     `json:"vertices"`              // V
+	Edges       int     `json:"edges"`                 // E, the candidate edges
+	Push        int     `json:"push,omitempty"`        // heap pushes
+	Pop         int     `json:"pop,omitempty"`         // heap pops
+	DecreaseKey int     `json:"decreaseKey,omitempty"` // heap decrease-key operations
+	Comparisons int     `json:"comparisons,omitempty"` // heap distance comparisons
+	Scanned     int     `json:"edgesScanned"`          // edges scanned
+	Find        int     `json:"find,omitempty"`        // union-find find operations
+	Union       int     `json:"union,omitempty"`       // union-find union operations
+	Bound       float64 `json:"bound"`                 // E log2 V, the comparison bound of the algorithm
+}
+
+// String formats the counts and the bound for the html template
+func (o Operations) String() string {
+	var counts []string
+	for _, c := range []struct {
+		name  string
+		count int
+	}{
+		{"pushes", o.Push}, {"pops", o.Pop}, {"decrease-keys", o.DecreaseKey}, {"comparisons", o.Comparisons},
+		{"edges scanned", o.Scanned}, {"finds", o.Find}, {"unions", o.Union},
+	} {
+		if c.count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", c.count, c.name))
+		}
+	}
+	return fmt.Sprintf("%s operations, V = %d, E = %d: %s; E log2 V = %.0f", o.Algorithm, o.Vertices, o.Edges,
+		strings.Join(counts, ", "), o.Bound)
+}
+
+// countOperations starts the operation counts of the algorithm on e candidate edges
+func (p *PrimMST) countOperations(algorithm string, e int) {
+	n := len(p.location)
+	p.ops = &Operations{Algorithm: algorithm, Vertices: n, Edges: e}
+	if n > 1 {
+		p.ops.Bound = float64(e) * math.Log2(float64(n))
+	}
+}
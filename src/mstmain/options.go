@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Graph option defaults and validation ranges injected into the graph options form
+const (
+	defaultVertices = 100   // default number of vertices
+	minVertices     = 2     // minimum number of vertices
+	defaultXmin     = -10.0 // default x minimum endpoint in Euclidean graph
+	defaultXmax     = 10.0  // default x maximum endpoint in Euclidean graph
+	defaultYmin     = -10.0 // default y minimum endpoint in Euclidean graph
+	defaultYmax     = 10.0  // default y maximum endpoint in Euclidean graph
+	maxEchoed       = 32    // most bytes of an invalid form value repeated in a status message
+)
+
+// Available MST algorithms, distance metrics, and page themes, the first is the default.
+// The metrics are the registered weight models and Terrain.
+var (
+	algorithms = []string{"Prim", algorithmKruskal, algorithmKruskalParallel, algorithmApproximate,
+		algorithmGabriel, algorithmRNG}
+	metrics = func() []string {
+		names := make([]string, 0, len(weightModels)+1)
+		for _, m := range weightModels {
+			names = append(names, m.name)
+		}
+		return append(names, metricTerrain)
+	}()
+	themes = []string{"light", "dark"}
+)
+
+// preferChoice makes the named choice the default, the first
+func preferChoice(list []string, name string) error {
+	for i, c := range list {
+		if c == name {
+			copy(list[1:i+1], list[:i])
+			list[0] = name
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not one of %s", name, strings.Join(list, ", "))
+}
+
+// Choice is a select option in the graph options form
+type Choice struct {
+	Value    string
+	Selected bool
+}
+
+// Type to contain all the graph options HTML template actions
+type OptionsT struct {
+	Vertices     int           // default number of vertices
+	MinVertices  int           // minimum number of vertices
+	MaxVertices  int           // maximum number of vertices
+	MaxGrid      int           // maximum grid rows or columns
+	Xmin         float64       // default x minimum endpoint in Euclidean graph
+	Xmax         float64       // default x maximum endpoint in Euclidean graph
+	Ymin         float64       // default y minimum endpoint in Euclidean graph
+	Ymax         float64       // default y maximum endpoint in Euclidean graph
+	Algorithms   []Choice      // MST algorithms
+	Metrics      []Choice      // distance metrics
+	Themes       []Choice      // page themes
+	Layers       []LayerChoice // plot layers drawn
+	Clusters     int           // default number of MST clusters
+	Prizes       []Choice      // prize modes
+	AttachBy     []Choice      // facility attachment distances
+	Surfaces     []Choice      // terrain elevation surfaces
+	Duplicates   []Choice      // policies for duplicate and near-coincident vertices
+	Projections  []Choice      // projections of lon,lat vertex lists
+	LabelStyles  []Choice      // axis label and distance number styles
+	Locales      []Choice      // number format and status message locales
+	Views        []Choice      // results views, the grid plot or tables
+	BatchFormats []Choice      // batch export image formats
+	Colors       []LayerColor  // default layer colors
+	Precision    int           // default label precision
+	MaxPrecision int           // maximum label precision
+	Presets      []Choice      // saved presets
+	Status       string        // status of the presets
+	ReadOnly     bool          // read-only demo without uploads or saved files
+}
+
+// choices creates the select options with the selected value marked
+func choices(values []string, selected string) []Choice {
+	c := make([]Choice, len(values))
+	for i, v := range values {
+		c[i] = Choice{Value: v, Selected: v == selected}
+	}
+	return c
+}
+
+// formChoice returns the form value for name if it is one of values, or the
+// default values[0] if the form value is empty
+func formChoice(r *http.Request, name string, values []string) (string, error) {
+	v := r.FormValue(name)
+	if len(v) == 0 {
+		return values[0], nil
+	}
+	for _, value := range values {
+		if v == value {
+			return v, nil
+		}
+	}
+	if len(v) > maxEchoed {
+		v = v[:maxEchoed] + "..."
+	}
+	return values[0], fmt.Errorf("unknown %s %q, using %s", name, v, values[0])
+}
+
+// defaultOptions creates the graph options with the server-side defaults
+func defaultOptions() OptionsT {
+	return OptionsT{
+		Vertices:     defaultVertices,
+		MinVertices:  minVertices,
+		MaxVertices:  maxVertices,
+		MaxGrid:      maxResolution,
+		Xmin:         defaultXmin,
+		Xmax:         defaultXmax,
+		Ymin:         defaultYmin,
+		Ymax:         defaultYmax,
+		Algorithms:   choices(algorithms, algorithms[0]),
+		Metrics:      choices(metrics, metrics[0]),
+		Themes:       choices(themes, themes[0]),
+		Layers:       layerChoices(defaultLayerSet()),
+		Clusters:     defaultClusters,
+		Prizes:       choices(prizeModes, prizeModes[0]),
+		AttachBy:     choices(facilityDistances, facilityDistances[0]),
+		Surfaces:     choices(terrainSurfaces, terrainSurfaces[0]),
+		Duplicates:   choices(separationPolicies, separationPolicies[0]),
+		Projections:  choices(projections, projections[0]),
+		LabelStyles:  choices(labelStyles, labelStyles[0]),
+		Locales:      choices(localeNames, localeNames[0]),
+		Views:        choices(views, views[0]),
+		ReadOnly:     readOnly,
+		BatchFormats: choices(batchFormats, batchFormats[0]),
+		Colors:       layerColors,
+		Precision:    defaultPrecision,
+		MaxPrecision: maxPrecision,
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFormChoice(t *testing.T) {
+	values := []string{"light", "dark"}
+	long := strings.Repeat("x", maxEchoed+10)
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string // the error message, empty for none
+	}{
+		{"empty", "", "light", ""},
+		{"default", "light", "light", ""},
+		{"other", "dark", "dark", ""},
+		{"case matters", "Dark", "light", `unknown theme "Dark", using light`},
+		{"markup is quoted", `<script>"x"</script>`, "light", `unknown theme "<script>\"x\"</script>", using light`},
+		{"long value is truncated", long, "light", `unknown theme "` + long[:maxEchoed] + `...", using light`},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/?theme="+url.QueryEscape(tt.value), nil)
+		got, err := formChoice(r, "theme", values)
+		if got != tt.want {
+			t.Errorf("%s: formChoice = %q, want %q", tt.name, got, tt.want)
+		}
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if msg != tt.wantErr {
+			t.Errorf("%s: error %q, want %q", tt.name, msg, tt.wantErr)
+		}
+	}
+}
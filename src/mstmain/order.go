@@ -0,0 +1,23 @@
+package main
+
+import "fmt"
+
+const orderBuckets = 10 // number of CSS classes for the Prim insertion order colors
+
+// orderColoring colors the vertices by the order Prim's algorithm added them to the MST,
+// from light (early) to dark (late)
+func (p *PrimMST) orderColoring() {
+	p.vertexClass = make([]string, len(p.location))
+	for rank, v := range p.order {
+		p.vertexClass[v] = fmt.Sprintf("order%d", rank*orderBuckets/len(p.order))
+	}
+}
+
+// plotOrderPath draws a faint path through the vertices in the order Prim added them
+func (p *PrimMST) plotOrderPath(g *gridPlot) {
+	pairs := make([][2]int, 0, len(p.order))
+	for i := 1; i < len(p.order); i++ {
+		pairs = append(pairs, [2]int{p.order[i-1], p.order[i]})
+	}
+	p.drawPairs(g, pairs, "orderpath")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+)
+
+const defaultOutlierSigma = 3.0 // outlier edges are longer than the mean plus this many standard deviations
+
+// Outlier is a vertex whose only MST edge is anomalously long
+type Outlier struct {
+	Vertex int    // vertex index
+	Name   string // vertex name, or v and the index when unnamed
+	X      string // x coordinate in the label format
+	Y      string // y coordinate in the label format
+	Length string // length of the vertex's MST edge in the label format
+	Sigmas string // standard deviations of the edge length above the mean
+}
+
+// formOutliers gets the outlier checkbox and the standard deviation threshold from the HTML form
+func (p *PrimMST) formOutliers(r *http.Request) error {
+	if len(r.FormValue("outliers")) == 0 {
+		return nil
+	}
+	sigma, err := formFloat(r, "outliersigma", defaultOutlierSigma)
+	if err != nil {
+		return err
+	}
+	if sigma < 0 {
+		return fmt.Errorf("outlier threshold %g is negative", sigma)
+	}
+	p.outlierSigma = &sigma
+	return nil
+}
+
+// findOutliers flags the leaves of the MST whose edge is longer than the mean
+// MST edge length plus sigma standard deviations
+func (p *PrimMST) findOutliers(sigma float64) {
+	p.outliers = nil
+	p.outlier = make([]bool, len(p.location))
+
+	// MST edge lengths and vertex degrees
+	degree := make([]int, len(p.location))
+	var sum, sumsq float64
+	nedges := 0
+	for _, e := range p.mst {
+		if e == nil {
+			continue
+		}
+		d := p.weight(e.v, e.w)
+		sum += d
+		sumsq += d * d
+		degree[e.v]++
+		degree[e.w]++
+		nedges++
+	}
+	if nedges == 0 {
+		return
+	}
+	mean := sum / float64(nedges)
+	stddev := math.Sqrt(math.Max(sumsq/float64(nedges)-mean*mean, 0))
+	if stddev == 0 {
+		return
+	}
+
+	// A leaf's only MST edge is the edge to its parent, or to its only child at the start vertex
+	for _, e := range p.mst {
+		if e == nil {
+			continue
+		}
+		d := p.weight(e.v, e.w)
+		if d <= mean+sigma*stddev {
+			continue
+		}
+		for _, v := range []int{e.v, e.w} {
+			if degree[v] != 1 {
+				continue
+			}
+			p.outlier[v] = true
+			p.outliers = append(p.outliers, Outlier{
+				Vertex: v,
+				Name:   p.name(v),
+				X:      p.format.format(real(p.location[v])),
+				Y:      p.format.format(imag(p.location[v])),
+				Length: p.format.format(d),
+				Sigmas: fmt.Sprintf("%.2f", (d-mean)/stddev),
+			})
+		}
+	}
+}
+
+// outlierSummary reports the number of outliers and the threshold
+func (p *PrimMST) outlierSummary() string {
+	return fmt.Sprintf("%d outliers with an MST edge over mean + %gσ", len(p.outliers), *p.outlierSigma)
+}
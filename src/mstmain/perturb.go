@@ -0,0 +1,82 @@
+package main
+
+import "fmt"
+
+// Perturbation is the result of jittering the vertices and finding the MST again
+type Perturbation struct {
+	Epsilon float64 // maximum jitter in x and y
+	Changed int     // number of MST edges not in the MST before the jitter
+	Edges   int     // number of MST edges
+}
+
+// String formats the perturbation for the html template
+func (pt Perturbation) String() string {
+	return fmt.Sprintf("jitter %g changed %d of %d edges", pt.Epsilon, pt.Changed, pt.Edges)
+}
+
+// key returns the edge with its vertices in increasing order so that v-w and w-v are equal
+func (e Edge) key() Edge {
+	if e.v > e.w {
+		return Edge{v: e.w, w: e.v}
+	}
+	return e
+}
+
+// edgeSet returns the set of MST edges
+func (p *PrimMST) edgeSet() map[Edge]bool {
+	set := make(map[Edge]bool, len(p.mst))
+	for _, e := range p.mst {
+		if e != nil {
+			set[e.key()] = true
+		}
+	}
+	return set
+}
+
+// jitter moves each vertex by a uniform random amount in [-epsilon, epsilon] in x and y,
+// keeping the vertices inside the endpoints
+func (p *PrimMST) jitter(epsilon float64) {
+	clamp := func(v, min, max float64) float64 {
+		if v < min {
+			return min
+		}
+		if v > max {
+			return max
+		}
+		return v
+	}
+	for i, z := range p.location {
+		x := clamp(real(z)+epsilon*(2*p.rnd.Float64()-1), p.xmin, p.xmax)
+		y := clamp(imag(z)+epsilon*(2*p.rnd.Float64()-1), p.ymin, p.ymax)
+		p.location[i] = complex(x, y)
+	}
+}
+
+// perturb jitters the vertices of the MST, finds the MST again, and marks the edges that changed
+func (p *PrimMST) perturb(epsilon float64) (Perturbation, error) {
+	before := p.edgeSet()
+	p.jitter(epsilon)
+	p.graph = nil
+	if p.meta.Algorithm != algorithmApproximate {
+		if err := p.findDistances(); err != nil {
+			return Perturbation{}, err
+		}
+	}
+	if err := p.solve(); err != nil {
+		return Perturbation{}, err
+	}
+	// The cost and hash are of the tree shown, the MST of the jittered vertices
+	p.cost = p.costBreakdown()
+	p.meta.Tree = p.treeHash()
+
+	pt := Perturbation{Epsilon: epsilon}
+	p.changed = make(map[Edge]bool)
+	for e := range p.edgeSet() {
+		pt.Edges++
+		if !before[e] {
+			p.changed[e] = true
+			pt.Changed++
+		}
+	}
+	return pt, nil
+}
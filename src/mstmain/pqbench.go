@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+// priorityQueue is the priority queue implementation used by Prim's algorithm, set by -pq
+var priorityQueue = "binary"
+
+// benchmarkQueues finds the MST of the same random graph of n vertices with each priority
+// queue implementation and writes the operation counts and times as a table
+func benchmarkQueues(w io.Writer, n int, seed int64) error {
+	if n < minVertices {
+		return fmt.Errorf("benchmark needs at least %d vertices", minVertices)
+	}
+	p := &PrimMST{rnd: rand.New(rand.NewSource(seed)),
+		Endpoints: Endpoints{xmin: defaultXmin, xmax: defaultXmax, ymin: defaultYmin, ymax: defaultYmax}}
+	p.randomVertices(n)
+	p.findDistances()
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "queue\tpush\tpop\tdecrease key\tcomparisons\ttime\tweight\t\n")
+	for _, name := range solver.QueueNames() {
+		q := solver.NewCountingQueue(solver.Queues[name](n))
+		start := time.Now()
+		parent, _ := solver.PrimQueue(p.graph, 0, q)
+		elapsed := time.Since(start)
+		var weight float64
+		for v, u := range parent {
+			if u >= 0 {
+				weight += p.graph[u][v]
+			}
+		}
+		c := q.Counts
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%v\t%.4f\t\n", name, c.Push, c.Pop, c.DecreaseKey, q.Comparisons(), elapsed, weight)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	patternPresets = "/presets"     // http handler for saving graph option presets
+	filePresets    = "presets.json" // named presets in the data directory
+)
+
+// presetNamePattern matches the preset names: letters, digits, spaces, and _ . -
+var presetNamePattern = regexp.MustCompile(`^[A-Za-z0-9 _.-]{1,64}$`)
+
+// Preset is a named set of graph options saved on the server
+type Preset struct {
+	Name      string  `json:"name"`
+	Vertices  int     `json:"vertices"`
+	Xmin      float64 `json:"xmin"`
+	Xmax      float64 `json:"xmax"`
+	Ymin      float64 `json:"ymin"`
+	Ymax      float64 `json:"ymax"`
+	Algorithm string  `json:"algorithm"`
+	Metric    string  `json:"metric"`
+	Theme     string  `json:"theme"`
+}
+
+// presetsMu serializes the read-modify-write of the presets file
+var presetsMu sync.Mutex
+
+// loadPresets reads the presets file, a missing file has no presets
+func loadPresets() (map[string]Preset, error) {
+	presets := make(map[string]Preset)
+	b, err := readFileLocked(filepath.Join(dataDir, filePresets))
+	if errors.Is(err, os.ErrNotExist) {
+		return presets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &presets); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// savePreset adds or replaces a preset in the presets file
+func savePreset(preset Preset) error {
+	return updatePresets(func(presets map[string]Preset) {
+		presets[preset.Name] = preset
+	})
+}
+
+// deletePreset removes a preset from the presets file
+func deletePreset(name string) error {
+	return updatePresets(func(presets map[string]Preset) {
+		delete(presets, name)
+	})
+}
+
+// updatePresets reads the presets file, applies update, and writes it back
+func updatePresets(update func(presets map[string]Preset)) error {
+	if readOnly {
+		return errReadOnly
+	}
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	presets, err := loadPresets()
+	if err != nil {
+		return err
+	}
+	update(presets)
+	b, err := json.MarshalIndent(presets, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dataDir, filePresets), func(w io.Writer) error {
+		_, err := w.Write(b)
+		return err
+	})
+}
+
+// presetNames returns the sorted preset names
+func presetNames(presets map[string]Preset) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyPreset overrides the graph options with the preset values
+func (o *OptionsT) applyPreset(preset Preset) {
+	o.Vertices = preset.Vertices
+	o.Xmin = preset.Xmin
+	o.Xmax = preset.Xmax
+	o.Ymin = preset.Ymin
+	o.Ymax = preset.Ymax
+	o.Algorithms = choices(algorithms, preset.Algorithm)
+	o.Metrics = choices(metrics, preset.Metric)
+	o.Themes = choices(themes, preset.Theme)
+}
+
+// HTTP handler for /presets connections saves the posted graph options as a preset
+func handlePresets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Presets are saved with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	preset := Preset{Name: r.FormValue("presetname")}
+	if len(preset.Name) == 0 {
+		http.Error(w, "Enter a preset name", http.StatusBadRequest)
+		return
+	}
+	if !presetNamePattern.MatchString(preset.Name) {
+		http.Error(w, "A preset name has at most 64 letters, digits, spaces, and _ . -", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	vertices := r.FormValue("vertices")
+	if preset.Vertices, err = strconv.Atoi(vertices); err != nil {
+		fmt.Printf("String %s conversion to int error: %v\n", vertices, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, f := range []struct {
+		name  string
+		value *float64
+	}{{"xmin", &preset.Xmin}, {"xmax", &preset.Xmax}, {"ymin", &preset.Ymin}, {"ymax", &preset.Ymax}} {
+		str := r.FormValue(f.name)
+		if *f.value, err = strconv.ParseFloat(str, 64); err != nil {
+			fmt.Printf("String %s conversion to float error: %v\n", str, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, f := range []struct {
+		name   string
+		values []string
+		value  *string
+	}{{"algorithm", algorithms, &preset.Algorithm}, {"metric", metrics, &preset.Metric}, {"theme", themes, &preset.Theme}} {
+		if *f.value, err = formChoice(r, f.name, f.values); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := savePreset(preset); err != nil {
+		fmt.Printf("savePreset error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, patternGraphOptions+"?preset="+url.QueryEscape(preset.Name), http.StatusSeeOther)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+const maxPrizeRounds = 10 // most rounds of re-solving the MST on the kept vertices and pruning
+
+// Prize modes assign each vertex a value, the first collects no prizes
+var prizeModes = []string{"none", "uniform", "random"}
+
+// PrizeCollecting is the tree that maximizes the prizes of its vertices minus its edge costs
+type PrizeCollecting struct {
+	Mode    string    `json:"mode"`    // how the prizes were assigned
+	Value   float64   `json:"value"`   // uniform prize, or the mean of the random prizes
+	Kept    int       `json:"kept"`    // vertices in the tree
+	Skipped int       `json:"skipped"` // vertices left out
+	Prize   float64   `json:"prize"`   // prizes of the kept vertices
+	Cost    float64   `json:"cost"`    // edge costs of the tree
+	Net     float64   `json:"net"`     // prize minus cost
+	FullNet float64   `json:"fullNet"` // all prizes minus the MST weight
+	Rounds  int       `json:"rounds"`  // rounds of re-solving and pruning
+	Prizes  []float64 `json:"prizes"`  // prize of each vertex
+	Edges   []Edge    `json:"-"`       // tree edges
+	skipped []bool    // vertices left out
+}
+
+// String formats the tradeoff for the html template
+func (pc PrizeCollecting) String() string {
+	return fmt.Sprintf("Prize-collecting tree keeps %d of %d vertices: prize %.2f - cost %.2f = net %.2f, "+
+		"spanning all vertices nets %.2f (%d rounds)",
+		pc.Kept, pc.Kept+pc.Skipped, pc.Prize, pc.Cost, pc.Net, pc.FullNet, pc.Rounds)
+}
+
+// formPrizes gets the prize mode and value from the HTML form.  The value defaults to
+// the mean MST edge length once the MST is known.
+func (p *PrimMST) formPrizes(r *http.Request) error {
+	mode, err := formChoice(r, "prizes", prizeModes)
+	if err != nil || mode == prizeModes[0] {
+		return err
+	}
+	value, err := formFloat(r, "prizevalue", 0)
+	if err != nil {
+		return err
+	}
+	if value < 0 {
+		return fmt.Errorf("prize %g is negative", value)
+	}
+	p.prize = &PrizeCollecting{Mode: mode, Value: value}
+	return nil
+}
+
+// prizeCollecting assigns the prizes and finds the prize-collecting tree rooted at the start
+// vertex.  Each round finds the MST of the kept vertices and prunes the subtrees whose prizes
+// do not pay for their edges, which is optimal for that tree, until no vertex is pruned.
+func (p *PrimMST) prizeCollecting() {
+	pc := p.prize
+	n := len(p.location)
+	if pc.Value == 0 && n > 1 {
+		pc.Value = p.totalDistance() / float64(n-1)
+	}
+	pc.Prizes = make([]float64, n)
+	rnd := rand.New(rand.NewSource(p.meta.Seed))
+	for v := range pc.Prizes {
+		if pc.Mode == "random" {
+			pc.Prizes[v] = 2 * pc.Value * rnd.Float64()
+		} else {
+			pc.Prizes[v] = pc.Value
+		}
+	}
+
+	kept := make([]int, n)
+	for v := range kept {
+		kept[v] = v
+	}
+	var edges []Edge
+	for pc.Rounds = 1; pc.Rounds <= maxPrizeRounds; pc.Rounds++ {
+		var next []int
+		edges, next = p.prunedTree(kept)
+		if len(next) == len(kept) {
+			break
+		}
+		kept = next
+	}
+	if pc.Rounds > maxPrizeRounds {
+		pc.Rounds = maxPrizeRounds
+	}
+
+	pc.Edges = edges
+	pc.skipped = make([]bool, n)
+	for v := range pc.skipped {
+		pc.skipped[v] = true
+	}
+	pc.Prize, pc.Cost, pc.FullNet = 0, 0, -p.totalDistance()
+	for _, v := range kept {
+		pc.skipped[v] = false
+		pc.Prize += pc.Prizes[v]
+	}
+	for _, e := range edges {
+		pc.Cost += p.weight(e.v, e.w)
+	}
+	for _, prize := range pc.Prizes {
+		pc.FullNet += prize
+	}
+	pc.Kept, pc.Skipped = len(kept), n-len(kept)
+	pc.Net = pc.Prize - pc.Cost
+}
+
+// prunedTree finds the MST of the kept vertices rooted at the start vertex, and keeps each
+// subtree only if its net prize exceeds the cost of the edge to it.  It returns the edges of
+// the pruned tree and its vertices.
+func (p *PrimMST) prunedTree(kept []int) ([]Edge, []int) {
+	m := len(kept)
+	root := 0
+	graph := make([][]float64, m)
+	for i, v := range kept {
+		if v == p.start {
+			root = i
+		}
+		graph[i] = make([]float64, m)
+		for j, w := range kept {
+			graph[i][j] = p.weight(v, w)
+		}
+	}
+	parent, order := solver.Prim(graph, root)
+
+	// Net prize of each subtree, children before parents
+	net := make([]float64, m)
+	for i := len(order) - 1; i >= 0; i-- {
+		v := order[i]
+		net[v] += p.prize.Prizes[kept[v]]
+		if u := parent[v]; u >= 0 {
+			if gain := net[v] - graph[u][v]; gain > 0 {
+				net[u] += gain
+			}
+		}
+	}
+
+	// Keep the subtrees that pay for their edges, parents before children
+	keep := make([]bool, m)
+	var edges []Edge
+	var next []int
+	for _, v := range order {
+		u := parent[v]
+		if u < 0 {
+			keep[v] = true
+		} else {
+			keep[v] = keep[u] && net[v] > graph[u][v]
+		}
+		if keep[v] {
+			next = append(next, kept[v])
+			if u >= 0 {
+				edges = append(edges, Edge{v: kept[u], w: kept[v]})
+			}
+		}
+	}
+	return edges, next
+}
+
+// drawPrize draws the prize-collecting tree over the MST
+func drawPrize(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for _, e := range p.prize.Edges {
+		a := p.location[e.v]
+		b := p.location[e.w]
+		g.line(real(a), imag(a), real(b), imag(b), "prizeedge")
+	}
+	return nil
+}
+
+// drawSkipped dims the vertices the prize-collecting tree leaves out
+func drawSkipped(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for v, z := range p.location {
+		if p.prize.skipped[v] {
+			g.point(real(z), imag(z), "skippedvertex")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+)
+
+const (
+	earthRadius  = 6378137.0         // WGS 84 semi-major axis in meters
+	earthFlat    = 1 / 298.257223563 // WGS 84 flattening
+	utmScale     = 0.9996            // UTM central meridian scale factor
+	utmEasting   = 500000.0          // UTM false easting in meters
+	utmNorthing  = 10000000.0        // UTM false northing in the southern hemisphere
+	mercatorLat  = 85.051128779806   // Web Mercator latitude limit, which makes the map square
+	utmLatMin    = -80.0             // southern latitude limit of the UTM zones
+	utmLatMax    = 84.0              // northern latitude limit of the UTM zones
+	utmZoneWidth = 6.0               // UTM zone width in degrees of longitude
+)
+
+// Projections of lon,lat vertex lists to planar meters, the first leaves x,y as is
+var projections = []string{"none", "Web Mercator", "UTM"}
+
+// formProjection gets the projection and UTM zone of the pasted vertex list from the HTML form.
+// Zone 0 picks the zone of the mean longitude.
+func (p *PrimMST) formProjection(r *http.Request) error {
+	var err error
+	if p.projection, err = formChoice(r, "projection", projections); err != nil {
+		return err
+	}
+	if p.utmZone, err = formInt(r, "utmzone", 0); err != nil {
+		return err
+	}
+	if p.utmZone < 0 || p.utmZone > 60 {
+		return fmt.Errorf("UTM zone %d is not in the range 1-60, or 0 for automatic", p.utmZone)
+	}
+	return nil
+}
+
+// lonLatBounds returns the longitude and latitude the projection accepts
+func lonLatBounds(projection string) Endpoints {
+	if projection == "UTM" {
+		return Endpoints{xmin: -180, xmax: 180, ymin: utmLatMin, ymax: utmLatMax}
+	}
+	return Endpoints{xmin: -180, xmax: 180, ymin: -mercatorLat, ymax: mercatorLat}
+}
+
+// webMercator projects lon,lat in degrees to Web Mercator meters
+func webMercator(z complex128) complex128 {
+	lon := real(z) * math.Pi / 180
+	lat := imag(z) * math.Pi / 180
+	return complex(earthRadius*lon, earthRadius*math.Log(math.Tan(math.Pi/4+lat/2)))
+}
+
+// utm projects lon,lat in degrees to UTM meters in the zone, with the southern false
+// northing if south is true.  It uses the transverse Mercator series of Snyder (1987),
+// which is accurate to a millimeter within the zone.
+func utm(z complex128, zone int, south bool) complex128 {
+	e2 := earthFlat * (2 - earthFlat)
+	ep2 := e2 / (1 - e2)
+	lon0 := (float64(zone-1)*utmZoneWidth - 180 + utmZoneWidth/2) * math.Pi / 180
+	phi := imag(z) * math.Pi / 180
+	sin, cos, tan := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+
+	n := earthRadius / math.Sqrt(1-e2*sin*sin)
+	t := tan * tan
+	c := ep2 * cos * cos
+	a := cos * (real(z)*math.Pi/180 - lon0)
+	m := earthRadius * ((1-e2/4-3*e2*e2/64-5*e2*e2*e2/256)*phi -
+		(3*e2/8+3*e2*e2/32+45*e2*e2*e2/1024)*math.Sin(2*phi) +
+		(15*e2*e2/256+45*e2*e2*e2/1024)*math.Sin(4*phi) -
+		(35*e2*e2*e2/3072)*math.Sin(6*phi))
+
+	x := utmScale*n*(a+(1-t+c)*math.Pow(a, 3)/6+
+		(5-18*t+t*t+72*c-58*ep2)*math.Pow(a, 5)/120) + utmEasting
+	y := utmScale * (m + n*tan*(a*a/2+(5-t+9*c+4*c*c)*math.Pow(a, 4)/24+
+		(61-58*t+t*t+600*c-330*ep2)*math.Pow(a, 6)/720))
+	if south {
+		y += utmNorthing
+	}
+	return complex(x, y)
+}
+
+// project projects the lon,lat vertices in place and returns the projection description
+// recorded in the metadata
+func (p *PrimMST) project() string {
+	if p.projection == "Web Mercator" {
+		for i, z := range p.location {
+			p.location[i] = webMercator(z)
+		}
+		return "Web Mercator (EPSG:3857)"
+	}
+
+	// The zone and hemisphere are those of the mean location unless the zone is given
+	var lon, lat float64
+	for _, z := range p.location {
+		lon += real(z)
+		lat += imag(z)
+	}
+	lon /= float64(len(p.location))
+	lat /= float64(len(p.location))
+	zone := p.utmZone
+	if zone == 0 {
+		zone = int(math.Floor((lon+180)/utmZoneWidth))%60 + 1
+	}
+	south := lat < 0
+	for i, z := range p.location {
+		p.location[i] = utm(z, zone, south)
+	}
+	if south {
+		return fmt.Sprintf("UTM zone %dS", zone)
+	}
+	return fmt.Sprintf("UTM zone %dN", zone)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+// Proximity graph names, used as overlays and, with Kruskal, as the edge set for the solver
+const (
+	proximityGabriel = "Gabriel"
+	proximityRNG     = "RNG"
+
+	algorithmGabriel = "Kruskal on Gabriel graph"
+	algorithmRNG     = "Kruskal on RNG"
+)
+
+// proximityAlgorithms maps the algorithms that solve on a proximity graph to the graph
+var proximityAlgorithms = map[string]string{algorithmGabriel: proximityGabriel, algorithmRNG: proximityRNG}
+
+// overlays are the proximity graph values of the overlay form field, which selects the
+// proximity graph layer, the first draws none
+var overlays = []string{"none", proximityGabriel, proximityRNG}
+
+// proximityEdges returns the edges of the Euclidean Gabriel graph or relative neighborhood graph
+func (p *PrimMST) proximityEdges(kind string) [][2]int {
+	graph := p.graph
+	if !p.euclidean() {
+		graph = solver.Distances(p.location)
+	}
+	if kind == proximityRNG {
+		return solver.RelativeNeighborhood(graph)
+	}
+	return solver.Gabriel(graph)
+}
+
+// findProximity finds the MST using Kruskal's algorithm on the edges of the proximity graph,
+// which contains the MST of the complete graph
+func (p *PrimMST) findProximity(kind string) error {
+	if !p.euclidean() {
+		if err := p.findMST(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%s graph needs the Euclidean metric, used Prim", kind)
+	}
+	start := time.Now()
+	pairs := p.proximityEdges(kind)
+	p.meta.timePhase("proximity graph", start)
+
+	edges := make([]WeightedEdge, len(pairs))
+	for i, e := range pairs {
+		edges[i] = WeightedEdge{Edge: Edge{v: e[0], w: e[1]}, distance: p.graph[e[0]][e[1]]}
+	}
+	start = time.Now()
+	sortEdges(edges)
+	p.meta.timePhase("sort", start)
+
+	p.countOperations(p.meta.Algorithm, len(edges))
+	p.rootTree(kruskal(len(p.location), edges, p.ops))
+	p.proximity = append(p.proximity, p.proximitySummary(kind, len(pairs)))
+	return nil
+}
+
+// proximitySummary reports the size of the proximity graph
+func (p *PrimMST) proximitySummary(kind string, edges int) string {
+	n := len(p.location)
+	return fmt.Sprintf("%s graph: %d of %d edges, %.2f per vertex", kind, edges, n*(n-1)/2,
+		float64(edges)/float64(n))
+}
+
+// plotOverlay draws the edges of the proximity graph overlay under the MST
+func (p *PrimMST) plotOverlay(g *gridPlot, kind string) {
+	class := "gabrieledge"
+	if kind == proximityRNG {
+		class = "rngedge"
+	}
+	pairs := p.proximityEdges(kind)
+	p.drawPairs(g, pairs, class)
+	if proximityAlgorithms[p.meta.Algorithm] != kind {
+		p.proximity = append(p.proximity, p.proximitySummary(kind, len(pairs)))
+	}
+}
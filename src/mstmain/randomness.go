@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	quadratExpected = 5    // least expected vertices per quadrat
+	maxQuadrats     = 10   // most quadrats per side
+	randomnessAlpha = 0.05 // significance level of the tests
+)
+
+// Randomness is the quadrat count test of complete spatial randomness of the vertices,
+// with a verdict combining it with the Clark-Evans nearest neighbor test
+type Randomness struct {
+	Quadrats  int     `json:"quadrats"`  // quadrats per side of the endpoints
+	ChiSquare float64 `json:"chiSquare"` // index of dispersion of the quadrat counts
+	DF        int     `json:"df"`        // degrees of freedom, quadrats - 1
+	P         float64 `json:"p"`         // two-sided p-value by the Wilson-Hilferty approximation
+	VMR       float64 `json:"vmr"`       // variance to mean ratio of the counts, > 1 clustered, < 1 dispersed
+	Verdict   string  `json:"verdict"`
+}
+
+// String formats the randomness test for the html template
+func (rt Randomness) String() string {
+	return fmt.Sprintf("quadrat test %dx%d, chi-square %.1f (df %d, p %.3f), variance/mean %.2f: %s",
+		rt.Quadrats, rt.Quadrats, rt.ChiSquare, rt.DF, rt.P, rt.VMR, rt.Verdict)
+}
+
+// chiSquareP returns the two-sided p-value of the chi-square statistic x with df degrees of
+// freedom, using the Wilson-Hilferty cube root normal approximation
+func chiSquareP(x float64, df int) float64 {
+	k := float64(df)
+	z := (math.Cbrt(x/k) - (1 - 2/(9*k))) / math.Sqrt(2/(9*k))
+	upper := 0.5 * math.Erfc(z/math.Sqrt2)
+	return math.Min(1, 2*math.Min(upper, 1-upper))
+}
+
+// randomnessTest counts the vertices in a grid of quadrats over the endpoints and tests the
+// counts against the Poisson counts of complete spatial randomness.  It returns nil when
+// there are too few vertices for quadrats with quadratExpected vertices.
+func (p *PrimMST) randomnessTest() *Randomness {
+	n := len(p.location)
+	k := int(math.Sqrt(float64(n) / quadratExpected))
+	if k > maxQuadrats {
+		k = maxQuadrats
+	}
+	if k < 2 || p.xmax <= p.xmin || p.ymax <= p.ymin {
+		return nil
+	}
+	counts := make([]int, k*k)
+	for _, z := range p.location {
+		col := int(float64(k) * (real(z) - p.xmin) / (p.xmax - p.xmin))
+		row := int(float64(k) * (imag(z) - p.ymin) / (p.ymax - p.ymin))
+		// The vertices on the maximum edges, or outside the endpoints, go in the edge quadrats
+		col = int(math.Max(0, math.Min(float64(k-1), float64(col))))
+		row = int(math.Max(0, math.Min(float64(k-1), float64(row))))
+		counts[row*k+col]++
+	}
+
+	// The index of dispersion is chi-square with k²-1 degrees of freedom for Poisson counts
+	mean := float64(n) / float64(k*k)
+	rt := &Randomness{Quadrats: k, DF: k*k - 1}
+	for _, c := range counts {
+		rt.ChiSquare += (float64(c) - mean) * (float64(c) - mean) / mean
+	}
+	rt.P = chiSquareP(rt.ChiSquare, rt.DF)
+	rt.VMR = rt.ChiSquare / float64(rt.DF)
+
+	quadrat := "random"
+	if rt.P < randomnessAlpha {
+		quadrat = "clustered"
+		if rt.VMR < 1 {
+			quadrat = "dispersed"
+		}
+	}
+	rt.Verdict = quadrat
+	if p.nearest != nil {
+		ce := "random"
+		switch {
+		case p.nearest.Z < -1.96:
+			ce = "clustered"
+		case p.nearest.Z > 1.96:
+			ce = "dispersed"
+		}
+		if ce != quadrat {
+			rt.Verdict = fmt.Sprintf("mixed, %s by the quadrat test and %s by Clark-Evans", quadrat, ce)
+			return rt
+		}
+	}
+	if rt.Verdict == "random" {
+		rt.Verdict = "consistent with complete spatial randomness"
+	}
+	return rt
+}
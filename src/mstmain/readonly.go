@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// readOnly is the public demo mode set by -read-only: the graphs are only kept in memory,
+// nothing is written to the data directory, and file uploads are rejected
+var readOnly bool
+
+var errReadOnly = errors.New("this is a read-only demo, nothing is saved")
+
+// uploadedFile returns true if the multipart form body has a non-empty file part.  It reads
+// the parts from memory, so checking an upload does not spill it to a temporary file.
+func uploadedFile(body []byte, boundary string) (bool, error) {
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if len(part.FileName()) > 0 {
+			if n, _ := io.Copy(io.Discard, io.LimitReader(part, 1)); n > 0 {
+				return true, nil
+			}
+		}
+	}
+}
+
+// rejectUploads rejects the requests that upload a file, and limits the request bodies
+// to maxUpload.  An empty file input of a form is not an upload.
+func rejectUploads(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/form-data" {
+			r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+			h.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxUpload))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("the request is larger than the %d byte limit of the read-only demo", maxUpload),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		upload, err := uploadedFile(body, params["boundary"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if upload {
+			http.Error(w, "File uploads are disabled in the read-only demo", http.StatusForbidden)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.ServeHTTP(w, r)
+	})
+}
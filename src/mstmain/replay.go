@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const patternReplay = "/replay" // http handler to replay a stored run and check its weight
+
+// runParams are the form parameters of the run worth recording, without the empty values
+// and the read-only status
+func runParams(form url.Values) url.Values {
+	params := url.Values{}
+	for name, values := range form {
+		if name == "status" || len(values) == 0 || len(values[0]) == 0 {
+			continue
+		}
+		params[name] = append([]string(nil), values...)
+	}
+	return params
+}
+
+// replayRequest creates the MST request that regenerates the run from its seed and parameters.
+// A run of an earlier graph, with a new start vertex, edit, or perturbation, gets the recorded
+// vertices as a pasted vertex list since the earlier graph may be gone.
+func replayRequest(run *Run) (*http.Request, error) {
+	if len(run.Params) == 0 {
+		return nil, fmt.Errorf("run %s has no recorded parameters to replay", run.ID)
+	}
+	params := url.Values{}
+	for name, values := range run.Params {
+		params[name] = append([]string(nil), values...)
+	}
+	params.Set("seed", fmt.Sprint(run.Metadata.Seed))
+	if len(params.Get("graph")) > 0 {
+		for _, name := range []string{"graph", "newstartvert", "edit", "editop", "projection", "autofit"} {
+			params.Del(name)
+		}
+		lines := make([]string, len(run.Vertices))
+		for i, v := range run.Vertices {
+			lines[i] = fmt.Sprintf("%v, %v", v.X, v.Y)
+			if len(v.Name) > 0 {
+				lines[i] += ", " + v.Name
+			}
+		}
+		params.Set("vertexlist", strings.Join(lines, "\n"))
+		params.Set("xmin", fmt.Sprint(run.Xmin))
+		params.Set("xmax", fmt.Sprint(run.Xmax))
+		params.Set("ymin", fmt.Sprint(run.Ymin))
+		params.Set("ymax", fmt.Sprint(run.Ymax))
+	}
+	return formRequest(params)
+}
+
+// formRequest creates a POST of the form parameters to /primmst, for createMST
+func formRequest(params url.Values) (*http.Request, error) {
+	r, err := http.NewRequest(http.MethodPost, patternPrimMST, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r, nil
+}
+
+// HTTP handler for /replay connections.  It regenerates the stored run from its seed and
+// parameters, finds the MST again, and shows it with whether the weight matches the record.
+// The X-Replay header is match or mismatch.
+func handleReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Replay a run with a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	run, err := loadRun(r.FormValue("id"))
+	if err != nil {
+		fmt.Printf("loadRun error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	replay, err := replayRequest(run)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	editSession(w, r)
+	p, status := createMST(replay.WithContext(r.Context()))
+	if len(p.location) == 0 {
+		http.Error(w, strings.Join(status, ", "), http.StatusBadRequest)
+		return
+	}
+	setLatestMST(p)
+
+	// The replayed weight must match the recorded weight up to rounding
+	distance := p.totalDistance()
+	if math.Abs(distance-run.Distance) <= 1e-9*math.Max(1, math.Abs(run.Distance)) {
+		w.Header().Set("X-Replay", "match")
+		status = append(status, fmt.Sprintf("Replay of run %s matches the recorded weight %s",
+			run.ID, p.format.format(run.Distance)))
+	} else {
+		w.Header().Set("X-Replay", "mismatch")
+		status = append(status, fmt.Sprintf("Replay of run %s does not match: recorded weight %s, replayed %s",
+			run.ID, p.format.format(run.Distance), p.format.format(distance)))
+	}
+	p.snapshots = snapshots(r)
+	if err := p.plotMST(newStreamWriter(w, r), status); err != nil {
+		fmt.Printf("plotMST error: %v\n", err)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	minResolution        = 10  // fewest rows or columns of an auto-sized grid
+	defaultMaxResolution = 600 // default most rows or columns of an auto-sized grid, the -max-grid flag
+	defaultMinResolution = 60  // default fewest rows or columns of an auto-sized grid
+	cellsPerVertex       = 16  // cells along the longer axis per square root of the vertex count
+	gridTicks            = 10  // intervals between the axis ticks
+)
+
+// Resolution is the grid size range of the auto-sized plot
+type Resolution struct {
+	Auto bool // choose the grid size from the vertices and bounds
+	Min  int  // fewest rows or columns
+	Max  int  // most rows or columns
+}
+
+// formResolution gets the auto-size checkbox and grid size range from the HTML form
+func (p *PrimMST) formResolution(r *http.Request) error {
+	p.resolution.Auto = len(r.FormValue("autoresolution")) > 0
+	var err error
+	if p.resolution.Min, err = formInt(r, "minresolution", defaultMinResolution); err != nil {
+		return err
+	}
+	if p.resolution.Max, err = formInt(r, "maxresolution", maxResolution); err != nil {
+		return err
+	}
+	if p.resolution.Min < minResolution || p.resolution.Max > maxResolution || p.resolution.Min > p.resolution.Max {
+		p.resolution = Resolution{Min: defaultMinResolution, Max: maxResolution}
+		if p.resolution.Min > maxResolution {
+			p.resolution.Min = maxResolution
+		}
+		return fmt.Errorf("grid size range is not within %d-%d, using %d x %d", minResolution, maxResolution, rows, columns)
+	}
+	return nil
+}
+
+// optimalResolution returns the rows and columns of the grid for n vertices in the endpoints.
+// The longer axis gets cellsPerVertex cells per square root of n, so the mean spacing of
+// the vertices is about the same number of cells at any n, and the shorter axis gets the
+// same cells per unit.  Both are kept within lo-hi.
+func optimalResolution(n int, ep Endpoints, lo, hi int) (int, int) {
+	clamp := func(cells float64) int {
+		return int(math.Max(float64(lo), math.Min(float64(hi), math.Round(cells))))
+	}
+	long := clamp(cellsPerVertex * math.Sqrt(float64(n)))
+	ratio := (ep.xmax - ep.xmin) / (ep.ymax - ep.ymin)
+	if ratio >= 1 {
+		return clamp(float64(long) / ratio), long
+	}
+	return long, clamp(float64(long) * ratio)
+}
+
+// gridSize returns the rows and columns of the MST plot, auto-sized or the default
+func (p *PrimMST) gridSize() (int, int) {
+	if !p.resolution.Auto {
+		return rows, columns
+	}
+	return optimalResolution(len(p.location), p.plotEndpoints(), p.resolution.Min, p.resolution.Max)
+}
+
+// style returns the CSS that sizes the grid cells to fill the plot and draws the axis ticks
+func (g *gridPlot) style() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "div.grid.sized { grid-template-columns: repeat(%d, 1fr); grid-template-rows: repeat(%d, 1fr); }\n",
+		g.columns, g.rows)
+	ticks := func(cell func(k int) int) string {
+		s := make([]string, 0, gridTicks-1)
+		for k := 1; k < gridTicks; k++ {
+			s = append(s, "div.grid.sized > div:nth-child("+strconv.Itoa(cell(k))+")")
+		}
+		return strings.Join(s, ", ")
+	}
+	sb.WriteString(ticks(func(k int) int { return k*g.rows/gridTicks*g.columns + 1 }))
+	sb.WriteString(" { border-bottom: 2px solid black; }\n")
+	sb.WriteString(ticks(func(k int) int { return (g.rows-1)*g.columns + k*g.columns/gridTicks }))
+	sb.WriteString(" { border-left: 2px solid black; }\n")
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultResponseCache = 32 // default number of MSTs kept in memory
+
+// cachedMST is the MST of a run with a seed and the status of its computation.  Only the
+// deterministic part of a run is cached: the run ID, recent runs, snapshots, and timings
+// are rendered for each request.
+type cachedMST struct {
+	key    string
+	p      *PrimMST
+	status []string
+}
+
+// responseCache is a least recently used cache of the MSTs of runs with a seed, so a request
+// repeating the parameters and seed of a recent run is not computed again
+type responseCache struct {
+	mu       sync.Mutex
+	capacity int
+	lru      *list.List               // most recently used at the front
+	entries  map[string]*list.Element // values are *cachedMST
+}
+
+// responses is the cache of the MSTs of runs with a seed, sized by -response-cache
+var responses = newResponseCache(defaultResponseCache)
+
+func newResponseCache(capacity int) *responseCache {
+	return &responseCache{capacity: capacity, lru: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the MST and marks it most recently used
+func (c *responseCache) get(key string) (*cachedMST, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*cachedMST), true
+}
+
+// put adds the MST and evicts the least recently used beyond the capacity
+func (c *responseCache) put(cr *cachedMST) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[cr.key]; ok {
+		el.Value = cr
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[cr.key] = c.lru.PushFront(cr)
+	for c.lru.Len() > c.capacity {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*cachedMST).key)
+	}
+}
+
+// responseKey returns the cache key of the request, a hash of the path and the parameters
+// recorded in its run, and whether the response can be cached.  Only runs with a seed are
+// repeatable, and runs of an earlier graph, with uploaded files, or with the session's
+// pinned snapshots depend on more than their parameters.
+func responseKey(r *http.Request) (string, bool) {
+	if responses.capacity <= 0 || len(r.FormValue("seed")) == 0 || len(r.FormValue("graph")) > 0 {
+		return "", false
+	}
+	if r.MultipartForm != nil && len(r.MultipartForm.File) > 0 {
+		return "", false
+	}
+	if len(snapshots(r)) > 0 {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(r.URL.Path + "\n" + runParams(r.Form).Encode() + "\n"))
+	if locale := r.FormValue("locale"); len(locale) == 0 || locale == localeAuto {
+		h.Write([]byte(acceptLocale(r.Header.Get("Accept-Language")).name))
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// cachedCreateMST returns a copy of the cached MST of a repeated run with a seed, or creates
+// the MST and caches a copy when it is complete, so the caller's changes for its request do
+// not reach the cache.  The X-Cache header says which.
+func cachedCreateMST(w http.ResponseWriter, r *http.Request) (*PrimMST, []string) {
+	key, cacheable := responseKey(r)
+	if !cacheable {
+		return createMST(r)
+	}
+	start := time.Now()
+	if cm, ok := responses.get(key); ok {
+		w.Header().Set("X-Cache", "hit")
+		p := *cm.p
+		p.meta.Timings = nil
+		p.meta.timePhase("cache", start)
+		return &p, append([]string{}, cm.status...)
+	}
+	w.Header().Set("X-Cache", "miss")
+	p, status := createMST(r)
+	if len(p.location) > 0 && len(p.meta.Partial) == 0 {
+		c := *p
+		responses.put(&cachedMST{key: key, p: &c, status: append([]string{}, status...)})
+	}
+	return p, status
+}
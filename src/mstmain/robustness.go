@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+const (
+	patternRobustness = "/primmstrobustness"        // http handler for the vertex-removal analysis
+	fileRobustness    = "templates/robustness.html" // html for the vertex-removal analysis
+	mostDisruptive    = 10                          // vertices listed whose removal most increases the weight
+)
+
+// Removal is the MST weight change when a vertex is removed
+type Removal struct {
+	Vertex   int     // removed vertex index
+	Name     string  // removed vertex name
+	Location string  // removed vertex location
+	Degree   int     // MST degree of the removed vertex
+	Weight   float64 // MST weight without the vertex
+	Change   float64 // weight change from the full MST
+}
+
+// RobustnessT contains the vertex-removal analysis HTML template actions
+type RobustnessT struct {
+	Weight   float64   // full MST weight
+	Removals []Removal // vertices by decreasing weight change
+	Min      float64   // smallest weight change
+	Median   float64   // median weight change
+	Mean     float64   // mean weight change
+	Max      float64   // largest weight change
+	Status   string    // status of the analysis
+	Theme    string    // page theme
+}
+
+// removeVertex returns the MST weight of the graph without vertex v
+func (p *PrimMST) removeVertex(v int) float64 {
+	n := len(p.location)
+	graph := make([][]float64, 0, n-1)
+	for i := 0; i < n; i++ {
+		if i == v {
+			continue
+		}
+		row := make([]float64, 0, n-1)
+		row = append(row, p.graph[i][:v]...)
+		row = append(row, p.graph[i][v+1:]...)
+		graph = append(graph, row)
+	}
+	parent, _ := solver.Prim(graph, 0)
+	var weight float64
+	for w, u := range parent {
+		if u >= 0 {
+			weight += graph[u][w]
+		}
+	}
+	return weight
+}
+
+// robustness removes each of the vertices in turn and reports the MST weight changes
+func (p *PrimMST) robustness(vertices []int) RobustnessT {
+	rt := RobustnessT{Weight: p.totalDistance(), Theme: p.theme}
+	degree := make([]int, len(p.location))
+	for _, e := range p.mst {
+		if e != nil {
+			degree[e.v]++
+			degree[e.w]++
+		}
+	}
+	for _, v := range vertices {
+		weight := p.removeVertex(v)
+		rt.Removals = append(rt.Removals, Removal{
+			Vertex:   v,
+			Name:     p.name(v),
+			Location: fmt.Sprintf("(%.2f, %.2f)", real(p.location[v]), imag(p.location[v])),
+			Degree:   degree[v],
+			Weight:   weight,
+			Change:   weight - rt.Weight,
+		})
+	}
+	sort.Slice(rt.Removals, func(i, j int) bool { return rt.Removals[i].Change > rt.Removals[j].Change })
+
+	// Distribution of the weight changes
+	n := len(rt.Removals)
+	rt.Max = rt.Removals[0].Change
+	rt.Min = rt.Removals[n-1].Change
+	rt.Median = rt.Removals[n/2].Change
+	if n%2 == 0 {
+		rt.Median = (rt.Removals[n/2-1].Change + rt.Removals[n/2].Change) / 2
+	}
+	for _, r := range rt.Removals {
+		rt.Mean += r.Change / float64(n)
+	}
+	return rt
+}
+
+// HTTP handler for /primmstrobustness connections, the optional vertex query parameter
+// removes only that vertex instead of each vertex in turn
+func handleRobustness(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	p = p.withMatrix()
+	if len(p.location) <= minVertices {
+		http.Error(w, "The MST has too few vertices to remove one", http.StatusBadRequest)
+		return
+	}
+
+	var vertices []int
+	if str := r.FormValue("vertex"); len(str) > 0 {
+		v, err := strconv.Atoi(str)
+		if err != nil || v < 0 || v >= len(p.location) {
+			http.Error(w, fmt.Sprintf("vertex %s is not in the range 0-%d", str, len(p.location)-1),
+				http.StatusBadRequest)
+			return
+		}
+		vertices = append(vertices, v)
+	} else {
+		for v := range p.location {
+			vertices = append(vertices, v)
+		}
+	}
+
+	rt := p.robustness(vertices)
+	rt.Status = fmt.Sprintf("Removed %d of %d vertices", len(vertices), len(p.location))
+	if len(vertices) > mostDisruptive {
+		rt.Status += fmt.Sprintf(", the %d most disruptive are listed", mostDisruptive)
+		rt.Removals = rt.Removals[:mostDisruptive]
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmplRobustness.Execute(w, rt); err != nil {
+		fmt.Printf("Write to HTTP output using template with robustness error: %v\n", err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dirRuns  = "runs" // stored runs in the data directory, one JSON file per run
+	listRuns = 20     // most recent runs offered in the results page
+)
+
+// runIDPattern matches the run IDs, which become file names
+var runIDPattern = regexp.MustCompile(`^[0-9a-z]+$`)
+
+// Run is a stored MST run, the JSON API response with an ID
+type Run struct {
+	ID      string     `json:"id"`
+	Created time.Time  `json:"created"`
+	Params  url.Values `json:"params,omitempty"` // form parameters, for replaying the run
+	*ResponseJSON
+}
+
+// String describes the run in the run lists
+func (run *Run) String() string {
+	return fmt.Sprintf("%s %s %s %d vertices, distance %.2f", run.ID, run.Metadata.Algorithm,
+		run.Metadata.Metric, len(run.Vertices), run.Distance)
+}
+
+// runPath returns the file of the run ID in the data directory
+func runPath(id string) (string, error) {
+	if !runIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid run ID %s", id)
+	}
+	return filepath.Join(dataDir, dirRuns, id+".json"), nil
+}
+
+// saveRun stores the MST with the form parameters of its request and returns the run ID,
+// empty in the read-only demo
+func (p *PrimMST) saveRun(status []string, form url.Values) (string, error) {
+	if readOnly {
+		return "", nil
+	}
+	now := time.Now()
+	run := &Run{
+		ID:           strconv.FormatInt(now.UnixNano(), 36),
+		Created:      now,
+		Params:       runParams(form),
+		ResponseJSON: p.response(status),
+	}
+	path, err := runPath(run.ID)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(run)
+	})
+	return run.ID, err
+}
+
+// loadRun reads the stored run with the ID
+func loadRun(id string) (*Run, error) {
+	path, err := runPath(id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readFileLocked(path)
+	if err != nil {
+		return nil, err
+	}
+	run := &Run{}
+	if err := json.Unmarshal(b, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// recentRuns returns the IDs of the n most recent stored runs, newest first
+func recentRuns(n int) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dataDir, dirRuns))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if id := strings.TrimSuffix(e.Name(), ".json"); runIDPattern.MatchString(id) && !e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	// The base 36 timestamps sort by length, then lexically
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) > len(ids[j])
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > n {
+		ids = ids[:n]
+	}
+	return ids, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	patternScaling = "/scaling"               // http handler for the scaling study
+	fileScaling    = "templates/scaling.html" // html for the scaling study
+	defaultSteps   = 10                       // default number of vertex counts in the sweep
+	maxSteps       = 100                      // most vertex counts in the sweep
+)
+
+// ScalingPoint is the MST weight and runtime for one vertex count in the sweep
+type ScalingPoint struct {
+	Vertices int           // number of vertices
+	Weight   float64       // MST total distance
+	Runtime  time.Duration // time to find the distances and the MST
+}
+
+// Type to contain all the scaling study HTML template actions
+type ScalingT struct {
+	Grid       []string       // plotting grid
+	Xlabel     []string       // x-axis labels, number of vertices
+	Ylabel     []string       // y-axis labels, MST weight
+	Points     []ScalingPoint // sweep results
+	MaxRuntime time.Duration  // runtime at the top of the plot
+	Status     string         // status of the study
+	Seed       int64          // random number generator seed
+	Algorithm  string         // MST algorithm
+	Theme      string         // page theme
+}
+
+// formInt gets an integer from the HTML form, or def if the form value is empty
+func formInt(r *http.Request, name string, def int) (int, error) {
+	str := r.FormValue(name)
+	if len(str) == 0 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil {
+		fmt.Printf("String %s conversion to int error: %v\n", str, err)
+		return def, err
+	}
+	return n, nil
+}
+
+// formFloat gets a float from the HTML form, or def if the form value is empty
+func formFloat(r *http.Request, name string, def float64) (float64, error) {
+	str := r.FormValue(name)
+	if len(str) == 0 {
+		return def, nil
+	}
+	x, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		fmt.Printf("String %s conversion to float error: %v\n", str, err)
+		return def, err
+	}
+	return x, nil
+}
+
+// scalingStudy finds the MST for each vertex count in the sweep
+func (p *PrimMST) scalingStudy(minN, maxN, steps int) []ScalingPoint {
+	points := make([]ScalingPoint, 0, steps)
+	for i := 0; i < steps; i++ {
+		n := minN
+		if steps > 1 {
+			n = minN + i*(maxN-minN)/(steps-1)
+		}
+		p.randomVertices(n)
+		start := time.Now()
+		p.findDistances()
+		p.solve()
+		points = append(points, ScalingPoint{Vertices: n, Weight: p.totalDistance(), Runtime: time.Since(start)})
+	}
+	return points
+}
+
+// plotScaling draws MST weight versus number of vertices as vertices connected by edges,
+// and the runtime scaled so that the maximum runtime is at the top of the plot
+func plotScaling(points []ScalingPoint, plot *ScalingT, f labelFormat) {
+	maxWeight := 0.0
+	for _, pt := range points {
+		if pt.Weight > maxWeight {
+			maxWeight = pt.Weight
+		}
+		if pt.Runtime > plot.MaxRuntime {
+			plot.MaxRuntime = pt.Runtime
+		}
+	}
+	xmax := float64(points[len(points)-1].Vertices)
+	if xmax <= float64(points[0].Vertices) {
+		xmax = float64(points[0].Vertices) + 1
+	}
+	ep := Endpoints{xmin: float64(points[0].Vertices), xmax: xmax, ymin: 0, ymax: 1.05 * maxWeight}
+	g := newGridPlot(ep)
+	runtimeY := func(d time.Duration) float64 {
+		return ep.ymax * float64(d) / float64(plot.MaxRuntime+1)
+	}
+
+	for i := 1; i < len(points); i++ {
+		prev, pt := points[i-1], points[i]
+		g.line(float64(prev.Vertices), prev.Weight, float64(pt.Vertices), pt.Weight, "edge")
+		g.line(float64(prev.Vertices), runtimeY(prev.Runtime), float64(pt.Vertices), runtimeY(pt.Runtime), "runtimeedge")
+	}
+	for _, pt := range points {
+		g.point(float64(pt.Vertices), pt.Weight, "vertex")
+		g.point(float64(pt.Vertices), runtimeY(pt.Runtime), "runtime")
+	}
+
+	plot.Grid = g.grid
+	plot.Xlabel, plot.Ylabel = g.labels(f)
+}
+
+// HTTP handler for /scaling connections
+func handleScaling(w http.ResponseWriter, r *http.Request) {
+	p := &PrimMST{}
+	plot := ScalingT{}
+	status := make([]string, 0)
+
+	if err := checkBudget(r); err != nil {
+		writeBudgetError(w, r, err)
+		return
+	}
+	var err error
+	if p.Endpoints, err = formEndpoints(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	plot.Theme, _ = formChoice(r, "theme", themes)
+	if p.meta.Algorithm, err = formChoice(r, "algorithm", algorithms); err != nil {
+		status = append(status, err.Error())
+	}
+	plot.Algorithm = p.meta.Algorithm
+
+	// Sweep range, defaults to the full range of vertices
+	minN, err := formInt(r, "sweepmin", minVertices)
+	if err != nil {
+		status = append(status, err.Error())
+	}
+	maxN, err := formInt(r, "sweepmax", maxVertices)
+	if err != nil {
+		status = append(status, err.Error())
+	}
+	steps, err := formInt(r, "sweepsteps", defaultSteps)
+	if err != nil {
+		status = append(status, err.Error())
+	}
+	if minN > maxN {
+		minN, maxN = maxN, minN
+	}
+	if minN < minVertices || maxN > maxVertices {
+		http.Error(w, fmt.Sprintf("sweep %d-%d is not in the range %d-%d", minN, maxN, minVertices, maxVertices),
+			http.StatusBadRequest)
+		return
+	}
+	if steps < 2 {
+		steps = 2
+	}
+	if steps > maxSteps {
+		http.Error(w, fmt.Sprintf("sweep steps %d is more than %d", steps, maxSteps), http.StatusBadRequest)
+		return
+	}
+
+	plot.Seed = newSeed()
+	if seed := r.FormValue("seed"); len(seed) > 0 {
+		if plot.Seed, err = strconv.ParseInt(seed, 10, 64); err != nil {
+			status = append(status, err.Error())
+		}
+	}
+	p.rnd = rand.New(rand.NewSource(plot.Seed))
+
+	format, err := formLabelFormat(r)
+	if err != nil {
+		status = append(status, err.Error())
+	}
+
+	plot.Points = p.scalingStudy(minN, maxN, steps)
+	plotScaling(plot.Points, &plot, format)
+	if len(status) > 0 {
+		plot.Status = strings.Join(status, ", ")
+	} else {
+		plot.Status = format.lang.message(msgScaling)
+	}
+
+	sw := newStreamWriter(w, r)
+	if err := tmplScaling.ExecuteTemplate(sw, "gridstart", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(plot.Grid, columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplScaling.ExecuteTemplate(sw, "gridend", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// lastSeed is the latest seed from newSeed
+var lastSeed int64
+
+// newSeed returns a seed for a request's random number generator from the clock.  Each
+// request creates its own *rand.Rand from its seed, never the global math/rand source, so
+// seeded runs repeat exactly while other requests run.  The seeds are strictly increasing,
+// so concurrent requests within the clock resolution still get different vertices.
+func newSeed() int64 {
+	for {
+		last := atomic.LoadInt64(&lastSeed)
+		seed := time.Now().UnixNano()
+		if seed <= last {
+			seed = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&lastSeed, last, seed) {
+			return seed
+		}
+	}
+}
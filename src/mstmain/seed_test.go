@@ -0,0 +1,43 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewSeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		goroutines int
+		seeds      int // seeds per goroutine
+	}{
+		{"sequential", 1, 1000},
+		{"concurrent", 8, 500},
+	}
+	for _, tt := range tests {
+		var mu sync.Mutex
+		seen := make(map[int64]bool)
+		var wg sync.WaitGroup
+		for g := 0; g < tt.goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				last := int64(-1 << 63)
+				for i := 0; i < tt.seeds; i++ {
+					seed := newSeed()
+					if seed <= last {
+						t.Errorf("%s: seed %d after %d is not increasing", tt.name, seed, last)
+					}
+					last = seed
+					mu.Lock()
+					if seen[seed] {
+						t.Errorf("%s: seed %d was returned twice", tt.name, seed)
+					}
+					seen[seed] = true
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
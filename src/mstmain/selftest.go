@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+const selfTestTrials = 200 // random graphs of the -selftest check
+
+// selfTest checks the priority queues and Prim's algorithm in the solver package, then each
+// MST algorithm against the brute-force MST of small random Euclidean graphs.  The approximate
+// algorithm samples every edge so it must be exact.
+func selfTest(w io.Writer, trials int, seed int64) error {
+	start := time.Now()
+	rnd := rand.New(rand.NewSource(seed))
+	if err := solver.SelfTest(rnd, trials); err != nil {
+		return fmt.Errorf("selftest seed %d: %v", seed, err)
+	}
+	for trial := 0; trial < trials; trial++ {
+		n := minVertices + rnd.Intn(solver.MaxBruteForce-minVertices+1)
+		for _, algorithm := range algorithms {
+			p := &PrimMST{rnd: rand.New(rand.NewSource(rnd.Int63())), neighbors: n, randomEdges: n,
+				Endpoints: Endpoints{xmin: defaultXmin, xmax: defaultXmax, ymin: defaultYmin, ymax: defaultYmax}}
+			p.meta.Algorithm = algorithm
+			p.randomVertices(n)
+			p.start = p.rnd.Intn(n)
+			if err := p.findDistances(); err != nil {
+				return err
+			}
+			if err := p.solve(); err != nil {
+				return fmt.Errorf("selftest seed %d: %s: %v", seed, algorithm, err)
+			}
+			var edges [][2]int
+			for _, e := range p.mst {
+				if e != nil {
+					edges = append(edges, [2]int{e.v, e.w})
+				}
+			}
+			if err := solver.CheckEdges(p.graph, edges); err != nil {
+				return fmt.Errorf("selftest seed %d: %s on %d vertices: %v", seed, algorithm, n, err)
+			}
+		}
+	}
+	fmt.Fprintf(w, "Selftest passed: priority queues %v and algorithms %v on %d random graphs in %v\n",
+		solver.QueueNames(), algorithms, trials, time.Since(start).Round(time.Millisecond))
+	return nil
+}
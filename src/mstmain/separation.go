@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math/cmplx"
+	"net/http"
+)
+
+// Policies for vertices closer than the minimum separation, the first is the default
+const (
+	policyKeep     = "keep"     // keep the vertices and only report them
+	policyMerge    = "merge"    // merge the vertex into the earlier vertex it is too close to
+	policyResample = "resample" // draw a new random location for the vertex
+	resampleTries  = 100        // random locations tried before a vertex is merged instead
+)
+
+var separationPolicies = []string{policyKeep, policyMerge, policyResample}
+
+// Separation reports the duplicate or near-coincident vertices and what was done with them
+type Separation struct {
+	MinSeparation float64 `json:"minSeparation"` // vertices closer than this are too close, 0 finds duplicates
+	Policy        string  `json:"policy"`        // keep, merge, or resample
+	Affected      int     `json:"affected"`      // vertices too close to an earlier vertex
+	Merged        int     `json:"merged"`        // vertices removed by merging
+	Resampled     int     `json:"resampled"`     // vertices moved to a new random location
+}
+
+func (s Separation) String() string {
+	close := "duplicate vertices"
+	if s.MinSeparation > 0 {
+		close = fmt.Sprintf("vertices closer than %g", s.MinSeparation)
+	}
+	return fmt.Sprintf("%d %s (policy %s): %d merged, %d resampled",
+		s.Affected, close, s.Policy, s.Merged, s.Resampled)
+}
+
+// formSeparation gets the minimum separation and the policy from the HTML form
+func (p *PrimMST) formSeparation(r *http.Request) error {
+	var err error
+	if p.separation.MinSeparation, err = formFloat(r, "minseparation", 0); err != nil {
+		return err
+	}
+	if p.separation.MinSeparation < 0 {
+		return fmt.Errorf("minimum separation %g is negative", p.separation.MinSeparation)
+	}
+	p.separation.Policy, err = formChoice(r, "duplicates", separationPolicies)
+	return err
+}
+
+// tooClose returns true if z is within the minimum separation of one of the vertices
+func (s *Separation) tooClose(z complex128, vertices []complex128) bool {
+	for _, v := range vertices {
+		if d := cmplx.Abs(z - v); d == 0 || d < s.MinSeparation {
+			return true
+		}
+	}
+	return false
+}
+
+// separate detects the vertices that duplicate or are too close to an earlier vertex
+// and merges or re-samples them according to the policy
+func (p *PrimMST) separate() {
+	s := &p.separation
+	s.Affected, s.Merged, s.Resampled = 0, 0, 0
+	kept := make([]complex128, 0, len(p.location))
+	var names []string
+	keep := func(z complex128, v int) {
+		kept = append(kept, z)
+		if p.names != nil {
+			names = append(names, p.names[v])
+		}
+	}
+	for v, z := range p.location {
+		if !s.tooClose(z, kept) {
+			keep(z, v)
+			continue
+		}
+		s.Affected++
+		switch s.Policy {
+		case policyMerge:
+			s.Merged++
+		case policyResample:
+			resampled := false
+			for i := 0; i < resampleTries && !resampled; i++ {
+				z = complex(p.xmin+(p.xmax-p.xmin)*p.rnd.Float64(), p.ymin+(p.ymax-p.ymin)*p.rnd.Float64())
+				resampled = !s.tooClose(z, kept)
+			}
+			if resampled {
+				s.Resampled++
+				keep(z, v)
+			} else {
+				s.Merged++
+			}
+		default:
+			keep(z, v)
+		}
+	}
+	p.location = kept
+	if p.names != nil {
+		p.names = names
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import "time"
+
+// dropLeastRecent deletes the least recently used sessions until at most limit are left.
+// The edit histories, stepping sessions, and snapshot strips are each kept by session with
+// their own limit.
+func dropLeastRecent[S any](sessions map[string]S, limit int, used func(S) time.Time) {
+	for len(sessions) > limit {
+		oldest := ""
+		for id, s := range sessions {
+			if len(oldest) == 0 || used(s).Before(used(sessions[oldest])) {
+				oldest = id
+			}
+		}
+		delete(sessions, oldest)
+	}
+}
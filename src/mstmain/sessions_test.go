@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDropLeastRecent(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		used  map[string]int // seconds before now
+		limit int
+		want  []string
+	}{
+		{"under the limit", map[string]int{"a": 1, "b": 2}, 3, []string{"a", "b"}},
+		{"at the limit", map[string]int{"a": 1, "b": 2}, 2, []string{"a", "b"}},
+		{"one over", map[string]int{"a": 1, "b": 3, "c": 2}, 2, []string{"a", "c"}},
+		{"several over", map[string]int{"a": 4, "b": 1, "c": 3, "d": 2}, 1, []string{"b"}},
+		{"none kept", map[string]int{"a": 1}, 0, nil},
+	}
+	for _, tt := range tests {
+		sessions := make(map[string]time.Time)
+		for id, s := range tt.used {
+			sessions[id] = now.Add(-time.Duration(s) * time.Second)
+		}
+		dropLeastRecent(sessions, tt.limit, func(used time.Time) time.Time { return used })
+		if len(sessions) != len(tt.want) {
+			t.Errorf("%s: %d sessions left, want %v", tt.name, len(sessions), tt.want)
+		}
+		for _, id := range tt.want {
+			if _, ok := sessions[id]; !ok {
+				t.Errorf("%s: session %s was dropped, want %v", tt.name, id, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+)
+
+// formSnap gets the snap-to-grid spacing from the HTML form, 0 does not snap
+func (p *PrimMST) formSnap(r *http.Request) error {
+	var err error
+	if p.snap, err = formFloat(r, "snap", 0); err != nil {
+		return err
+	}
+	if p.snap < 0 {
+		return fmt.Errorf("snap-to-grid spacing %g is negative", p.snap)
+	}
+	return nil
+}
+
+// snapCoordinate rounds x to the nearest multiple of spacing within min and max
+func snapCoordinate(x, spacing, min, max float64) float64 {
+	s := math.Round(x/spacing) * spacing
+	if s < min {
+		s = math.Ceil(min/spacing) * spacing
+	}
+	if s > max {
+		s = math.Floor(max/spacing) * spacing
+	}
+	// No multiple of spacing is within the bounds
+	if s < min || s > max {
+		return x
+	}
+	return s
+}
+
+// snapToGrid rounds the vertex coordinates to the snap-to-grid spacing
+func (p *PrimMST) snapToGrid() {
+	if p.snap == 0 {
+		return
+	}
+	for i, z := range p.location {
+		p.location[i] = complex(snapCoordinate(real(z), p.snap, p.xmin, p.xmax),
+			snapCoordinate(imag(z), p.snap, p.ymin, p.ymax))
+	}
+}
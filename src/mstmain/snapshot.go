@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	patternPin          = "/primmstpin" // http handler to pin the last MST as a snapshot
+	maxSnapshots        = 8             // snapshots kept per session, the oldest is dropped
+	maxSnapshotSessions = 100           // snapshot strips kept, the least recently used is dropped
+)
+
+// Snapshot is a pinned MST shown as a thumbnail in the results page strip
+type Snapshot struct {
+	Number    int    // pin number in the session, starting at 1
+	Label     string // algorithm, metric, vertices, and seed
+	Weight    string // MST total distance
+	Thumbnail string // SVG data URL of the MST
+}
+
+// sessionSnapshots are the pinned snapshots by session, which the edit history cookie identifies
+var sessionSnapshots = struct {
+	sync.Mutex
+	sessions map[string]*snapshotStrip
+}{sessions: make(map[string]*snapshotStrip)}
+
+// snapshotStrip is a session's pinned snapshots, oldest first
+type snapshotStrip struct {
+	snapshots []Snapshot
+	pinned    int       // snapshots pinned in the session, including the dropped ones
+	used      time.Time // latest pin, for dropping the least recently used session
+}
+
+// snapshot creates the snapshot of the MST with its thumbnail
+func (p *PrimMST) snapshot() (Snapshot, error) {
+	var buf bytes.Buffer
+	if err := p.writeFrame(&buf, len(p.order)-1); err != nil {
+		return Snapshot{}, err
+	}
+	label := fmt.Sprintf("%s, %s, %d vertices, seed %d", p.meta.Algorithm, p.meta.Metric, len(p.location), p.meta.Seed)
+	if len(p.meta.Partial) > 0 {
+		label += ", partial"
+	}
+	return Snapshot{
+		Label:     label,
+		Weight:    p.format.format(p.totalDistance()),
+		Thumbnail: "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// pinSnapshot adds the snapshot to the session's strip, dropping the oldest snapshot
+// and the least recently used session when there are too many
+func pinSnapshot(session string, s Snapshot) int {
+	sessionSnapshots.Lock()
+	defer sessionSnapshots.Unlock()
+	strip, ok := sessionSnapshots.sessions[session]
+	if !ok {
+		strip = &snapshotStrip{}
+		sessionSnapshots.sessions[session] = strip
+	}
+	strip.pinned++
+	s.Number = strip.pinned
+	strip.snapshots = append(strip.snapshots, s)
+	if len(strip.snapshots) > maxSnapshots {
+		strip.snapshots = strip.snapshots[1:]
+	}
+	strip.used = time.Now()
+	dropLeastRecent(sessionSnapshots.sessions, maxSnapshotSessions, func(s *snapshotStrip) time.Time { return s.used })
+	return len(strip.snapshots)
+}
+
+// snapshots returns a copy of the request session's pinned snapshots
+func snapshots(r *http.Request) []Snapshot {
+	c, err := r.Cookie(editCookie)
+	if err != nil {
+		return nil
+	}
+	sessionSnapshots.Lock()
+	defer sessionSnapshots.Unlock()
+	strip, ok := sessionSnapshots.sessions[c.Value]
+	if !ok {
+		return nil
+	}
+	return append([]Snapshot(nil), strip.snapshots...)
+}
+
+// HTTP handler for /primmstpin connections.  A POST pins the last MST to the session's
+// snapshot strip, or clears the strip with clear=1, and responds with no content so the
+// browser stays on the results page.
+func handlePin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Pin a snapshot with a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	c, err := r.Cookie(editCookie)
+	if err != nil {
+		http.Error(w, "Snapshots need cookies for the session", http.StatusBadRequest)
+		return
+	}
+	if len(r.FormValue("clear")) > 0 {
+		sessionSnapshots.Lock()
+		delete(sessionSnapshots.sessions, c.Value)
+		sessionSnapshots.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	s, err := p.snapshot()
+	if err != nil {
+		fmt.Printf("snapshot error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Snapshots", fmt.Sprint(pinSnapshot(c.Value, s)))
+	w.WriteHeader(http.StatusNoContent)
+}
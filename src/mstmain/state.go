@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	fileState    = "graphstate.json" // versioned state of the previous graph
+	stateVersion = 1                 // version of the graph state document
+)
+
+// GraphState is the versioned JSON document saved after generating a graph so that
+// a new start vertex can restore the full context of the previous graph
+type GraphState struct {
+	Version   int      `json:"version"`
+	Xmin      float64  `json:"xmin"`
+	Xmax      float64  `json:"xmax"`
+	Ymin      float64  `json:"ymin"`
+	Ymax      float64  `json:"ymax"`
+	Seed      int64    `json:"seed"`                // seed that generated the vertices
+	Start     int      `json:"start"`               // start vertex index
+	Algorithm string   `json:"algorithm"`           // MST algorithm
+	Metric    string   `json:"metric"`              // distance metric
+	Vertices  []Point  `json:"vertices"`            // vertex coordinates
+	Terrain   *Terrain `json:"terrain,omitempty"`   // elevation surface of the Terrain metric
+	Projected string   `json:"projected,omitempty"` // projection of the lon,lat vertices to these coordinates
+	Units     string   `json:"units,omitempty"`     // coordinate units declared by the vertex list header
+	Graph     string   `json:"graph,omitempty"`     // graph ID, so a restore can check it is the graph it asks for
+}
+
+// state creates the graph state from the MST
+func (p *PrimMST) state() *GraphState {
+	s := &GraphState{
+		Version:   stateVersion,
+		Xmin:      p.xmin,
+		Xmax:      p.xmax,
+		Ymin:      p.ymin,
+		Ymax:      p.ymax,
+		Seed:      p.meta.Seed,
+		Start:     p.start,
+		Algorithm: p.meta.Algorithm,
+		Metric:    p.meta.Metric,
+		Vertices:  make([]Point, len(p.location)),
+		Terrain:   p.terrain,
+		Projected: p.meta.Projection,
+		Units:     p.meta.Units,
+	}
+	for i, z := range p.location {
+		s.Vertices[i] = p.point(i, z)
+	}
+	return s
+}
+
+// restoreState sets the endpoints, vertices, and metadata from the graph state
+func (p *PrimMST) restoreState(s *GraphState) error {
+	if len(s.Vertices) == 0 {
+		return errors.New("the previous graph has no vertices")
+	}
+	if s.Start < 0 || s.Start >= len(s.Vertices) {
+		return fmt.Errorf("the previous graph start vertex %d is not in the range 0-%d", s.Start, len(s.Vertices)-1)
+	}
+	p.Endpoints = Endpoints{xmin: s.Xmin, ymin: s.Ymin, xmax: s.Xmax, ymax: s.Ymax}
+	p.location = make([]complex128, len(s.Vertices))
+	names := make([]string, len(s.Vertices))
+	for i, v := range s.Vertices {
+		p.location[i] = complex(v.X, v.Y)
+		names[i] = v.Name
+	}
+	p.names = namesOrNil(names)
+	p.start = s.Start
+	p.meta.Seed = s.Seed
+	p.meta.Start = s.Start
+	p.meta.Algorithm = s.Algorithm
+	p.meta.Metric = s.Metric
+	p.terrain = s.Terrain
+	p.meta.Projection = s.Projected
+	p.meta.Units = s.Units
+	return nil
+}
+
+// saveState writes the graph state to the state file in the data directory.  The read-only demo keeps the
+// graph state in the graph cache only.
+func saveState(s *GraphState) error {
+	if readOnly {
+		return nil
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dataDir, fileState), func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(s)
+	})
+}
+
+// loadState reads the graph state file, or the legacy vertices csv file if there is no state file
+func loadState() (*GraphState, error) {
+	b, err := readFileLocked(filepath.Join(dataDir, fileState))
+	if errors.Is(err, os.ErrNotExist) {
+		return loadLegacyVertices()
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &GraphState{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	if s.Version > stateVersion {
+		return nil, fmt.Errorf("graph state version %d is newer than %d", s.Version, stateVersion)
+	}
+	return s, nil
+}
+
+// loadLegacyVertices reads the csv file of endpoints and vertex locations written by
+// earlier versions, which always started at vertex 0 and recorded no seed
+func loadLegacyVertices() (*GraphState, error) {
+	b, err := readFileLocked(fileVerts)
+	if err != nil {
+		fmt.Printf("Read file %s error: %v\n", fileVerts, err)
+		return nil, err
+	}
+	s := &GraphState{Algorithm: algorithms[0], Metric: metrics[0]}
+	input := bufio.NewScanner(bytes.NewReader(b))
+	input.Scan()
+	line := input.Text()
+	// Each line has comma-separated values
+	values := strings.Split(line, ",")
+	if len(values) < 4 {
+		return nil, fmt.Errorf("file %s endpoints line %q does not have 4 values", fileVerts, line)
+	}
+	for i, v := range []*float64{&s.Xmin, &s.Ymin, &s.Xmax, &s.Ymax} {
+		if *v, err = strconv.ParseFloat(values[i], 64); err != nil {
+			fmt.Printf("String %s conversion to float error: %v\n", values[i], err)
+			return nil, err
+		}
+	}
+
+	for input.Scan() {
+		line := input.Text()
+		// Each line has comma-separated values
+		values := strings.Split(line, ",")
+		if len(values) < 2 {
+			continue
+		}
+		var x, y float64
+		if x, err = strconv.ParseFloat(values[0], 64); err != nil {
+			fmt.Printf("String %s conversion to float error: %v\n", values[0], err)
+			continue
+		}
+		if y, err = strconv.ParseFloat(values[1], 64); err != nil {
+			fmt.Printf("String %s conversion to float error: %v\n", values[1], err)
+			continue
+		}
+		s.Vertices = append(s.Vertices, Point{X: x, Y: y})
+	}
+	return s, nil
+}
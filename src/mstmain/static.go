@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	patternStatic = "/static/"             // http handler for CSS and JS assets
+	dirStatic     = "static"               // directory for the CSS and JS assets
+	cacheControl  = "public, max-age=3600" // browsers revalidate the assets hourly using the ETag
+)
+
+// HTTP handler for /static/ connections
+func handleStatic(w http.ResponseWriter, r *http.Request) {
+	// Clean the path so that files outside the static directory cannot be served
+	name := path.Clean("/" + strings.TrimPrefix(r.URL.Path, patternStatic))
+	file := filepath.Join(dirStatic, filepath.FromSlash(name))
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Printf("Read file %s error: %v\n", file, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The ETag is the content hash, ServeContent answers If-None-Match with 304 Not Modified
+	w.Header().Set("ETag", fmt.Sprintf("\"%x\"", sha256.Sum256(content)))
+	w.Header().Set("Cache-Control", cacheControl)
+	http.ServeContent(w, r, file, info.ModTime(), bytes.NewReader(content))
+}
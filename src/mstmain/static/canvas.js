@@ -0,0 +1,108 @@
+// Interactive canvas view of the MST in node-link format with pan, zoom, and hover
+(function () {
+	"use strict";
+
+	var canvas = document.getElementById("mstcanvas");
+	var tooltip = document.getElementById("tooltip");
+	var ctx = canvas.getContext("2d");
+	var data = JSON.parse(document.getElementById("mstdata").textContent);
+
+	// Data bounds with a small margin
+	var xs = data.nodes.map(function (n) { return n.x; });
+	var ys = data.nodes.map(function (n) { return n.y; });
+	var xmin = Math.min.apply(null, xs), xmax = Math.max.apply(null, xs);
+	var ymin = Math.min.apply(null, ys), ymax = Math.max.apply(null, ys);
+	var span = Math.max(xmax - xmin, ymax - ymin) || 1;
+	xmin -= 0.05 * span;
+	ymin -= 0.05 * span;
+	span *= 1.1;
+
+	// View transform: screen = (data - origin) * scale, y flipped
+	var scale = canvas.width / span;
+	var originX = xmin;
+	var originY = ymin + span;
+	var hover = null;
+
+	function toScreen(x, y) {
+		return [(x - originX) * scale, (originY - y) * scale];
+	}
+
+	function toData(sx, sy) {
+		return [sx / scale + originX, originY - sy / scale];
+	}
+
+	function draw() {
+		var style = getComputedStyle(document.body);
+		ctx.clearRect(0, 0, canvas.width, canvas.height);
+		ctx.lineWidth = 1.5;
+		ctx.strokeStyle = "#aaa";
+		data.links.forEach(function (l) {
+			var a = toScreen(data.nodes[l.source].x, data.nodes[l.source].y);
+			var b = toScreen(data.nodes[l.target].x, data.nodes[l.target].y);
+			ctx.beginPath();
+			ctx.moveTo(a[0], a[1]);
+			ctx.lineTo(b[0], b[1]);
+			ctx.stroke();
+		});
+		data.nodes.forEach(function (n) {
+			var p = toScreen(n.x, n.y);
+			ctx.beginPath();
+			ctx.fillStyle = n.start ? "#0f0" : (n === hover ? "#f00" : style.color);
+			ctx.arc(p[0], p[1], n.start || n === hover ? 5 : 3, 0, 2 * Math.PI);
+			ctx.fill();
+		});
+	}
+
+	// Zoom around the cursor
+	canvas.addEventListener("wheel", function (ev) {
+		ev.preventDefault();
+		var before = toData(ev.offsetX, ev.offsetY);
+		scale *= ev.deltaY < 0 ? 1.2 : 1 / 1.2;
+		var after = toData(ev.offsetX, ev.offsetY);
+		originX += before[0] - after[0];
+		originY += before[1] - after[1];
+		draw();
+	});
+
+	// Drag to pan, hover to show the nearest vertex
+	var drag = null;
+	canvas.addEventListener("mousedown", function (ev) {
+		drag = [ev.offsetX, ev.offsetY];
+	});
+	window.addEventListener("mouseup", function () {
+		drag = null;
+	});
+	canvas.addEventListener("mousemove", function (ev) {
+		if (drag) {
+			originX -= (ev.offsetX - drag[0]) / scale;
+			originY += (ev.offsetY - drag[1]) / scale;
+			drag = [ev.offsetX, ev.offsetY];
+			draw();
+			return;
+		}
+		var nearest = null, best = 64;
+		data.nodes.forEach(function (n) {
+			var p = toScreen(n.x, n.y);
+			var d = (p[0] - ev.offsetX) * (p[0] - ev.offsetX) + (p[1] - ev.offsetY) * (p[1] - ev.offsetY);
+			if (d < best) {
+				best = d;
+				nearest = n;
+			}
+		});
+		if (nearest !== hover) {
+			hover = nearest;
+			draw();
+		}
+		if (hover) {
+			tooltip.style.display = "block";
+			tooltip.style.left = (ev.offsetX + 12) + "px";
+			tooltip.style.top = (ev.offsetY + 12) + "px";
+			tooltip.textContent = (hover.name || "vertex " + hover.id) + " (" + hover.x.toFixed(2) + ", " + hover.y.toFixed(2) +
+				") order " + hover.order;
+		} else {
+			tooltip.style.display = "none";
+		}
+	});
+
+	draw();
+})();
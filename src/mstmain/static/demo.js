@@ -0,0 +1,81 @@
+// Brownian motion demo: draws the MST frames streamed by /primmstdemo/events
+(function () {
+	"use strict";
+
+	var canvas = document.getElementById("democanvas");
+	var ctx = canvas.getContext("2d");
+	var bounds = JSON.parse(canvas.dataset.bounds);
+	var status = document.getElementById("demostatus");
+	var start = document.getElementById("demostart");
+	var stop = document.getElementById("demostop");
+	var source = null;
+
+	// Fit the bounds to the canvas, y up
+	function toScreen(p) {
+		return [(p[0] - bounds[0]) / (bounds[1] - bounds[0]) * canvas.width,
+			(bounds[3] - p[1]) / (bounds[3] - bounds[2]) * canvas.height];
+	}
+
+	function draw(f) {
+		var style = getComputedStyle(document.body);
+		ctx.clearRect(0, 0, canvas.width, canvas.height);
+		ctx.lineWidth = 1.5;
+		// Unchanged edges first so the changed edges are on top
+		[0, 1].forEach(function (changed) {
+			ctx.strokeStyle = changed ? "#f80" : "#aaa";
+			f.links.forEach(function (l) {
+				if (l[2] !== changed) {
+					return;
+				}
+				var a = toScreen(f.nodes[l[0]]), b = toScreen(f.nodes[l[1]]);
+				ctx.beginPath();
+				ctx.moveTo(a[0], a[1]);
+				ctx.lineTo(b[0], b[1]);
+				ctx.stroke();
+			});
+		});
+		f.nodes.forEach(function (n, i) {
+			var p = toScreen(n);
+			ctx.beginPath();
+			ctx.fillStyle = i === f.start ? "#0f0" : style.color;
+			ctx.arc(p[0], p[1], i === f.start ? 5 : 3, 0, 2 * Math.PI);
+			ctx.fill();
+		});
+		status.textContent = "Frame " + f.frame + ", distance " + f.distance.toFixed(2) + ", " +
+			f.changed + " of " + f.links.length + " edges changed";
+	}
+
+	function close() {
+		if (source) {
+			source.close();
+			source = null;
+		}
+		start.disabled = false;
+		stop.disabled = true;
+	}
+
+	start.addEventListener("click", function () {
+		close();
+		var params = new URLSearchParams();
+		["interval", "sigma", "demoseed"].forEach(function (id) {
+			var input = document.getElementById(id);
+			params.set(input.name, input.value);
+		});
+		source = new EventSource("/primmstdemo/events?" + params.toString());
+		source.addEventListener("frame", function (ev) {
+			draw(JSON.parse(ev.data));
+		});
+		source.addEventListener("failed", function (ev) {
+			status.textContent = ev.data;
+			close();
+		});
+		source.addEventListener("end", close);
+		source.onerror = function () {
+			status.textContent = "The demo stream was closed";
+			close();
+		};
+		start.disabled = true;
+		stop.disabled = false;
+	});
+	stop.addEventListener("click", close);
+})();
@@ -0,0 +1,102 @@
+// Preview of the pasted vertex list, the server parses it the same way
+(function () {
+	"use strict";
+
+	var list = document.getElementById("vertexlist");
+	var preview = document.getElementById("vertexlistpreview");
+	var vertices = document.getElementById("vertices");
+
+	function bound(id) {
+		return parseFloat(document.getElementById(id).value);
+	}
+
+	function update() {
+		var accepted = 0, rejected = 0;
+		var xmin = bound("xstart"), xmax = bound("xend"), ymin = bound("ystart"), ymax = bound("yend");
+		// Projected lists have lon,lat lines within the projection's limits
+		var projection = document.getElementById("projection").value;
+		if (projection === "Web Mercator") {
+			xmin = -180; xmax = 180; ymin = -85.051128779806; ymax = 85.051128779806;
+		} else if (projection === "UTM") {
+			xmin = -180; xmax = 180; ymin = -80; ymax = 84;
+		// Auto-fit moves the bounds to the vertices, so none are outside them
+		} else if (document.getElementById("autofit").checked) {
+			xmin = ymin = -Infinity;
+			xmax = ymax = Infinity;
+		}
+		// An optional header row names the columns, with the coordinate units in brackets
+		var roles = {x: "x", lon: "x", lng: "x", longitude: "x", easting: "x",
+			y: "y", lat: "y", latitude: "y", northing: "y", name: "name", label: "name", id: "name"};
+		var cols = {x: 0, y: 1, name: 2, fields: 3}, units = "", first = true, error = "";
+		function split(line, n) {
+			if (/[,;]/.test(line)) {
+				return line.split(/[,;]/).map(function (f) { return f.trim(); });
+			}
+			var fields = line.split(/\s+/);
+			return fields.length > n ? fields.slice(0, n - 1).concat(fields.slice(n - 1).join(" ")) : fields;
+		}
+		function header(line) {
+			var h = {x: -1, y: -1, name: -1, fields: 0}, found = false, xunit = "", yunit = "";
+			var fields = split(line, Infinity);
+			h.fields = fields.length;
+			fields.forEach(function (f, i) {
+				var m = /^([A-Za-z_]+)\s*(?:\[([^\]]*)\]|\(([^)]*)\))?$/.exec(f);
+				var role = m && roles[m[1].toLowerCase()];
+				if (!role) {
+					return;
+				}
+				found = true;
+				h[role] = i;
+				var unit = ((m[2] || "") + (m[3] || "")).trim();
+				if (role === "x") {
+					xunit = unit;
+				} else if (role === "y") {
+					yunit = unit;
+				}
+			});
+			if (!found) {
+				return false;
+			}
+			if (h.x < 0 || h.y < 0) {
+				error = "the header needs x and y columns";
+			} else if (xunit && yunit && xunit !== yunit) {
+				error = "the header units of x and y must agree";
+			}
+			cols = h;
+			units = xunit || yunit;
+			return true;
+		}
+		list.value.split("\n").forEach(function (line) {
+			line = line.trim();
+			if (line === "" || line.charAt(0) === "#") {
+				return;
+			}
+			if (first) {
+				first = false;
+				if (header(line)) {
+					return;
+				}
+			}
+			// x,y or x,y,name separated by commas or semicolons, or else by whitespace,
+			// in the columns of the header
+			var fields = split(line, cols.fields);
+			var x = Number(fields[cols.x]), y = Number(fields[cols.y]);
+			var count = fields.length === cols.fields || (cols.name === cols.fields - 1 && fields.length === cols.fields - 1);
+			if (!count || fields[cols.x] === "" || fields[cols.y] === "" ||
+				isNaN(x) || isNaN(y) || x < xmin || x > xmax || y < ymin || y > ymax) {
+				rejected++;
+			} else {
+				accepted++;
+			}
+		});
+		preview.textContent = error ? error : accepted + rejected === 0 ? "" :
+			accepted + " vertices accepted, " + rejected + " lines rejected" + (units ? ", coordinates in " + units : "");
+		// The number of vertices is not used with a pasted list
+		vertices.required = accepted + rejected === 0;
+	}
+
+	list.addEventListener("input", update);
+	["xstart", "xend", "ystart", "yend", "autofit", "projection"].forEach(function (id) {
+		document.getElementById(id).addEventListener("input", update);
+	});
+})();
@@ -0,0 +1,41 @@
+// Progressive rendering of the MST grid: the vertex layer is written as html and the
+// edge layer is streamed afterwards as calls to primmstCells
+var primmstGrid = null;
+
+// The pin form stays on the results page and reports the number of pinned snapshots,
+// which the strip shows from the next run
+document.addEventListener("DOMContentLoaded", function () {
+	"use strict";
+	var form = document.getElementById("pinform");
+	if (form === null) {
+		return;
+	}
+	form.addEventListener("submit", function (event) {
+		event.preventDefault();
+		var body = new URLSearchParams();
+		if (event.submitter && event.submitter.name === "clear") {
+			body.set("clear", "1");
+		}
+		fetch(form.action, {method: "POST", body: body}).then(function (resp) {
+			var status = document.getElementById("pinstatus");
+			if (!resp.ok) {
+				status.textContent = "pin failed: " + resp.status;
+			} else if (body.has("clear")) {
+				status.textContent = "0 pinned";
+			} else {
+				status.textContent = resp.headers.get("X-Snapshots") + " pinned, shown from the next run";
+			}
+		});
+	});
+});
+
+// primmstCells sets the CSS class of the grid cells at the given indexes
+function primmstCells(cls, cells) {
+	"use strict";
+	if (primmstGrid === null) {
+		primmstGrid = document.querySelector("div.grid").children;
+	}
+	cells.forEach(function (i) {
+		primmstGrid[i].className = cls;
+	});
+}
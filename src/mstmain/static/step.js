@@ -0,0 +1,10 @@
+// Step through the MST with the arrow keys by clicking the step buttons,
+// each step is a POST to the server which keeps the step of the session
+document.addEventListener("keydown", (event) => {
+	const buttons = {ArrowRight: "stepnext", ArrowLeft: "stepprev", Home: "stepreset"};
+	const button = document.getElementById(buttons[event.key]);
+	if (button && !button.disabled) {
+		event.preventDefault();
+		button.click();
+	}
+});
@@ -0,0 +1,61 @@
+// Runs vertex generation and Prim's algorithm in the browser using the WebAssembly solver
+(function () {
+	"use strict";
+
+	var form = document.getElementById("wasmform");
+	var status = document.getElementById("status");
+	var canvas = document.getElementById("mstcanvas");
+	var ctx = canvas.getContext("2d");
+
+	function draw(result, xmin, xmax, ymin, ymax) {
+		var sx = canvas.width / (xmax - xmin);
+		var sy = canvas.height / (ymax - ymin);
+		function toScreen(v) {
+			return [(v.x - xmin) * sx, (ymax - v.y) * sy];
+		}
+		ctx.clearRect(0, 0, canvas.width, canvas.height);
+		ctx.lineWidth = 1.5;
+		ctx.strokeStyle = "#aaa";
+		result.edges.forEach(function (e) {
+			var a = toScreen(result.vertices[e.v]);
+			var b = toScreen(result.vertices[e.w]);
+			ctx.beginPath();
+			ctx.moveTo(a[0], a[1]);
+			ctx.lineTo(b[0], b[1]);
+			ctx.stroke();
+		});
+		result.vertices.forEach(function (v, i) {
+			var p = toScreen(v);
+			ctx.beginPath();
+			ctx.fillStyle = i === result.start ? "#0f0" : "#000";
+			ctx.arc(p[0], p[1], i === result.start ? 5 : 3, 0, 2 * Math.PI);
+			ctx.fill();
+		});
+	}
+
+	form.addEventListener("submit", function (ev) {
+		ev.preventDefault();
+		var value = function (id) { return parseFloat(document.getElementById(id).value); };
+		var xmin = value("xmin"), xmax = value("xmax"), ymin = value("ymin"), ymax = value("ymax");
+		var seed = document.getElementById("seed").value === "" ? Date.now() : value("seed");
+		var start = performance.now();
+		var result = primmst(value("vertices"), xmin, xmax, ymin, ymax, seed);
+		if (result.error) {
+			status.textContent = result.error;
+			return;
+		}
+		draw(result, xmin, xmax, ymin, ymax);
+		document.getElementById("distance").textContent = result.distance.toFixed(2);
+		status.textContent = "MST found in " + (performance.now() - start).toFixed(1) + " ms, seed " + seed;
+	});
+
+	// Start the Go runtime, which registers primmst, then enable the form
+	var go = new Go();
+	WebAssembly.instantiateStreaming(fetch("primmst.wasm"), go.importObject).then(function (obj) {
+		go.run(obj.instance);
+		form.querySelector("input[type=submit]").disabled = false;
+		status.textContent = "Ready";
+	}).catch(function (err) {
+		status.textContent = "Failed to load primmst.wasm, see the README to build it: " + err;
+	});
+})();
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	fileStep         = "templates/step.html" // html for the step-through mode
+	patternStepNext  = "/step/next"          // http handler to add the next MST edge
+	patternStepPrev  = "/step/prev"          // http handler to remove the last MST edge
+	patternStepReset = "/step/reset"         // http handler to restart stepping the last MST
+	maxStepSessions  = 20                    // stepping sessions kept, each holds an MST, the least recently used is dropped
+)
+
+// StepT is the step-through page of the MST after the first Step edges
+type StepT struct {
+	Theme    string
+	Step     int    // edges added
+	Steps    int    // edges of the MST
+	Edge     string // edge added by the step, empty at step 0
+	Distance string // total distance of the edges added
+	Frame    string // SVG image of the MST so far
+}
+
+// stepSession steps through an MST one edge at a time
+type stepSession struct {
+	p    *PrimMST
+	step int       // edges added
+	used time.Time // latest step, for dropping the least recently used session
+}
+
+// stepSessions are the stepping sessions, which the edit history cookie identifies
+var stepSessions = struct {
+	sync.Mutex
+	sessions map[string]*stepSession
+}{sessions: make(map[string]*stepSession)}
+
+// step moves the session's MST by the step action and returns a copy of the session.
+// Reset and a session without an MST start at step 0 of the last MST.
+func step(session, action string) (stepSession, error) {
+	stepSessions.Lock()
+	defer stepSessions.Unlock()
+	s, ok := stepSessions.sessions[session]
+	if !ok || action == patternStepReset {
+		p := latestMST()
+		if p == nil || len(p.order) == 0 {
+			return stepSession{}, fmt.Errorf("no MST has been created, submit the graph options first")
+		}
+		s = &stepSession{p: p}
+		stepSessions.sessions[session] = s
+	}
+	switch action {
+	case patternStepNext:
+		if s.step < len(s.p.order)-1 {
+			s.step++
+		}
+	case patternStepPrev:
+		if s.step > 0 {
+			s.step--
+		}
+	}
+	s.used = time.Now()
+	dropLeastRecent(stepSessions.sessions, maxStepSessions, func(s *stepSession) time.Time { return s.used })
+	return *s, nil
+}
+
+// page creates the step-through page of the session
+func (s stepSession) page() (StepT, error) {
+	p := s.p
+	var buf bytes.Buffer
+	if err := p.writeFrame(&buf, s.step); err != nil {
+		return StepT{}, err
+	}
+	page := StepT{Theme: p.theme, Step: s.step, Steps: len(p.order) - 1, Frame: buf.String()}
+	var distance float64
+	for _, v := range p.order[1 : s.step+1] {
+		e := p.mst[v]
+		distance += p.weight(e.v, e.w)
+		page.Edge = fmt.Sprintf("%s to %s, length %s", p.name(e.v), p.name(e.w), p.format.format(p.weight(e.v, e.w)))
+	}
+	page.Distance = p.format.format(distance)
+	return page, nil
+}
+
+// HTTP handler for the /step/next, /step/prev, and /step/reset connections.  A POST moves the
+// session's step and a GET of /step/reset starts stepping the last MST.  The response is the
+// step-through page, or the SVG frame with format=svg, with the X-Step and X-Steps headers.
+func handleStep(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.URL.Path != patternStepReset {
+		http.Error(w, "Step with a POST", http.StatusMethodNotAllowed)
+		return
+	}
+	editSession(w, r)
+	c, err := r.Cookie(editCookie)
+	if err != nil {
+		http.Error(w, "Stepping needs cookies for the session", http.StatusBadRequest)
+		return
+	}
+	s, err := step(c.Value, r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	page, err := s.page()
+	if err != nil {
+		fmt.Printf("page error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Step", fmt.Sprint(page.Step))
+	w.Header().Set("X-Steps", fmt.Sprint(page.Steps))
+	if r.FormValue("format") == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		fmt.Fprint(w, page.Frame)
+		return
+	}
+	if err := tmplStep.Execute(w, page); err != nil {
+		fmt.Printf("Write to HTTP output using template with step error: %v\n", err)
+	}
+}
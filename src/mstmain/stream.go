@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	flushRows  = 25 // #grid rows written between flushes to the client
+	flushEdges = 25 // #MST edges rasterized between flushes to the client
+)
+
+// streamWriter writes a chunked http response, the compress middleware handles gzip
+type streamWriter struct {
+	w       io.Writer    // the http.ResponseWriter
+	flusher http.Flusher // nil when the http.ResponseWriter cannot flush
+}
+
+// newStreamWriter creates a streamWriter for an html response
+func newStreamWriter(w http.ResponseWriter, r *http.Request) *streamWriter {
+	sw := &streamWriter{w: w}
+	sw.flusher, _ = w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	return sw
+}
+
+// Write implements io.Writer
+func (sw *streamWriter) Write(b []byte) (int, error) {
+	return sw.w.Write(b)
+}
+
+// Flush sends the data written so far to the client
+func (sw *streamWriter) Flush() error {
+	if sw.flusher != nil {
+		sw.flusher.Flush()
+	}
+	return nil
+}
+
+// Close flushes the remaining data to the client
+func (sw *streamWriter) Close() error {
+	return sw.Flush()
+}
+
+// writeGrid writes the grid cells, rows of columns cells, as html divs, flushing every flushRows rows
+func (sw *streamWriter) writeGrid(grid []string, columns int) error {
+	for row := 0; row < len(grid)/columns; row++ {
+		for _, class := range grid[row*columns : (row+1)*columns] {
+			if _, err := io.WriteString(sw, "<div class=\""+class+"\"></div>"); err != nil {
+				return err
+			}
+		}
+		if (row+1)%flushRows == 0 {
+			if err := sw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeCells writes the grid cells colored since the grid was written as a script
+// that sets their CSS classes, then flushes so the browser draws them
+func (sw *streamWriter) writeCells(g *gridPlot) error {
+	if len(g.changed) == 0 {
+		return nil
+	}
+	// Group the cells by their current class, a cell may have been colored more than once
+	var classes []string
+	cells := make(map[string][]string)
+	seen := make(map[int]bool)
+	for _, i := range g.changed {
+		if seen[i] {
+			continue
+		}
+		seen[i] = true
+		class := g.grid[i]
+		if _, ok := cells[class]; !ok {
+			classes = append(classes, class)
+		}
+		cells[class] = append(cells[class], fmt.Sprint(i))
+	}
+	g.changed = g.changed[:0]
+
+	if _, err := io.WriteString(sw, "<script>"); err != nil {
+		return err
+	}
+	for _, class := range classes {
+		if _, err := fmt.Fprintf(sw, "primmstCells(%q,[%s]);", class, strings.Join(cells[class], ",")); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(sw, "</script>\n"); err != nil {
+		return err
+	}
+	return sw.Flush()
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	stressSample     = time.Second                           // interval between stress test heap samples
+	stressReport     = 10                                    // heap samples between stress test progress lines
+	stressExpression = "sqrt(dx^2+dy^2) * (1 + 0.1*abs(dy))" // edge weight of the Custom metric in the stress test
+)
+
+// stressRun is a solve of the stress test
+type stressRun struct {
+	algorithm string
+	latency   time.Duration
+	err       error
+}
+
+// stressGraph creates a random graph with a random size, algorithm, metric, and start vertex
+func stressGraph(rnd *rand.Rand) (*PrimMST, error) {
+	p := &PrimMST{rnd: rand.New(rand.NewSource(rnd.Int63())),
+		Endpoints: Endpoints{xmin: defaultXmin, xmax: defaultXmax, ymin: defaultYmin, ymax: defaultYmax}}
+	p.meta.Algorithm = algorithms[rnd.Intn(len(algorithms))]
+	p.meta.Metric = metrics[rnd.Intn(len(metrics))]
+	if _, ok := proximityAlgorithms[p.meta.Algorithm]; ok {
+		// The proximity graphs are Euclidean
+		p.meta.Metric = metrics[0]
+	}
+	p.neighbors, p.randomEdges = 1+rnd.Intn(2*defaultNeighbors), rnd.Intn(2*defaultRandomEdges+1)
+	switch p.meta.Metric {
+	case metricCustom:
+		e, err := parseExpr(stressExpression)
+		if err != nil {
+			return nil, err
+		}
+		p.model, p.meta.Expression = exprModel{e}, stressExpression
+	case metricHaversine:
+		p.model = haversineModel{}
+	case metricCost:
+		p.model = costModel{perKm: defaultCostKm, setup: 1, km: 1}
+	case metricTerrain:
+		// The built-in surfaces, the raster needs an upload
+		p.terrain = &Terrain{Surface: terrainSurfaces[rnd.Intn(len(terrainSurfaces)-1)],
+			Relief: defaultRelief * (p.xmax - p.xmin)}
+	}
+	p.randomVertices(minVertices + rnd.Intn(maxVertices-minVertices+1))
+	p.start = rnd.Intn(len(p.location))
+	return p, nil
+}
+
+// stressSolve finds the MST of a random graph and checks that it spans the vertices
+func stressSolve(rnd *rand.Rand) stressRun {
+	start := time.Now()
+	p, err := stressGraph(rnd)
+	if err != nil {
+		return stressRun{err: err}
+	}
+	run := stressRun{algorithm: p.meta.Algorithm}
+	if run.err = p.findDistances(); run.err == nil {
+		run.err = p.solve()
+	}
+	run.latency = time.Since(start)
+	if run.err != nil {
+		return run
+	}
+	edges := 0
+	for _, e := range p.mst {
+		if e != nil {
+			edges++
+		}
+	}
+	if edges != len(p.location)-1 {
+		run.err = fmt.Errorf("%s MST of %d vertices has %d edges", p.meta.Algorithm, len(p.location), edges)
+	}
+	return run
+}
+
+// heapInUse collects the garbage and returns the heap bytes in use
+func heapInUse() uint64 {
+	var m runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m)
+	return m.HeapInuse
+}
+
+// latencyPercentile returns the percentile of the sorted latencies
+func latencyPercentile(sorted []time.Duration, percent float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(percent / 100 * float64(len(sorted)-1))
+	return sorted[i]
+}
+
+// stressTest solves random graphs of random sizes, algorithms, and metrics on the workers
+// until the duration has passed, writing progress every stressReport heap samples and then the latency
+// percentiles of each algorithm and the memory growth.  It returns an error when a solve
+// failed, so a deployment can be validated before it is used.
+func stressTest(w io.Writer, d time.Duration, workers int, seed int64) error {
+	if workers < 1 {
+		return fmt.Errorf("stress test needs at least 1 worker, not %d", workers)
+	}
+	heapStart, goroutines := heapInUse(), runtime.NumGoroutine()
+	fmt.Fprintf(w, "Stress test for %v on %d workers, seed %d, %d-%d vertices\n", d, workers, seed, minVertices, maxVertices)
+
+	var (
+		mu       sync.Mutex
+		runs     []stressRun
+		heapPeak = heapStart
+		wg       sync.WaitGroup
+	)
+	start := time.Now()
+	deadline := start.Add(d)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(rnd *rand.Rand) {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				run := stressSolve(rnd)
+				mu.Lock()
+				runs = append(runs, run)
+				mu.Unlock()
+			}
+		}(rand.New(rand.NewSource(seed + int64(i))))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	ticker := time.NewTicker(stressSample)
+	defer ticker.Stop()
+	for samples, running := 0, true; running; {
+		select {
+		case <-done:
+			running = false
+		case <-ticker.C:
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			if m.HeapInuse > heapPeak {
+				heapPeak = m.HeapInuse
+			}
+			if samples++; samples%stressReport != 0 {
+				continue
+			}
+			mu.Lock()
+			n := len(runs)
+			mu.Unlock()
+			fmt.Fprintf(w, "%v: %d solves, heap %d KiB\n", time.Since(start).Round(time.Second), n, m.HeapInuse>>10)
+		}
+	}
+	elapsed := time.Since(start)
+
+	latencies := make(map[string][]time.Duration)
+	var all []time.Duration
+	failed := 0
+	for _, run := range runs {
+		if run.err != nil {
+			failed++
+			if failed <= 10 {
+				fmt.Fprintf(w, "error: %v\n", run.err)
+			}
+			continue
+		}
+		latencies[run.algorithm] = append(latencies[run.algorithm], run.latency)
+		all = append(all, run.latency)
+	}
+	latencies["all"] = all
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "algorithm\tsolves\tp50\tp90\tp99\tmax\t\n")
+	for _, alg := range append(append([]string(nil), algorithms...), "all") {
+		l := latencies[alg]
+		if len(l) == 0 {
+			continue
+		}
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+		fmt.Fprintf(tw, "%s\t%d\t%v\t%v\t%v\t%v\t\n", alg, len(l), latencyPercentile(l, 50), latencyPercentile(l, 90),
+			latencyPercentile(l, 99), l[len(l)-1])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	heapEnd := heapInUse()
+	fmt.Fprintf(w, "%d solves in %v, %.1f per second, %d failed\n", len(runs), elapsed.Round(time.Millisecond),
+		float64(len(runs))/elapsed.Seconds(), failed)
+	fmt.Fprintf(w, "heap in use: %d KiB at the start, %d KiB peak, %d KiB at the end (%+d KiB), goroutines %d -> %d\n",
+		heapStart>>10, heapPeak>>10, heapEnd>>10, (int64(heapEnd)-int64(heapStart))>>10, goroutines, runtime.NumGoroutine())
+	if failed > 0 {
+		return fmt.Errorf("stress test: %d of %d solves failed", failed, len(runs))
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math/cmplx"
+)
+
+// stretchBounds are the upper bounds of the stretch classes stretch0-3, stretch4 is above them
+var stretchBounds = []float64{1.1, 1.25, 1.5, 2}
+
+// Stretch is the MST path length from the start vertex to each vertex divided by the
+// straight-line distance between them, how much longer a route along the MST is
+type Stretch struct {
+	Mean      float64   `json:"mean"`
+	Max       float64   `json:"max"`
+	MaxVertex int       `json:"maxVertex"` // vertex with the largest stretch
+	Vertices  []float64 `json:"vertices"`  // stretch of each vertex, 0 for the start vertex and unreached vertices
+}
+
+// stretchSummary reports the mean and max stretch
+func (p *PrimMST) stretchSummary() string {
+	return fmt.Sprintf("MST path stretch from the start vertex: mean %.3f, max %.3f at %s",
+		p.stretch.Mean, p.stretch.Max, p.name(p.stretch.MaxVertex))
+}
+
+// pathLengths returns the MST path length from the start vertex to each vertex,
+// -1 for the vertices the tree does not reach
+func (p *PrimMST) pathLengths() []float64 {
+	adj := p.adjacency()
+	path := make([]float64, len(p.location))
+	for i := range path {
+		path[i] = -1
+	}
+	path[p.start] = 0
+	queue := []int{p.start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range adj[v] {
+			if path[w] < 0 {
+				path[w] = path[v] + p.weight(v, w)
+				queue = append(queue, w)
+			}
+		}
+	}
+	return path
+}
+
+// findStretch finds the stretch of each vertex reached by the MST.  Vertices at the start
+// vertex location have no stretch.
+func (p *PrimMST) findStretch() {
+	s := &Stretch{Vertices: make([]float64, len(p.location)), MaxVertex: p.start}
+	count := 0
+	for v, path := range p.pathLengths() {
+		d := cmplx.Abs(p.location[v] - p.location[p.start])
+		if path < 0 || d == 0 {
+			continue
+		}
+		s.Vertices[v] = path / d
+		s.Mean += s.Vertices[v]
+		count++
+		if s.Vertices[v] > s.Max {
+			s.Max, s.MaxVertex = s.Vertices[v], v
+		}
+	}
+	if count > 0 {
+		s.Mean /= float64(count)
+	}
+	p.stretch = s
+}
+
+// stretchClass returns the CSS class of the stretch
+func stretchClass(stretch float64) string {
+	for i, b := range stretchBounds {
+		if stretch <= b {
+			return fmt.Sprintf("stretch%d", i)
+		}
+	}
+	return fmt.Sprintf("stretch%d", len(stretchBounds))
+}
+
+// drawStretch colors the vertices by their MST path stretch over the vertex colors
+func drawStretch(p *PrimMST, g *gridPlot, w *streamWriter) error {
+	for v, z := range p.location {
+		if s := p.stretch.Vertices[v]; s > 0 {
+			g.point(real(z), imag(z), stretchClass(s))
+		}
+	}
+	p.note("stretch", p.stretchSummary())
+	return nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+const (
+	patternSweep = "/primmstsweep"        // http handler for the start vertex sweep
+	fileSweep    = "templates/sweep.html" // html for the start vertex sweep
+	sweepBuckets = 5                      // number of CSS classes for the edge frequencies
+	sweepListed  = 10                     // edges listed that are most often added early
+)
+
+// SweepEdge is an edge added among the first k by Prim from some start vertices
+type SweepEdge struct {
+	V       string // vertex name or index
+	W       string // vertex name or index
+	Length  string // edge length in the label format
+	Count   int    // start vertices that added the edge among the first k
+	Percent string // percent of the start vertices
+}
+
+// SweepT contains the start vertex sweep HTML template actions
+type SweepT struct {
+	Grid    []string      // plotting grid
+	Xlabel  []string      // x-axis labels
+	Ylabel  []string      // y-axis labels
+	Legend  []LegendEntry // layers drawn on the grid
+	K       int           // edges counted from each start vertex
+	Roots   int           // start vertices swept
+	Edges   int           // distinct edges among the first k of some start vertex
+	Forced  int           // edges among the first k of every start vertex
+	Top     []SweepEdge   // edges most often among the first k
+	Runtime string        // sweep time
+	Status  string        // status of the sweep
+	Partial string        // why the sweep stopped early, empty when it finished
+	Theme   string        // page theme
+}
+
+// sweep runs Prim from every vertex and counts how often each edge is among the first k
+// edges added.  It stops early when the computation is cancelled and returns the number
+// of start vertices swept.
+func (p *PrimMST) sweep(k int) (map[Edge]int, int, error) {
+	n := len(p.location)
+	counts := make(map[Edge]int)
+	for s := 0; s < n; s++ {
+		if p.checkpoint(fmt.Sprintf("sweep after %d of %d start vertices", s, n)) {
+			return counts, s, nil
+		}
+		q, err := solver.NewQueue(priorityQueue, n)
+		if err != nil {
+			return nil, 0, err
+		}
+		parent, order := solver.PrimQueue(p.graph, s, q)
+		for i := 1; i <= k && i < len(order); i++ {
+			w := order[i]
+			counts[Edge{v: parent[w], w: w}.key()]++
+		}
+	}
+	return counts, n, nil
+}
+
+// plotSweep draws the MST faintly and the swept edges colored by how often they are
+// among the first k added relative to the most frequent edge, from light to dark
+func (p *PrimMST) plotSweep(counts map[Edge]int) *gridPlot {
+	g := newGridPlot(p.plotEndpoints())
+	for _, e := range p.mst {
+		if e != nil {
+			a, b := p.location[e.v], p.location[e.w]
+			g.line(real(a), imag(a), real(b), imag(b), "edge")
+		}
+	}
+	// Draw the most frequent edges last so they are on top
+	edges := make([]Edge, 0, len(counts))
+	for e := range counts {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool { return counts[edges[i]] < counts[edges[j]] })
+	most := 1
+	if len(edges) > 0 {
+		most = counts[edges[len(edges)-1]]
+	}
+	for _, e := range edges {
+		bucket := (counts[e] - 1) * sweepBuckets / most
+		a, b := p.location[e.v], p.location[e.w]
+		g.line(real(a), imag(a), real(b), imag(b), fmt.Sprintf("sweep%d", bucket))
+	}
+	for _, z := range p.location {
+		g.point(real(z), imag(z), "vertex")
+	}
+	return g
+}
+
+// HTTP handler for /primmstsweep connections.  The k query parameter is the number of
+// edges counted from each start vertex, by default a tenth of the vertices.
+func handleSweep(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	p = p.withMatrix()
+	n := len(p.location)
+	k := (n - 1) / 10
+	if k < 1 {
+		k = 1
+	}
+	if str := r.FormValue("k"); len(str) > 0 {
+		var err error
+		if k, err = strconv.Atoi(str); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if k < 1 || k > n-1 {
+		http.Error(w, fmt.Sprintf("k %d is not in the range 1-%d", k, n-1), http.StatusBadRequest)
+		return
+	}
+	ctx, cancel, err := computeContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer cancel()
+
+	// The sweep shares the MST's graph, so it records its own partial result
+	sp := *p
+	sp.ctx = ctx
+	sp.meta.Partial = ""
+	start := time.Now()
+	counts, roots, err := sp.sweep(k)
+	if err != nil {
+		fmt.Printf("sweep error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plot := SweepT{K: k, Roots: roots, Edges: len(counts), Partial: sp.meta.Partial, Theme: p.theme,
+		Runtime: time.Since(start).Round(time.Millisecond).String()}
+	if roots == 0 {
+		plot.Status = p.format.lang.message(msgNoSweep)
+	} else {
+		for e, c := range counts {
+			if c == roots {
+				plot.Forced++
+			}
+			plot.Top = append(plot.Top, SweepEdge{V: p.name(e.v), W: p.name(e.w), Count: c,
+				Length:  p.format.format(p.weight(e.v, e.w)),
+				Percent: fmt.Sprintf("%.1f%%", 100*float64(c)/float64(roots))})
+		}
+		sort.Slice(plot.Top, func(i, j int) bool { return plot.Top[i].Count > plot.Top[j].Count })
+		if len(plot.Top) > sweepListed {
+			plot.Top = plot.Top[:sweepListed]
+		}
+		plot.Status = fmt.Sprintf("Prim from %d start vertices: %d edges are among the first %d added from some start vertex, %d from every one",
+			roots, plot.Edges, k, plot.Forced)
+		g := p.plotSweep(counts)
+		plot.Grid = g.grid
+		plot.Xlabel, plot.Ylabel = g.labels(p.format)
+		plot.Legend = legend(g.grid)
+	}
+
+	sw := newStreamWriter(w, r)
+	if err := tmplSweep.ExecuteTemplate(sw, "gridstart", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(plot.Grid, columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplSweep.ExecuteTemplate(sw, "gridend", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	fileTable = "templates/table.html" // html for the table view of the MST
+	viewPlot  = "plot"                 // results shown on the grid plot
+	viewTable = "table"                // results shown as HTML tables for screen readers
+)
+
+// views are the results views of the form, the first is the default
+var views = []string{viewPlot, viewTable}
+
+// TableVertex is a row of the vertex table
+type TableVertex struct {
+	Vertex int
+	Name   string
+	X      string
+	Y      string
+	Degree int    // MST degree
+	Order  int    // position in the order the algorithm added the vertex to the tree, from 1
+	Parent string // vertex joining it to the tree, empty for the start vertex
+}
+
+// TableEdge is a row of the edge table
+type TableEdge struct {
+	Order  int // order the algorithm added the edge to the tree, from 1
+	From   string
+	To     string
+	Length string
+}
+
+// TableT is the table view of the MST, the results shared with the plot view and the
+// vertex and edge tables
+type TableT struct {
+	PlotT
+	Views    []Choice      // results views
+	Vertex   []TableVertex // vertices in index order
+	Edge     []TableEdge   // edges in the order they were added
+	Reached  int           // vertices in the tree
+	Longest  string        // longest MST edge
+	MeanEdge string        // mean MST edge length
+}
+
+// writeTable writes the MST as HTML tables of the vertices and edges with the results of
+// the plot view, in document order for screen readers
+func (p *PrimMST) writeTable(w http.ResponseWriter, status []string) error {
+	start := time.Now()
+	table := TableT{Views: choices(views, viewTable)}
+	table.Theme = p.theme
+	table.LabelStyle = p.format.style
+	table.Precision = p.format.precision
+	table.Locale = p.format.locale
+	table.RunID = p.runID
+	table.TreeCount = p.trees
+	table.Layers = p.layerView(nil)
+	p.results(&table.PlotT, status)
+
+	label := func(v int) string {
+		if p.names != nil {
+			return p.name(v)
+		}
+		return fmt.Sprint(v)
+	}
+	adj := p.adjacency()
+	table.Vertex = make([]TableVertex, len(p.location))
+	for v, z := range p.location {
+		table.Vertex[v] = TableVertex{Vertex: v, X: p.format.format(real(z)), Y: p.format.format(imag(z)),
+			Degree: len(adj[v])}
+		if p.names != nil {
+			table.Vertex[v].Name = p.name(v)
+		}
+	}
+	var longest, total float64
+	for i, v := range p.order {
+		table.Vertex[v].Order = i + 1
+		e := p.mst[v]
+		if e == nil {
+			continue
+		}
+		d := p.weight(e.v, e.w)
+		table.Vertex[v].Parent = label(e.v)
+		table.Edge = append(table.Edge, TableEdge{Order: len(table.Edge) + 1, From: label(e.v), To: label(e.w),
+			Length: p.format.format(d)})
+		total += d
+		if d > longest {
+			longest = d
+		}
+	}
+	table.Reached = len(p.order)
+	table.Longest = p.format.format(longest)
+	if len(table.Edge) > 0 {
+		table.MeanEdge = p.format.format(total / float64(len(table.Edge)))
+	}
+
+	p.meta.timePhase("table", start)
+	table.Meta = p.meta
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	return tmplTable.Execute(w, table)
+}
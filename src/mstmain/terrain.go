@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	metricTerrain  = "Terrain" // 3D distance over the elevation surface
+	terrainRaster  = "raster"  // surface read from an uploaded CSV raster
+	terrainSamples = 32        // elevation samples along an edge across the whole graph diagonal
+	maxRasterSize  = 500       // maximum rows and columns of an uploaded raster
+	terrainBands   = 10        // number of elevation shading bands
+	defaultRelief  = 0.25      // default relief as a fraction of the x extent
+)
+
+// terrainSurfaces are the built-in elevation surfaces, then the uploaded raster
+var terrainSurfaces = []string{"hills", "ridge", "bowl", terrainRaster}
+
+// Terrain is the elevation surface over the graph endpoints, saved with the graph state
+type Terrain struct {
+	Surface string      `json:"surface"`          // built-in surface name or raster
+	Relief  float64     `json:"relief"`           // elevation range of a built-in surface
+	Raster  [][]float64 `json:"raster,omitempty"` // elevations, first row at ymax, stretched over the endpoints
+}
+
+// String formats the terrain for the html template
+func (t *Terrain) String() string {
+	if t.Surface == terrainRaster {
+		return fmt.Sprintf("%d x %d raster surface", len(t.Raster), len(t.Raster[0]))
+	}
+	return fmt.Sprintf("%s surface, relief %g", t.Surface, t.Relief)
+}
+
+// hills are the centers, radii, and heights of the hills surface in unit coordinates
+var hills = []struct{ u, v, r, h float64 }{
+	{.25, .3, .15, 1}, {.7, .65, .2, .8}, {.6, .2, .1, .6}, {.2, .8, .12, .5}, {.85, .9, .08, .7},
+}
+
+// elevation returns the terrain elevation at x,y within the endpoints
+func (t *Terrain) elevation(ep Endpoints, x, y float64) float64 {
+	u := (x - ep.xmin) / (ep.xmax - ep.xmin)
+	v := (y - ep.ymin) / (ep.ymax - ep.ymin)
+	switch t.Surface {
+	case "hills":
+		z := 0.0
+		for _, h := range hills {
+			d2 := (u-h.u)*(u-h.u) + (v-h.v)*(v-h.v)
+			z += h.h * math.Exp(-d2/(2*h.r*h.r))
+		}
+		return t.Relief * z
+	case "ridge":
+		return t.Relief * (0.5 + 0.5*math.Sin(2*math.Pi*(u+0.5*v)))
+	case "bowl":
+		return t.Relief * 2 * ((u-.5)*(u-.5) + (v-.5)*(v-.5))
+	}
+	return t.rasterElevation(u, v)
+}
+
+// rasterElevation bilinearly interpolates the raster at unit coordinates u, v
+func (t *Terrain) rasterElevation(u, v float64) float64 {
+	rows, cols := len(t.Raster), len(t.Raster[0])
+	fr := math.Max(0, math.Min(1, 1-v)) * float64(rows-1)
+	fc := math.Max(0, math.Min(1, u)) * float64(cols-1)
+	r0, c0 := int(fr), int(fc)
+	r1, c1 := r0+1, c0+1
+	if r1 == rows {
+		r1 = r0
+	}
+	if c1 == cols {
+		c1 = c0
+	}
+	dr, dc := fr-float64(r0), fc-float64(c0)
+	top := t.Raster[r0][c0]*(1-dc) + t.Raster[r0][c1]*dc
+	bottom := t.Raster[r1][c0]*(1-dc) + t.Raster[r1][c1]*dc
+	return top*(1-dr) + bottom*dr
+}
+
+// parseRaster reads a CSV raster of elevations, one row per line
+func parseRaster(text string) ([][]float64, error) {
+	var raster [][]float64
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(raster) > 0 && len(fields) != len(raster[0]) {
+			return nil, fmt.Errorf("raster line %d has %d values, not %d", i+1, len(fields), len(raster[0]))
+		}
+		row := make([]float64, len(fields))
+		for j, f := range fields {
+			z, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil || math.IsNaN(z) || math.IsInf(z, 0) {
+				return nil, fmt.Errorf("raster line %d value %d is not a number", i+1, j+1)
+			}
+			row[j] = z
+		}
+		raster = append(raster, row)
+		if len(raster) > maxRasterSize || len(row) > maxRasterSize {
+			return nil, fmt.Errorf("raster is larger than %d x %d", maxRasterSize, maxRasterSize)
+		}
+	}
+	if len(raster) < 2 || len(raster[0]) < 2 {
+		return nil, errors.New("raster needs at least 2 rows and 2 columns")
+	}
+	return raster, nil
+}
+
+// formTerrain gets the elevation surface from the HTML form when the metric is Terrain
+func (p *PrimMST) formTerrain(r *http.Request) error {
+	if p.meta.Metric != metricTerrain {
+		return nil
+	}
+	surface, err := formChoice(r, "surface", terrainSurfaces)
+	if err != nil {
+		return err
+	}
+	t := &Terrain{Surface: surface}
+	if t.Relief, err = formFloat(r, "relief", defaultRelief*(p.xmax-p.xmin)); err != nil {
+		return err
+	}
+	if t.Relief < 0 {
+		return fmt.Errorf("relief %g is negative", t.Relief)
+	}
+	if surface == terrainRaster {
+		f, _, err := r.FormFile("rasterfile")
+		if err != nil {
+			return fmt.Errorf("raster surface needs an uploaded CSV file: %v", err)
+		}
+		defer f.Close()
+		b, err := io.ReadAll(io.LimitReader(f, maxUpload))
+		if err != nil {
+			return err
+		}
+		if t.Raster, err = parseRaster(string(b)); err != nil {
+			return err
+		}
+	}
+	p.terrain = t
+	return nil
+}
+
+// terrainDistances finds the 3D length of each edge over the terrain
+func (p *PrimMST) terrainDistances() {
+	n := len(p.location)
+	p.graph = make([][]float64, n)
+	for i := range p.graph {
+		p.graph[i] = make([]float64, n)
+		p.graph[i][i] = math.MaxFloat64
+	}
+	diagonal := math.Hypot(p.xmax-p.xmin, p.ymax-p.ymin)
+	elevations := make([]float64, n)
+	for v, z := range p.location {
+		elevations[v] = p.terrain.elevation(p.Endpoints, real(z), imag(z))
+	}
+	for v := 0; v < n; v++ {
+		for w := v + 1; w < n; w++ {
+			length := p.terrainPath(p.location[v], p.location[w], elevations[v], elevations[w], diagonal)
+			p.graph[v][w] = length
+			p.graph[w][v] = length
+		}
+	}
+}
+
+// terrainLength finds the 3D length of edge v-w over the terrain
+func (p *PrimMST) terrainLength(v, w int) float64 {
+	a, b := p.location[v], p.location[w]
+	return p.terrainPath(a, b, p.terrain.elevation(p.Endpoints, real(a), imag(a)),
+		p.terrain.elevation(p.Endpoints, real(b), imag(b)), math.Hypot(p.xmax-p.xmin, p.ymax-p.ymin))
+}
+
+// terrainPath finds the 3D length of the edge from a at elevation za to b at elevation zb by
+// sampling the elevation along the edge, more often along longer edges
+func (p *PrimMST) terrainPath(a, b complex128, za, zb, diagonal float64) float64 {
+	dx, dy := real(b)-real(a), imag(b)-imag(a)
+	samples := int(math.Ceil(terrainSamples*math.Hypot(dx, dy)/diagonal)) + 1
+	step := math.Hypot(dx, dy) / float64(samples)
+	length := 0.0
+	z0 := za
+	for i := 1; i <= samples; i++ {
+		z1 := zb
+		if i < samples {
+			t := float64(i) / float64(samples)
+			z1 = p.terrain.elevation(p.Endpoints, real(a)+t*dx, imag(a)+t*dy)
+		}
+		length += math.Hypot(step, z1-z0)
+		z0 = z1
+	}
+	return length
+}
+
+// plotTerrain shades the grid cells by elevation band, with contour lines between the
+// bands, as a background that the vertices and edges are drawn over
+func (p *PrimMST) plotTerrain(g *gridPlot) {
+	z := make([]float64, g.rows*g.columns)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for row := 0; row < g.rows; row++ {
+		y := g.ymax - float64(row)/g.yscale
+		for col := 0; col < g.columns; col++ {
+			x := g.xmin + float64(col)/g.xscale
+			e := p.terrain.elevation(p.Endpoints, x, y)
+			z[row*g.columns+col] = e
+			lo, hi = math.Min(lo, e), math.Max(hi, e)
+		}
+	}
+	band := func(i int) int {
+		if hi == lo {
+			return 0
+		}
+		b := int(terrainBands * (z[i] - lo) / (hi - lo))
+		if b == terrainBands {
+			b--
+		}
+		return b
+	}
+	for row := 0; row < g.rows; row++ {
+		for col := 0; col < g.columns; col++ {
+			i := row*g.columns + col
+			b := band(i)
+			class := fmt.Sprintf("elev%d", b)
+			if (col+1 < g.columns && band(i+1) != b) || (row+1 < g.rows && band(i+g.columns) != b) {
+				class = "contour"
+			}
+			g.background(row, col, class)
+		}
+	}
+}
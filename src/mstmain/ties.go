@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+const tieLexicographic = "lexicographic" // tie-break by the vertex order of the edges
+
+// TieReport counts the arbitrary choices between equal-length candidate edges made by Prim's
+// algorithm, and how they were broken
+type TieReport struct {
+	Pops    int    `json:"pops"`    // vertices added while others in the queue had the same distance
+	Parents int    `json:"parents"` // candidate edges as short as the vertex's tree edge, not chosen
+	Breaks  int    `json:"breaks"`  // tie-breaks, pops plus parents
+	Rule    string `json:"rule"`    // priority queue order or lexicographic
+}
+
+// String formats the tie report for the html template
+func (t TieReport) String() string {
+	if t.Breaks == 0 {
+		return "none, no candidate edges had equal lengths"
+	}
+	return fmt.Sprintf("%d broken by %s, %d equal-distance vertices in the queue and %d equal-length edges",
+		t.Breaks, t.Rule, t.Pops, t.Parents)
+}
+
+// newTieReport creates the report of the solver's tie counts
+func newTieReport(ties solver.Ties, lexicographic bool) *TieReport {
+	rule := priorityQueue + " queue order"
+	if lexicographic {
+		rule = "lexicographic vertex order"
+	}
+	return &TieReport{Pops: ties.Pops, Parents: ties.Parents, Breaks: ties.Breaks(), Rule: rule}
+}
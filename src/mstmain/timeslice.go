@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	patternTimeSlice = "/timeslice"               // http handler for the MSTs of time windows
+	fileTimeSlice    = "templates/timeslice.html" // html for the time-sliced MSTs
+	dirTimed         = "timed"                    // stored timestamped datasets in the data directory
+	defaultWindows   = 10                         // windows covering the time span when the width is empty
+	maxWindows       = 200                        // windows of a dataset
+)
+
+// timedVertices are the vertices of a dataset with a timestamp column
+type timedVertices struct {
+	location []complex128
+	time     []float64 // seconds, Unix seconds for RFC 3339 timestamps
+	dates    bool      // the timestamps are RFC 3339 dates
+	rejected int       // lines rejected
+}
+
+// TimeWindow is a row of the time window table
+type TimeWindow struct {
+	Index    int
+	From     string
+	To       string
+	Selected bool // the window shown on the plot
+	Solved   bool // the window has enough vertices for an MST
+	CompareStats
+}
+
+// TimeSliceT contains the time-sliced MST HTML template actions
+type TimeSliceT struct {
+	Xlabel   []string     // x-axis labels shared by all windows
+	Ylabel   []string     // y-axis labels shared by all windows
+	Windows  []TimeWindow // statistics of each window
+	Window   TimeWindow   // window shown on the plot
+	ID       string       // stored dataset
+	Width    string       // window width in seconds
+	Step     string       // seconds between window starts
+	Prev     string       // link to the previous window, empty for the first
+	Next     string       // link to the next window, empty for the last
+	Vertices int          // vertices of the dataset
+	Rejected int          // lines rejected
+	Status   string       // status of the time slicing
+	Theme    string       // page theme
+}
+
+// parseTimedVertices parses the x, y, t lines of the dataset.  The timestamp is seconds or an
+// RFC 3339 date, and all lines must use the same kind.
+func parseTimedVertices(text string) (*timedVertices, error) {
+	tv := &timedVertices{}
+	kinds := 0
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var fields []string
+		if strings.ContainsAny(line, ",;") {
+			fields = strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ';' })
+			for i := range fields {
+				fields[i] = strings.TrimSpace(fields[i])
+			}
+		} else {
+			fields = strings.Fields(line)
+		}
+		if len(fields) < 3 {
+			tv.rejected++
+			continue
+		}
+		x, errx := strconv.ParseFloat(fields[0], 64)
+		y, erry := strconv.ParseFloat(fields[1], 64)
+		if errx != nil || erry != nil || math.IsInf(x, 0) || math.IsInf(y, 0) || math.IsNaN(x) || math.IsNaN(y) {
+			tv.rejected++
+			continue
+		}
+		t, err := strconv.ParseFloat(fields[2], 64)
+		kind := 1
+		if err != nil {
+			date, err := time.Parse(time.RFC3339, fields[2])
+			if err != nil {
+				tv.rejected++
+				continue
+			}
+			t, kind = float64(date.UnixNano())/1e9, 2
+		}
+		if math.IsInf(t, 0) || math.IsNaN(t) {
+			tv.rejected++
+			continue
+		}
+		kinds |= kind
+		tv.location = append(tv.location, complex(x, y))
+		tv.time = append(tv.time, t)
+	}
+	if kinds == 3 {
+		return nil, fmt.Errorf("the timestamps mix seconds and RFC 3339 dates")
+	}
+	if len(tv.location) < minVertices {
+		return nil, fmt.Errorf("the dataset has %d timestamped vertices, fewer than %d", len(tv.location), minVertices)
+	}
+	tv.dates = kinds == 2
+	return tv, nil
+}
+
+// span returns the earliest and latest timestamps
+func (tv *timedVertices) span() (float64, float64) {
+	first, last := tv.time[0], tv.time[0]
+	for _, t := range tv.time {
+		first = math.Min(first, t)
+		last = math.Max(last, t)
+	}
+	return first, last
+}
+
+// slice returns the vertices with timestamps in [from, to)
+func (tv *timedVertices) slice(from, to float64) []complex128 {
+	var location []complex128
+	for i, t := range tv.time {
+		if t >= from && t < to {
+			location = append(location, tv.location[i])
+		}
+	}
+	return location
+}
+
+// formatTime formats the timestamp as a date or as seconds
+func (tv *timedVertices) formatTime(t float64, f labelFormat) string {
+	if tv.dates {
+		sec, frac := math.Modf(t)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC().Format(time.RFC3339)
+	}
+	return f.format(t)
+}
+
+// timedPath returns the file of the stored dataset ID in the data directory
+func timedPath(id string) (string, error) {
+	if !runIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid dataset ID %s", id)
+	}
+	return filepath.Join(dataDir, dirTimed, id+".csv"), nil
+}
+
+// saveTimed stores the dataset text so the windows can be linked, and returns its ID
+func saveTimed(text string) (string, error) {
+	if readOnly {
+		return "", errReadOnly
+	}
+	id := strconv.FormatInt(newSeed(), 36)
+	path, err := timedPath(id)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, text)
+		return err
+	})
+	return id, err
+}
+
+// timedText gets the dataset text from the upload or the text area, or from the stored ID
+func timedText(r *http.Request) (string, string, error) {
+	if id := r.FormValue("id"); len(id) > 0 {
+		path, err := timedPath(id)
+		if err != nil {
+			return "", "", err
+		}
+		b, err := readFileLocked(path)
+		return string(b), id, err
+	}
+	text := r.FormValue("timedtext")
+	if f, _, err := r.FormFile("timed"); err == nil {
+		defer f.Close()
+		b, err := io.ReadAll(io.LimitReader(f, maxUpload))
+		if err != nil {
+			return "", "", err
+		}
+		text = string(b)
+	}
+	if len(strings.TrimSpace(text)) == 0 {
+		return "", "", fmt.Errorf("upload a dataset or enter x, y, t lines")
+	}
+	if len(text) > maxUpload {
+		return "", "", fmt.Errorf("the dataset is larger than %d bytes", maxUpload)
+	}
+	id, err := saveTimed(text)
+	return text, id, err
+}
+
+// windowLink returns the URL of the window of the stored dataset
+func windowLink(id string, width, step float64, window int, theme string) string {
+	v := url.Values{}
+	v.Set("id", id)
+	v.Set("width", strconv.FormatFloat(width, 'g', -1, 64))
+	v.Set("step", strconv.FormatFloat(step, 'g', -1, 64))
+	v.Set("window", strconv.Itoa(window))
+	v.Set("theme", theme)
+	return patternTimeSlice + "?" + v.Encode()
+}
+
+// HTTP handler for /timeslice connections.  A POST uploads a dataset of x, y, t lines, which is
+// stored so a GET with its id can select or step through the windows.
+func handleTimeSlice(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := r.ParseMultipartForm(2 * maxUpload); err != nil && err != http.ErrNotMultipart {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	text, id, err := timedText(r)
+	if err != nil {
+		fmt.Printf("timedText error: %v\n", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tv, err := parseTimedVertices(text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plot := TimeSliceT{ID: id, Vertices: len(tv.location), Rejected: tv.rejected}
+	status := make([]string, 0)
+	plot.Theme, _ = formChoice(r, "theme", themes)
+	format, err := formLabelFormat(r)
+	if err != nil {
+		status = append(status, err.Error())
+	}
+
+	// The windows start at the first timestamp and cover the span, the default width
+	// divides the span into defaultWindows windows
+	first, last := tv.span()
+	def := (last - first) / defaultWindows
+	if def <= 0 {
+		def = 1
+	}
+	width, err := formFloat(r, "width", def)
+	if err != nil || width <= 0 {
+		status = append(status, "window width must be positive")
+		width = def
+	}
+	step, err := formFloat(r, "step", width)
+	if err != nil || step <= 0 {
+		status = append(status, "window step must be positive")
+		step = width
+	}
+	count := maxWindows
+	if n := math.Floor((last-first)/step) + 1; n > maxWindows {
+		status = append(status, fmt.Sprintf("only the first %d of %.0f windows are shown", maxWindows, n))
+	} else {
+		count = int(n)
+	}
+	selected, err := formInt(r, "window", 0)
+	if err != nil || selected < 0 || selected >= count {
+		status = append(status, fmt.Sprintf("window must be in the range 0-%d", count-1))
+		selected = 0
+	}
+	plot.Width = strconv.FormatFloat(width, 'g', -1, 64)
+	plot.Step = strconv.FormatFloat(step, 'g', -1, 64)
+
+	// Each window gets its own MST, the plots of all windows share endpoints fit to the dataset
+	ep := fitEndpoints(tv.location, defaultMargin).equalAspect()
+	g := newGridPlot(ep)
+	plot.Xlabel, plot.Ylabel = g.labels(format)
+	var shown *PrimMST
+	for i := 0; i < count; i++ {
+		from := first + float64(i)*step
+		to := from + width
+		window := TimeWindow{Index: i, From: tv.formatTime(from, format), To: tv.formatTime(to, format),
+			Selected: i == selected}
+		location := tv.slice(from, to)
+		window.Vertices = len(location)
+		if len(location) >= minVertices && len(location) <= maxVertices {
+			window.Solved = true
+			p := compareMST(location, &window.CompareStats, format)
+			if window.Selected {
+				shown = p
+			}
+		}
+		plot.Windows = append(plot.Windows, window)
+	}
+	plot.Window = plot.Windows[selected]
+	if selected > 0 {
+		plot.Prev = windowLink(id, width, step, selected-1, plot.Theme)
+	}
+	if selected < count-1 {
+		plot.Next = windowLink(id, width, step, selected+1, plot.Theme)
+	}
+
+	// The plot shows the MST of the window, or only its vertices when it has too few or too many
+	var grid []string
+	if shown != nil {
+		grid = shown.plotCompare(ep)
+	} else {
+		from := first + float64(selected)*step
+		for _, z := range tv.slice(from, from+width) {
+			g.point(real(z), imag(z), "vertex")
+		}
+		grid = g.grid
+		status = append(status, fmt.Sprintf("window %d has %d vertices, no MST for fewer than %d or more than %d",
+			selected, plot.Window.Vertices, minVertices, maxVertices))
+	}
+	if len(status) > 0 {
+		plot.Status = strings.Join(status, ", ")
+	} else {
+		plot.Status = fmt.Sprintf("%d windows of width %s every %s, the plot shows window %d", count, plot.Width, plot.Step, selected)
+	}
+
+	sw := newStreamWriter(w, r)
+	if err := tmplTimeSlice.ExecuteTemplate(sw, "gridstart", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(grid, columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplTimeSlice.ExecuteTemplate(sw, "gridend", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+	}
+}
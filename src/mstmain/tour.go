@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	patternTour       = "/primmsttour"        // http handler for the simulated annealing tour
+	fileTour          = "templates/tour.html" // html for the simulated annealing tour
+	defaultTourPasses = 200                   // default iterations per vertex
+	maxTourIterations = 20000000              // most annealing iterations per request
+	finalTemperature  = 1e-3                  // default final temperature as a fraction of the initial
+	tourProgressSteps = 20                    // progress lines streamed during the annealing
+	tourNeighbors     = 10                    // nearest neighbors considered by the 2-opt moves
+)
+
+// TourProgress is an intermediate state of the annealing streamed to the page
+type TourProgress struct {
+	Iteration   int    // iterations so far
+	Temperature string // current temperature
+	Current     string // current tour length
+	Best        string // best tour length so far
+}
+
+// TourT contains the simulated annealing tour HTML template actions
+type TourT struct {
+	Xlabel      []string // x-axis labels
+	Ylabel      []string // y-axis labels
+	Iterations  int      // annealing iterations
+	Temperature string   // initial temperature
+	Cooling     string   // geometric cooling factor per iteration
+	Seed        int64    // random number generator seed
+	MST         string   // MST weight
+	Initial     string   // MST preorder tour length
+	Best        string   // annealed tour length
+	Improvement string   // percent shorter than the MST tour
+	Ratio       string   // annealed tour length over the MST weight, a lower bound is 1
+	Accepted    int      // moves accepted
+	Runtime     string   // annealing time
+	Status      string   // status of the annealing
+	Partial     string   // why the annealing stopped early, empty when it finished
+	Theme       string   // page theme
+}
+
+// preorderTour visits the vertices in preorder of the MST rooted at the start vertex.
+// Shortcutting the doubled tree makes a tour at most twice the MST weight in a metric graph.
+func (p *PrimMST) preorderTour() []int {
+	n := len(p.location)
+	children := make([][]int, n)
+	for _, v := range p.order {
+		if e := p.mst[v]; e != nil {
+			children[e.v] = append(children[e.v], e.w)
+		}
+	}
+	tour := make([]int, 0, n)
+	stack := []int{p.start}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		tour = append(tour, v)
+		for i := len(children[v]) - 1; i >= 0; i-- {
+			stack = append(stack, children[v][i])
+		}
+	}
+	return tour
+}
+
+// tourLength returns the length of the closed tour
+func (p *PrimMST) tourLength(tour []int) float64 {
+	var length float64
+	for i, v := range tour {
+		length += p.weight(v, tour[(i+1)%len(tour)])
+	}
+	return length
+}
+
+// anneal refines the tour by simulated annealing with 2-opt moves, cooling geometrically
+// from temperature t0, and calls progress tourProgressSteps times.  Each move joins a
+// random vertex to one of its tourNeighbors nearest neighbors.  It returns the best tour
+// found and the number of accepted moves.
+func (p *PrimMST) anneal(tour []int, iterations int, t0, cooling float64, rnd *rand.Rand,
+	progress func(TourProgress) error) ([]int, int, error) {
+	n := len(tour)
+	current := p.tourLength(tour)
+	best := append([]int(nil), tour...)
+	bestLength := current
+	accepted := 0
+	if n < 4 {
+		return best, accepted, nil
+	}
+	d := p.graph
+	neighbors := p.nearestK(tourNeighbors)
+	pos := make([]int, n)
+	for i, v := range tour {
+		pos[v] = i
+	}
+	// reverse reverses tour[l..r] and updates the positions
+	reverse := func(l, r int) {
+		for ; l < r; l, r = l+1, r-1 {
+			tour[l], tour[r] = tour[r], tour[l]
+			pos[tour[l]], pos[tour[r]] = l, r
+		}
+	}
+
+	temperature := t0
+	step := iterations / tourProgressSteps
+	if step == 0 {
+		step = 1
+	}
+	for it := 1; it <= iterations; it++ {
+		// Replace the edges a-b and c-e with a-c and b-e
+		i := rnd.Intn(n)
+		a, b := tour[i], tour[(i+1)%n]
+		c := neighbors[a][rnd.Intn(len(neighbors[a]))]
+		j := pos[c]
+		e := tour[(j+1)%n]
+		if c != b && e != a {
+			delta := d[a][c] + d[b][e] - d[a][b] - d[c][e]
+			if delta < 0 || rnd.Float64() < math.Exp(-delta/temperature) {
+				// Reversing b..c or its complement e..a makes the same cycle
+				if i < j {
+					reverse(i+1, j)
+				} else {
+					reverse(j+1, i)
+				}
+				current += delta
+				accepted++
+				if current < bestLength-1e-9 {
+					bestLength = current
+					copy(best, tour)
+				}
+			}
+		}
+		temperature *= cooling
+
+		if it%step == 0 {
+			err := progress(TourProgress{
+				Iteration:   it,
+				Temperature: fmt.Sprintf("%.4g", temperature),
+				Current:     fmt.Sprintf("%.2f", current),
+				Best:        fmt.Sprintf("%.2f", bestLength),
+			})
+			if err != nil {
+				return best, accepted, err
+			}
+		}
+	}
+	return best, accepted, nil
+}
+
+// nearestK returns the k nearest neighbors of each vertex
+func (p *PrimMST) nearestK(k int) [][]int {
+	n := len(p.location)
+	if k > n-1 {
+		k = n - 1
+	}
+	neighbors := make([][]int, n)
+	order := make([]int, n)
+	for v := 0; v < n; v++ {
+		for i := range order {
+			order[i] = i
+		}
+		// p.graph[v][v] is MaxFloat64 so v sorts last
+		row := p.graph[v]
+		sort.Slice(order, func(i, j int) bool { return row[order[i]] < row[order[j]] })
+		neighbors[v] = append([]int(nil), order[:k]...)
+	}
+	return neighbors
+}
+
+// plotTour draws the tour over the faint MST
+func (p *PrimMST) plotTour(ep Endpoints, tour []int) []string {
+	g := newGridPlot(ep)
+	for _, e := range p.mst {
+		if e != nil {
+			a, b := p.location[e.v], p.location[e.w]
+			g.line(real(a), imag(a), real(b), imag(b), "edge")
+		}
+	}
+	for i, v := range tour {
+		a, b := p.location[v], p.location[tour[(i+1)%len(tour)]]
+		g.line(real(a), imag(a), real(b), imag(b), "tour")
+	}
+	for v, z := range p.location {
+		class := "vertex"
+		if v == p.start {
+			class = "startvertex"
+		}
+		g.point(real(z), imag(z), class)
+	}
+	return g.grid
+}
+
+// formTourSchedule gets the annealing schedule from the query, defaulting to
+// defaultTourPasses iterations per vertex from the mean MST edge length down to
+// finalTemperature of it
+func (p *PrimMST) formTourSchedule(r *http.Request) (int, float64, float64, error) {
+	n := len(p.location)
+	iterations := defaultTourPasses * n
+	if str := r.FormValue("iterations"); len(str) > 0 {
+		var err error
+		if iterations, err = strconv.Atoi(str); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	if iterations < 1 || iterations > maxTourIterations {
+		return 0, 0, 0, fmt.Errorf("iterations %d is not in the range 1-%d", iterations, maxTourIterations)
+	}
+	t0, err := formFloat(r, "temperature", p.totalDistance()/float64(n-1))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if t0 <= 0 {
+		return 0, 0, 0, fmt.Errorf("temperature %g is not positive", t0)
+	}
+	cooling, err := formFloat(r, "cooling", math.Pow(finalTemperature, 1/float64(iterations)))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if cooling <= 0 || cooling > 1 {
+		return 0, 0, 0, fmt.Errorf("cooling %g is not in the range (0, 1]", cooling)
+	}
+	return iterations, t0, cooling, nil
+}
+
+// HTTP handler for /primmsttour connections.  The optional iterations, temperature,
+// cooling, and seed query parameters set the annealing schedule.
+func handleTour(w http.ResponseWriter, r *http.Request) {
+	p := latestMST()
+	if p == nil || len(p.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	p = p.withMatrix()
+	iterations, t0, cooling, err := p.formTourSchedule(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seed := newSeed()
+	if str := r.FormValue("seed"); len(str) > 0 {
+		if seed, err = strconv.ParseInt(str, 10, 64); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	ctx, cancel, err := computeContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer cancel()
+
+	tour := p.preorderTour()
+	mst := p.totalDistance()
+	initial := p.tourLength(tour)
+	plot := TourT{
+		Iterations:  iterations,
+		Temperature: fmt.Sprintf("%.4g", t0),
+		Cooling:     strconv.FormatFloat(cooling, 'g', -1, 64),
+		Seed:        seed,
+		MST:         p.format.format(mst),
+		Initial:     p.format.format(initial),
+		Theme:       p.theme,
+	}
+	ep := p.plotEndpoints()
+	plot.Xlabel, plot.Ylabel = newGridPlot(ep).labels(p.format)
+
+	// Stream the progress of the annealing, then the best tour
+	sw := newStreamWriter(w, r)
+	if err := tmplTour.ExecuteTemplate(sw, "start", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	sw.Flush()
+	start := time.Now()
+	done := 0
+	best, accepted, err := p.anneal(tour, iterations, t0, cooling, rand.New(rand.NewSource(seed)),
+		func(tp TourProgress) error {
+			// Stop at a progress step when cancelled, keeping the best tour so far
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			done = tp.Iteration
+			if err := tmplTour.ExecuteTemplate(sw, "progress", tp); err != nil {
+				return err
+			}
+			return sw.Flush()
+		})
+	if err != nil && ctx.Err() == nil {
+		fmt.Printf("anneal error: %v\n", err)
+		return
+	}
+	if ctx.Err() != nil {
+		plot.Partial = fmt.Sprintf("Partial result: stopped after %d of %d iterations", done, iterations)
+	}
+	length := p.tourLength(best)
+	plot.Runtime = time.Since(start).Round(time.Millisecond).String()
+	plot.Best = p.format.format(length)
+	plot.Improvement = fmt.Sprintf("%.1f%%", 100*(initial-length)/initial)
+	plot.Ratio = fmt.Sprintf("%.3f", length/mst)
+	plot.Accepted = accepted
+	plot.Status = fmt.Sprintf("Simulated annealing, seed %d: the tour is %s shorter than the MST preorder tour",
+		seed, plot.Improvement)
+
+	if err := tmplTour.ExecuteTemplate(sw, "gridstart", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(p.plotTour(ep, best), columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplTour.ExecuteTemplate(sw, "gridend", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import "fmt"
+
+// adjacency returns the neighbors of each vertex in the MST
+func (p *PrimMST) adjacency() [][]int {
+	adj := make([][]int, len(p.location))
+	for _, e := range p.mst {
+		if e != nil {
+			adj[e.v] = append(adj[e.v], e.w)
+			adj[e.w] = append(adj[e.w], e.v)
+		}
+	}
+	return adj
+}
+
+// depths returns the number of MST edges from the start vertex to each vertex
+func (p *PrimMST) depths() []int {
+	adj := p.adjacency()
+	depth := make([]int, len(p.location))
+	for i := range depth {
+		depth[i] = -1
+	}
+	depth[p.start] = 0
+	queue := []int{p.start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range adj[v] {
+			if depth[w] < 0 {
+				depth[w] = depth[v] + 1
+				queue = append(queue, w)
+			}
+		}
+	}
+	return depth
+}
+
+// Bipartition is the natural 2-coloring of the MST by even and odd depth
+type Bipartition struct {
+	Even int // number of vertices at even depth, including the start vertex
+	Odd  int // number of vertices at odd depth
+}
+
+// String formats the bipartition for the html template
+func (b Bipartition) String() string {
+	return fmt.Sprintf("even depth %d, odd depth %d", b.Even, b.Odd)
+}
+
+// bipartition colors the vertices by the parity of their depth in the MST
+func (p *PrimMST) bipartition() Bipartition {
+	var b Bipartition
+	p.vertexClass = make([]string, len(p.location))
+	for i, d := range p.depths() {
+		if d%2 == 0 {
+			p.vertexClass[i] = "evenvertex"
+			b.Even++
+		} else {
+			p.vertexClass[i] = "oddvertex"
+			b.Odd++
+		}
+	}
+	return b
+}
+
+// classOf returns the CSS class of vertex v, black unless a vertex coloring was applied
+func (p *PrimMST) classOf(v int) string {
+	if p.vertexClass != nil && len(p.vertexClass[v]) > 0 {
+		return p.vertexClass[v]
+	}
+	return "vertex"
+}
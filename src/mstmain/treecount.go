@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+)
+
+const (
+	exactTreeCount = 100 // largest graph whose spanning trees are counted exactly
+	maxTreeDigits  = 30  // larger counts are shown in scientific notation
+)
+
+// formTreeCount gets the spanning tree count checkbox and the edge threshold from the HTML form
+func (p *PrimMST) formTreeCount(r *http.Request) error {
+	if len(r.FormValue("treecount")) == 0 {
+		return nil
+	}
+	threshold, err := formFloat(r, "treethreshold", 0)
+	if err != nil {
+		return err
+	}
+	if threshold < 0 {
+		return fmt.Errorf("spanning tree threshold %g is negative", threshold)
+	}
+	p.treeThreshold = &threshold
+	return nil
+}
+
+// laplacian returns the Laplacian matrix of the graph with the edges no longer than
+// threshold, or of the complete graph when threshold is 0
+func (p *PrimMST) laplacian(threshold float64) [][]int64 {
+	n := len(p.location)
+	l := make([][]int64, n)
+	for i := range l {
+		l[i] = make([]int64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if threshold == 0 || p.weight(i, j) <= threshold {
+				l[i][j], l[j][i] = -1, -1
+				l[i][i]++
+				l[j][j]++
+			}
+		}
+	}
+	return l
+}
+
+// exactDeterminant returns the determinant of the integer matrix using Bareiss elimination
+func exactDeterminant(m [][]int64) *big.Int {
+	n := len(m)
+	a := make([][]*big.Int, n)
+	for i := range m {
+		a[i] = make([]*big.Int, n)
+		for j := range m[i] {
+			a[i][j] = big.NewInt(m[i][j])
+		}
+	}
+	sign := 1
+	prev := big.NewInt(1)
+	t := new(big.Int)
+	for k := 0; k < n; k++ {
+		// Swap in a row with a nonzero pivot
+		if a[k][k].Sign() == 0 {
+			i := k + 1
+			for i < n && a[i][k].Sign() == 0 {
+				i++
+			}
+			if i == n {
+				return new(big.Int)
+			}
+			a[k], a[i] = a[i], a[k]
+			sign = -sign
+		}
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j < n; j++ {
+				a[i][j].Mul(a[i][j], a[k][k])
+				t.Mul(a[i][k], a[k][j])
+				a[i][j].Sub(a[i][j], t)
+				a[i][j].Quo(a[i][j], prev)
+			}
+		}
+		prev = a[k][k]
+	}
+	if n == 0 {
+		return big.NewInt(1)
+	}
+	det := new(big.Int).Set(a[n-1][n-1])
+	if sign < 0 {
+		det.Neg(det)
+	}
+	return det
+}
+
+// log10Determinant returns log10 of the absolute determinant using LU decomposition
+// with partial pivoting, -Inf if the matrix is singular
+func log10Determinant(m [][]int64) float64 {
+	n := len(m)
+	a := make([][]float64, n)
+	for i := range m {
+		a[i] = make([]float64, n)
+		for j := range m[i] {
+			a[i][j] = float64(m[i][j])
+		}
+	}
+	logdet := 0.0
+	for k := 0; k < n; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[pivot][k]) {
+				pivot = i
+			}
+		}
+		if math.Abs(a[pivot][k]) < 1e-9 {
+			return math.Inf(-1)
+		}
+		a[k], a[pivot] = a[pivot], a[k]
+		for i := k + 1; i < n; i++ {
+			f := a[i][k] / a[k][k]
+			for j := k + 1; j < n; j++ {
+				a[i][j] -= f * a[k][j]
+			}
+		}
+		logdet += math.Log10(math.Abs(a[k][k]))
+	}
+	return logdet
+}
+
+// treeCount returns the number of spanning trees of the graph with the edges no longer
+// than threshold using Kirchhoff's matrix-tree theorem: any cofactor of the Laplacian
+func (p *PrimMST) treeCount(threshold float64) string {
+	n := len(p.location)
+	// Cayley's formula n^(n-2) for the complete graph
+	if threshold == 0 {
+		count := new(big.Int).Exp(big.NewInt(int64(n)), big.NewInt(int64(n-2)), nil)
+		return formatCount(count)
+	}
+
+	// Remove the first row and column of the Laplacian
+	l := p.laplacian(threshold)
+	minor := make([][]int64, n-1)
+	for i := range minor {
+		minor[i] = l[i+1][1:]
+	}
+	if n <= exactTreeCount {
+		count := exactDeterminant(minor)
+		return formatCount(count)
+	}
+	logdet := log10Determinant(minor)
+	if math.IsInf(logdet, -1) {
+		return "0"
+	}
+	return formatLog10(logdet)
+}
+
+// formatCount formats the count exactly, or in scientific notation when it has too many digits
+func formatCount(count *big.Int) string {
+	s := count.String()
+	if len(s) <= maxTreeDigits {
+		return s
+	}
+	return fmt.Sprintf("%s.%se%d", s[:1], s[1:4], len(s)-1)
+}
+
+// formatLog10 formats 10^log10 in scientific notation
+func formatLog10(log10 float64) string {
+	exponent := math.Floor(log10)
+	return fmt.Sprintf("%.3fe%d", math.Pow(10, log10-exponent), int(exponent))
+}
+
+// treeCountString describes the spanning tree count of the graph
+func (p *PrimMST) treeCountString() string {
+	threshold := *p.treeThreshold
+	graph := "complete graph"
+	if threshold > 0 {
+		graph = fmt.Sprintf("edges up to %g", threshold)
+	}
+	return fmt.Sprintf("%s spanning trees (%s)", p.treeCount(threshold), graph)
+}
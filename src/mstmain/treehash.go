@@ -0,0 +1,37 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// treeHash returns the canonical hash of the MST, a hash of the sorted multiset of its edges
+// by the locations of their endpoints.  It does not depend on the order Prim added the edges,
+// their direction from the start vertex, or the numbering of the vertices, so two runs,
+// machines, or algorithms found the same tree when the hashes are equal.
+func (p *PrimMST) treeHash() string {
+	point := func(z complex128) string {
+		// Adding zero turns -0 into 0
+		return strconv.FormatFloat(real(z)+0, 'g', -1, 64) + "," + strconv.FormatFloat(imag(z)+0, 'g', -1, 64)
+	}
+	edges := make([]string, 0, len(p.location)-1)
+	for _, e := range p.mst {
+		if e == nil {
+			continue
+		}
+		a, b := point(p.location[e.v]), point(p.location[e.w])
+		if b < a {
+			a, b = b, a
+		}
+		edges = append(edges, a+" "+b)
+	}
+	if len(edges) == 0 {
+		return ""
+	}
+	sort.Strings(edges)
+	sum := sha256.Sum256([]byte(strings.Join(edges, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
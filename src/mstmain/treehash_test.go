@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTreeHash(t *testing.T) {
+	// path 0-1-2 of the points a, b, c
+	a, b, c := complex(0, 0), complex(3, 4), complex(6, 0)
+	path := &PrimMST{location: []complex128{a, b, c}, mst: MST{nil, {0, 1}, {1, 2}}}
+	tests := []struct {
+		name string
+		p    *PrimMST
+		same bool // the hash equals the hash of path
+	}{
+		{"same tree", &PrimMST{location: []complex128{a, b, c}, mst: MST{nil, {0, 1}, {1, 2}}}, true},
+		{"reversed edges", &PrimMST{location: []complex128{a, b, c}, mst: MST{{1, 0}, {2, 1}, nil}}, true},
+		{"renumbered vertices", &PrimMST{location: []complex128{c, a, b}, mst: MST{nil, {1, 2}, {2, 0}}}, true},
+		{"negative zero", &PrimMST{location: []complex128{complex(0, math.Copysign(0, -1)), b, c}, mst: MST{nil, {0, 1}, {1, 2}}}, true},
+		{"other tree", &PrimMST{location: []complex128{a, b, c}, mst: MST{nil, {0, 1}, {0, 2}}}, false},
+		{"moved vertex", &PrimMST{location: []complex128{a, b, complex(6, 1)}, mst: MST{nil, {0, 1}, {1, 2}}}, false},
+	}
+	want := path.treeHash()
+	if len(want) != 16 {
+		t.Fatalf("hash %q is not 16 hex digits", want)
+	}
+	for _, tt := range tests {
+		if got := tt.p.treeHash(); (got == want) != tt.same {
+			t.Errorf("%s: hash %s, path hash %s, want equal %v", tt.name, got, want, tt.same)
+		}
+	}
+	if got := (&PrimMST{location: []complex128{a}, mst: MST{nil}}).treeHash(); got != "" {
+		t.Errorf("hash of a tree without edges is %q, want empty", got)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// vertexGrid buckets the vertices into square cells of about two vertices each, so that the
+// nearest vertices of a vertex are found by searching the cells in rings around it instead of
+// measuring the distance to every vertex
+type vertexGrid struct {
+	location      []complex128
+	x0, y0        float64 // lower left corner of the vertices' bounding box
+	size          float64 // cell side
+	columns, rows int
+	cells         [][]int // vertices of each cell, row major
+	scanned       int     // distances measured by the searches
+}
+
+// neighbor is a vertex found by a grid search and its Euclidean distance
+type neighbor struct {
+	w        int
+	distance float64
+}
+
+func newVertexGrid(location []complex128) *vertexGrid {
+	g := &vertexGrid{location: location, columns: 1, rows: 1, size: 1}
+	n := len(location)
+	if n == 0 {
+		g.cells = make([][]int, 1)
+		return g
+	}
+	x1, y1 := real(location[0]), imag(location[0])
+	g.x0, g.y0 = x1, y1
+	for _, z := range location {
+		g.x0, g.y0 = math.Min(g.x0, real(z)), math.Min(g.y0, imag(z))
+		x1, y1 = math.Max(x1, real(z)), math.Max(y1, imag(z))
+	}
+	// The cells of side √(2wh/n) hold two vertices on average, and at least (w+h)/n
+	// keeps the cell count below 2n when the vertices are on a line
+	w, h := x1-g.x0, y1-g.y0
+	if size := math.Max(math.Sqrt(2*w*h/float64(n)), (w+h)/float64(n)); size > 0 {
+		g.size = size
+		g.columns, g.rows = int(w/size)+1, int(h/size)+1
+	}
+	g.cells = make([][]int, g.columns*g.rows)
+	for v, z := range location {
+		c, r := g.cell(z)
+		g.cells[r*g.columns+c] = append(g.cells[r*g.columns+c], v)
+	}
+	return g
+}
+
+// cell returns the column and row of the cell containing z
+func (g *vertexGrid) cell(z complex128) (int, int) {
+	clamp := func(i, n int) int {
+		if i < 0 {
+			return 0
+		}
+		if i >= n {
+			return n - 1
+		}
+		return i
+	}
+	return clamp(int((real(z)-g.x0)/g.size), g.columns), clamp(int((imag(z)-g.y0)/g.size), g.rows)
+}
+
+// nearest returns the k vertices nearest to vertex v and closer than within, nearest first,
+// other than v and the vertices that skip reports, or fewer if there are not k such vertices
+func (g *vertexGrid) nearest(v, k int, within float64, skip func(w int) bool) []neighbor {
+	best := make([]neighbor, 0, k)
+	if k < 1 {
+		return best
+	}
+	z := g.location[v]
+	c0, r0 := g.cell(z)
+	for ring := 0; ring <= g.columns || ring <= g.rows; ring++ {
+		// Every vertex outside the rings searched so far is farther than ring-1 cells
+		if len(best) == k && best[k-1].distance <= float64(ring-1)*g.size || within <= float64(ring-1)*g.size {
+			break
+		}
+		for r := r0 - ring; r <= r0+ring; r++ {
+			if r < 0 || r >= g.rows {
+				continue
+			}
+			// Only the first and last rows of the ring are whole, the rest are its two ends
+			step := 2 * ring
+			if r == r0-ring || r == r0+ring || ring == 0 {
+				step = 1
+			}
+			for c := c0 - ring; c <= c0+ring; c += step {
+				if c < 0 || c >= g.columns {
+					continue
+				}
+				for _, w := range g.cells[r*g.columns+c] {
+					if w == v || skip != nil && skip(w) {
+						continue
+					}
+					g.scanned++
+					d := cmplx.Abs(g.location[w] - z)
+					if d >= within || len(best) == k && d >= best[k-1].distance {
+						continue
+					}
+					if len(best) < k {
+						best = append(best, neighbor{})
+					}
+					i := len(best) - 1
+					for ; i > 0 && best[i-1].distance > d; i-- {
+						best[i] = best[i-1]
+					}
+					best[i] = neighbor{w: w, distance: d}
+				}
+			}
+		}
+	}
+	return best
+}
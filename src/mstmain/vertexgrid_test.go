@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestVertexGridNearest checks the grid search against sorting every distance, for random,
+// collinear, and coincident vertices
+func TestVertexGridNearest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	random := make([]complex128, 200)
+	line := make([]complex128, 50)
+	same := make([]complex128, 10)
+	for i := range random {
+		random[i] = complex(100*rnd.Float64(), 10*rnd.Float64())
+	}
+	for i := range line {
+		line[i] = complex(rnd.Float64(), 0)
+	}
+	for i := range same {
+		same[i] = complex(3, 4)
+	}
+	tests := []struct {
+		name     string
+		location []complex128
+		k        int
+		within   float64
+	}{
+		{"random", random, 1, math.Inf(1)},
+		{"random k 8", random, 8, math.Inf(1)},
+		{"random within 2", random, 8, 2},
+		{"line", line, 5, math.Inf(1)},
+		{"coincident", same, 3, math.Inf(1)},
+		{"more than n", same, 20, math.Inf(1)},
+		{"one vertex", same[:1], 1, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		g := newVertexGrid(tt.location)
+		for v := range tt.location {
+			// Skip the even vertices to check the filter
+			skip := func(w int) bool { return w%2 == 0 }
+			var want []float64
+			for w, z := range tt.location {
+				if d := cmplx.Abs(z - tt.location[v]); w != v && !skip(w) && d < tt.within {
+					want = append(want, d)
+				}
+			}
+			sort.Float64s(want)
+			if len(want) > tt.k {
+				want = want[:tt.k]
+			}
+			got := g.nearest(v, tt.k, tt.within, skip)
+			if len(got) != len(want) {
+				t.Fatalf("%s: vertex %d has %d neighbors, want %d", tt.name, v, len(got), len(want))
+			}
+			for i, nb := range got {
+				if nb.distance != want[i] || skip(nb.w) || nb.w == v {
+					t.Errorf("%s: vertex %d neighbor %d is %d at %g, want distance %g", tt.name, v, i, nb.w, nb.distance, want[i])
+				}
+			}
+		}
+	}
+}
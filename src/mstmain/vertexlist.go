@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultMargin = 5.0 // default auto-fit margin, percent of the vertex extent on each side
+
+// vertexList is a parsed vertex list
+type vertexList struct {
+	location []complex128 // vertices inside the endpoints
+	names    []string     // vertex names, empty when unnamed
+	rejected int          // lines rejected, including NaN and infinite coordinates
+	units    string       // coordinate units declared in the header row, empty when undeclared
+}
+
+// vertexColumns are the x, y, and name field indexes of the vertex list lines, from the
+// header row or else x,y,name.  name is -1 when the header has no name column.
+type vertexColumns struct {
+	x, y, name int
+	fields     int // fields of a line, the name takes the rest of a whitespace separated line
+}
+
+// defaultColumns are the columns of a vertex list without a header row
+var defaultColumns = vertexColumns{x: 0, y: 1, name: 2, fields: 3}
+
+// columnPattern matches a header column name with optional units, x[km] or x (km)
+var columnPattern = regexp.MustCompile(`^([A-Za-z_]+)\s*(?:\[([^\]]*)\]|\(([^)]*)\))?$`)
+
+// columnRoles are the header column names of the vertex coordinates and names
+var columnRoles = map[string]string{
+	"x": "x", "lon": "x", "lng": "x", "longitude": "x", "easting": "x",
+	"y": "y", "lat": "y", "latitude": "y", "northing": "y",
+	"name": "name", "label": "name", "id": "name",
+}
+
+// splitFields splits the line into fields separated by commas or semicolons, so that names
+// can have spaces, or else by whitespace, joining the fields past the last one
+func splitFields(line string, n int) []string {
+	if strings.ContainsAny(line, ",;") {
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ';' })
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		return fields
+	}
+	fields := strings.Fields(line)
+	if len(fields) > n {
+		fields = append(fields[:n-1], strings.Join(fields[n-1:], " "))
+	}
+	return fields
+}
+
+// parseHeader returns the columns and units of the header row, and false if the line is
+// not a header because none of its fields name a column.  The x and y units must agree.
+func parseHeader(line string) (vertexColumns, string, bool, error) {
+	cols := vertexColumns{x: -1, y: -1, name: -1}
+	fields := splitFields(line, math.MaxInt32)
+	cols.fields = len(fields)
+	var units [2]string
+	found := false
+	for i, f := range fields {
+		m := columnPattern.FindStringSubmatch(f)
+		if m == nil {
+			continue
+		}
+		role, ok := columnRoles[strings.ToLower(m[1])]
+		if !ok {
+			continue
+		}
+		found = true
+		unit := strings.TrimSpace(m[2] + m[3])
+		switch role {
+		case "x":
+			cols.x, units[0] = i, unit
+		case "y":
+			cols.y, units[1] = i, unit
+		case "name":
+			cols.name = i
+		}
+	}
+	if !found {
+		return cols, "", false, nil
+	}
+	if cols.x < 0 || cols.y < 0 {
+		return cols, "", true, fmt.Errorf("vertex list header %q needs x and y columns", line)
+	}
+	if len(units[0]) > 0 && len(units[1]) > 0 && units[0] != units[1] {
+		return cols, "", true, fmt.Errorf("vertex list header has x in %s and y in %s, the units must agree", units[0], units[1])
+	}
+	unit := units[0]
+	if len(unit) == 0 {
+		unit = units[1]
+	}
+	return cols, unit, true, nil
+}
+
+// parseVertexList parses vertices pasted as "x,y" or "x,y,name" lines.  The fields can be
+// separated by commas or semicolons, so that names can have spaces, or else by whitespace.
+// Blank lines and lines starting with # are skipped.  An optional header row before the
+// vertices names the columns in any order, with the coordinate units in brackets or
+// parentheses: x[km], y[km], name.
+func parseVertexList(text string, ep Endpoints) (vertexList, error) {
+	var list vertexList
+	cols := defaultColumns
+	first := true
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// The first line is the header if it names columns
+		if first {
+			first = false
+			h, units, ok, err := parseHeader(line)
+			if err != nil {
+				return list, err
+			}
+			if ok {
+				cols, list.units = h, units
+				continue
+			}
+		}
+
+		z, name, ok := cols.parseLine(line, ep)
+		if !ok {
+			list.rejected++
+			continue
+		}
+		list.location = append(list.location, z)
+		list.names = append(list.names, name)
+	}
+	return list, nil
+}
+
+// parseLine returns the vertex and name of the vertex list line, and false if the line does
+// not have the columns or the vertex is not inside the endpoints
+func (cols vertexColumns) parseLine(line string, ep Endpoints) (complex128, string, bool) {
+	fields := splitFields(line, cols.fields)
+	// The name is optional when it is the last column
+	if len(fields) != cols.fields && !(cols.name == cols.fields-1 && len(fields) == cols.fields-1) {
+		return 0, "", false
+	}
+	x, errx := strconv.ParseFloat(fields[cols.x], 64)
+	y, erry := strconv.ParseFloat(fields[cols.y], 64)
+	if errx != nil || erry != nil || !(x >= ep.xmin && x <= ep.xmax && y >= ep.ymin && y <= ep.ymax) ||
+		math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return 0, "", false
+	}
+	name := ""
+	if cols.name >= 0 && cols.name < len(fields) {
+		name = fields[cols.name]
+	}
+	return complex(x, y), name, true
+}
+
+// distanceUnits returns the units of the edge distances: km for the Haversine metric, the
+// declared coordinate units for Euclidean and Terrain distances, and none for the weights
+// of the other metrics
+func (p *PrimMST) distanceUnits() string {
+	switch p.meta.Metric {
+	case metricHaversine:
+		return "km"
+	case metrics[0], metricTerrain:
+		return p.meta.Units
+	}
+	return ""
+}
+
+// namesOrNil returns nil if none of the vertices have names
+func namesOrNil(names []string) []string {
+	for _, name := range names {
+		if len(name) > 0 {
+			return names
+		}
+	}
+	return nil
+}
+
+// name returns the name of vertex v, or v<index> if it has none
+func (p *PrimMST) name(v int) string {
+	if p.names != nil && len(p.names[v]) > 0 {
+		return p.names[v]
+	}
+	return "v" + strconv.Itoa(v)
+}
+
+// formAutoFit gets the auto-fit checkbox and margin for the pasted vertex list from the HTML form
+func (p *PrimMST) formAutoFit(r *http.Request) error {
+	p.autofit = len(r.FormValue("autofit")) > 0
+	var err error
+	if p.margin, err = formFloat(r, "margin", defaultMargin); err != nil {
+		return err
+	}
+	if p.margin < 0 {
+		return fmt.Errorf("auto-fit margin %g%% is negative", p.margin)
+	}
+	return nil
+}
+
+// fitEndpoints returns the endpoints containing the vertices with a margin percent
+// of their extent on each side.  A zero extent gets a margin of one unit.
+func fitEndpoints(location []complex128, margin float64) Endpoints {
+	ep := Endpoints{xmin: math.Inf(1), xmax: math.Inf(-1), ymin: math.Inf(1), ymax: math.Inf(-1)}
+	for _, z := range location {
+		ep.xmin = math.Min(ep.xmin, real(z))
+		ep.xmax = math.Max(ep.xmax, real(z))
+		ep.ymin = math.Min(ep.ymin, imag(z))
+		ep.ymax = math.Max(ep.ymax, imag(z))
+	}
+	pad := func(lo, hi float64) (float64, float64) {
+		d := (hi - lo) * margin / 100
+		if d == 0 {
+			d = 1
+		}
+		return lo - d, hi + d
+	}
+	ep.xmin, ep.xmax = pad(ep.xmin, ep.xmax)
+	ep.ymin, ep.ymax = pad(ep.ymin, ep.ymax)
+	return ep
+}
+
+// pastedVertices sets the vertex locations from the pasted vertex list, fitting the
+// endpoints to the vertices when auto-fit is checked.  A projected list has lon,lat lines
+// and always fits the endpoints to the projected vertices.
+func (p *PrimMST) pastedVertices(text string) error {
+	bounds := p.Endpoints
+	projected := len(p.projection) > 0 && p.projection != projections[0]
+	if projected {
+		bounds = lonLatBounds(p.projection)
+	} else if p.autofit {
+		bounds = Endpoints{xmin: math.Inf(-1), xmax: math.Inf(1), ymin: math.Inf(-1), ymax: math.Inf(1)}
+	}
+	list, err := parseVertexList(text, bounds)
+	if err != nil {
+		return err
+	}
+	location := list.location
+	if len(location) < minVertices || len(location) > maxVertices {
+		return fmt.Errorf("pasted vertex list has %d vertices in the bounds, not in the range %d-%d",
+			len(location), minVertices, maxVertices)
+	}
+	p.location = location
+	p.names = namesOrNil(list.names)
+	p.imported = fmt.Sprintf("Pasted vertex list: %d vertices accepted, %d lines rejected", len(location), list.rejected)
+	if len(list.units) > 0 {
+		p.meta.Units = list.units
+		p.imported += ", coordinates in " + list.units
+	}
+	if projected {
+		p.meta.Projection = p.project()
+		p.meta.Units = "m"
+		p.imported += ", projected to " + p.meta.Projection
+	}
+	if p.autofit || projected {
+		p.Endpoints = fitEndpoints(location, p.margin)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	patternVertexStream = "/api/primmst/stream" // http handler for the MST of vertices streamed in chunked POSTs
+	maxVertexStreams    = 16                    // vertex streams kept in memory
+	vertexStreamIdle    = 10 * time.Minute      // time without new vertices before a stream is dropped
+)
+
+// StreamReport is the MST of a vertex stream so far
+type StreamReport struct {
+	ID       string     `json:"id"`
+	Metric   string     `json:"metric"`
+	Units    string     `json:"units,omitempty"` // coordinate units declared by the header row
+	Count    int        `json:"count"`           // vertices received
+	Rejected int        `json:"rejected"`        // lines rejected
+	Added    int        `json:"added"`           // vertices added since the previous report
+	Distance float64    `json:"distance"`        // MST total distance
+	Longest  float64    `json:"longest"`         // longest MST edge
+	Closed   bool       `json:"closed,omitempty"`
+	Vertices []Point    `json:"vertices,omitempty"` // with full=on
+	Edges    []EdgeJSON `json:"edges,omitempty"`    // with full=on, by increasing distance
+}
+
+// vertexStream is the MST of the vertices received so far.  A new vertex only needs the
+// tree edges and its own edges: the MST of the graph with the vertex is the MST of those.
+type vertexStream struct {
+	sync.Mutex
+	id       string // random token, since anyone with the id can add vertices or close the stream
+	metric   string
+	model    WeightModel
+	cols     vertexColumns
+	first    bool // the next line can be the header row
+	units    string
+	location []complex128
+	names    []string
+	tree     []WeightedEdge // MST edges by increasing distance
+	rejected int
+	used     time.Time // latest POST, for dropping idle streams
+	closed   bool
+}
+
+// vertexStreams are the vertex streams by ID
+var vertexStreams = struct {
+	sync.Mutex
+	streams map[string]*vertexStream
+}{streams: make(map[string]*vertexStream)}
+
+// newVertexStream creates a stream with the weight model of the metric query parameters,
+// dropping the idle streams
+func newVertexStream(r *http.Request) (*vertexStream, error) {
+	p := &PrimMST{}
+	var err error
+	if p.meta.Metric, err = formChoice(r, "metric", metrics); err != nil {
+		return nil, err
+	}
+	if p.meta.Metric == metricTerrain {
+		return nil, fmt.Errorf("the %s metric needs the whole graph, it cannot stream", metricTerrain)
+	}
+	for _, m := range weightModels {
+		if m.name == p.meta.Metric {
+			if p.model, err = m.create(p, r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	s := &vertexStream{id: randomToken(), metric: p.meta.Metric, model: p.model,
+		cols: defaultColumns, first: true, used: time.Now()}
+
+	vertexStreams.Lock()
+	defer vertexStreams.Unlock()
+	for id, old := range vertexStreams.streams {
+		old.Lock()
+		if time.Since(old.used) > vertexStreamIdle {
+			old.closed = true
+			delete(vertexStreams.streams, id)
+		}
+		old.Unlock()
+	}
+	if len(vertexStreams.streams) >= maxVertexStreams {
+		return nil, fmt.Errorf("%d vertex streams are open, try again later", maxVertexStreams)
+	}
+	vertexStreams.streams[s.id] = s
+	return s, nil
+}
+
+// vertexStreamByID returns the open stream with the ID
+func vertexStreamByID(id string) (*vertexStream, error) {
+	vertexStreams.Lock()
+	defer vertexStreams.Unlock()
+	s, ok := vertexStreams.streams[id]
+	if !ok {
+		return nil, fmt.Errorf("vertex stream %q is not open", id)
+	}
+	return s, nil
+}
+
+// close ends the stream and drops it
+func (s *vertexStream) close() {
+	vertexStreams.Lock()
+	delete(vertexStreams.streams, s.id)
+	vertexStreams.Unlock()
+	s.Lock()
+	s.closed = true
+	s.Unlock()
+}
+
+// addLine adds the vertex of the line, x,y,name or the columns of the header row.
+// Blank lines and lines starting with # are skipped.  The stream must be locked.
+func (s *vertexStream) addLine(line string) error {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	if s.first {
+		s.first = false
+		cols, units, ok, err := parseHeader(line)
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.cols, s.units = cols, units
+			return nil
+		}
+	}
+	all := Endpoints{xmin: math.Inf(-1), xmax: math.Inf(1), ymin: math.Inf(-1), ymax: math.Inf(1)}
+	z, name, ok := s.cols.parseLine(line, all)
+	if !ok {
+		s.rejected++
+		return nil
+	}
+	return s.add(z, name)
+}
+
+// add adds the vertex and finds the MST of the tree edges and the vertex's edges with
+// Kruskal's algorithm
+func (s *vertexStream) add(z complex128, name string) error {
+	n := len(s.location)
+	if n >= maxVertices {
+		return &BudgetError{What: "vertices", Asked: n + 1, Budget: maxVertices, Flag: "-max-vertices"}
+	}
+	edges := make([]WeightedEdge, 0, len(s.tree)+n)
+	edges = append(edges, s.tree...)
+	for v, a := range s.location {
+		distance, fixed := s.model.Weight(a, z)
+		weight := distance + fixed
+		if math.IsNaN(weight) || math.IsInf(weight, 0) || weight < 0 {
+			return fmt.Errorf("%s weight %g of edge %d-%d is not finite and non-negative", s.metric, weight, v, n)
+		}
+		edges = append(edges, WeightedEdge{Edge{v: v, w: n}, weight})
+	}
+	sortEdges(edges)
+	uf := newUnionFind(n + 1)
+	tree := edges[:0]
+	for _, e := range edges {
+		if uf.union(e.v, e.w) {
+			tree = append(tree, e)
+		}
+	}
+	s.tree = tree
+	s.location = append(s.location, z)
+	s.names = append(s.names, name)
+	return nil
+}
+
+// report returns the MST of the stream with the vertices added since the previous report
+// at count, and the vertices and edges when full.  The stream must be locked.
+func (s *vertexStream) report(count int, full bool) StreamReport {
+	rp := StreamReport{ID: s.id, Metric: s.metric, Units: s.units, Count: len(s.location),
+		Rejected: s.rejected, Added: len(s.location) - count, Closed: s.closed}
+	for _, e := range s.tree {
+		rp.Distance += e.distance
+		rp.Longest = math.Max(rp.Longest, e.distance)
+	}
+	if full {
+		rp.Vertices = make([]Point, len(s.location))
+		for i, z := range s.location {
+			rp.Vertices[i] = Point{X: real(z), Y: imag(z), Name: s.names[i]}
+		}
+		rp.Edges = make([]EdgeJSON, len(s.tree))
+		for i, e := range s.tree {
+			rp.Edges[i] = EdgeJSON{V: e.v, W: e.w, Distance: e.distance}
+		}
+	}
+	return rp
+}
+
+// postVertices adds the vertex lines of the request body as they arrive, so a chunked POST
+// can trickle them in while a GET of the stream reports the MST.  It returns the HTTP status
+// of an error.
+func (s *vertexStream) postVertices(r *http.Request) (int, error) {
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		s.Lock()
+		s.used = time.Now()
+		err := s.addLine(scanner.Text())
+		closed := s.closed
+		s.Unlock()
+		if closed {
+			return http.StatusGone, fmt.Errorf("vertex stream %s was closed", s.id)
+		}
+		if _, ok := err.(*BudgetError); ok {
+			return http.StatusRequestEntityTooLarge, err
+		}
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return http.StatusBadRequest, err
+	}
+	return http.StatusOK, nil
+}
+
+// followStream writes a report every interval while vertices arrive, as newline delimited
+// JSON, until the stream is closed or the client disconnects
+func (s *vertexStream) followStream(w http.ResponseWriter, r *http.Request, interval float64, full bool) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	ticker := time.NewTicker(time.Duration(interval * float64(time.Second)))
+	defer ticker.Stop()
+	count, first := 0, true
+	for {
+		s.Lock()
+		changed := first || len(s.location) != count || s.closed
+		var rp StreamReport
+		if changed {
+			rp = s.report(count, full)
+			count, first = len(s.location), false
+		}
+		s.Unlock()
+		if changed {
+			if err := enc.Encode(rp); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			if rp.Closed {
+				return
+			}
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HTTP handler for /api/primmst/stream connections, the MST of vertices that arrive over time.
+// A POST of x,y,name lines without an id opens a stream, and with the id of the report adds
+// to it; the body is read as it arrives, so it can be a long chunked upload.  A GET of the
+// id reports the MST as newline delimited JSON every interval seconds while vertices arrive.
+// A DELETE of the id, or a POST with close=on, closes the stream.  The parameters are query
+// parameters so the body is only vertices.
+func handleVertexStream(w http.ResponseWriter, r *http.Request) {
+	r.Form = r.URL.Query()
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		http.Error(w, "Send the vertex lines as the request body, not a multipart form", http.StatusUnsupportedMediaType)
+		return
+	}
+	full := len(r.FormValue("full")) > 0
+	var (
+		s   *vertexStream
+		err error
+	)
+	if id := r.FormValue("id"); len(id) > 0 {
+		s, err = vertexStreamByID(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	} else if r.Method != http.MethodPost {
+		http.Error(w, "Open a vertex stream with a POST, then follow it with a GET of its id", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		code := http.StatusOK
+		if s == nil {
+			if s, err = newVertexStream(r); err != nil {
+				fmt.Printf("newVertexStream error: %v\n", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			code = http.StatusCreated
+		}
+		s.Lock()
+		count := len(s.location)
+		s.Unlock()
+		if status, err := s.postVertices(r); err != nil {
+			fmt.Printf("postVertices error: %v\n", err)
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if len(r.FormValue("close")) > 0 {
+			s.close()
+		}
+		s.Lock()
+		rp := s.report(count, full)
+		s.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if err := json.NewEncoder(w).Encode(rp); err != nil {
+			fmt.Printf("JSON encode error: %v\n", err)
+		}
+	case http.MethodGet:
+		interval, err := formFloat(r, "interval", defaultInterval)
+		if err != nil || !(interval >= minInterval && interval <= 60) {
+			http.Error(w, fmt.Sprintf("interval %q must be %g-60 seconds", r.FormValue("interval"), minInterval),
+				http.StatusBadRequest)
+			return
+		}
+		s.followStream(w, r, interval, full)
+	case http.MethodDelete:
+		s.close()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Use POST, GET, or DELETE", http.StatusMethodNotAllowed)
+	}
+}
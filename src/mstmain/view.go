@@ -0,0 +1,86 @@
+package main
+
+import (
+	"html/template"
+	"path/filepath"
+	"strings"
+)
+
+// templateFuncs are the helpers available to every page template
+var templateFuncs = template.FuncMap{
+	// checked and selected write the attribute of a checkbox or option when it is set
+	"checked": func(b bool) template.HTMLAttr {
+		if b {
+			return " checked"
+		}
+		return ""
+	},
+	"selected": func(b bool) template.HTMLAttr {
+		if b {
+			return " selected"
+		}
+		return ""
+	},
+	// classIf returns the CSS class when the condition holds, for conditional classes
+	"classIf": func(cond bool, class string) string {
+		if cond {
+			return class
+		}
+		return ""
+	},
+	// join joins strings with commas
+	"join": func(s []string) string { return strings.Join(s, ", ") },
+	// seq returns 0 to n-1 to loop a number of times
+	"seq": func(n int) []int {
+		s := make([]int, n)
+		for i := range s {
+			s[i] = i
+		}
+		return s
+	},
+}
+
+// parseTemplate parses the template file with the template helpers
+func parseTemplate(file string) *template.Template {
+	return template.Must(template.New(filepath.Base(file)).Funcs(templateFuncs).ParseFiles(file))
+}
+
+// layerNote is a size or summary a layer reports when it is drawn
+type layerNote struct {
+	layer string
+	text  string
+}
+
+// note records the note of the drawn layer
+func (p *PrimMST) note(layer, text string) {
+	p.layerNotes = append(p.layerNotes, layerNote{layer, text})
+}
+
+// PlotLayerView is the plot layers part of the results page view model
+type PlotLayerView struct {
+	Choices []LayerChoice // layer checkboxes with the notes of the drawn layers
+	Legend  []LegendEntry // CSS classes drawn on the grid
+	Colors  []LayerColor  // user layer colors, overriding the theme
+}
+
+// layerView creates the view of the selected layers, their notes, and the legend of the grid
+func (p *PrimMST) layerView(grid []string) PlotLayerView {
+	v := PlotLayerView{Choices: layerChoices(p.layers), Legend: legend(grid), Colors: p.colors}
+	for i, c := range v.Choices {
+		for _, n := range p.layerNotes {
+			if n.layer == c.Name {
+				v.Choices[i].Notes = append(v.Choices[i].Notes, n.text)
+			}
+		}
+	}
+	return v
+}
+
+// Notes returns the notes of all the drawn layers
+func (v PlotLayerView) Notes() []string {
+	var notes []string
+	for _, c := range v.Choices {
+		notes = append(notes, c.Notes...)
+	}
+	return notes
+}
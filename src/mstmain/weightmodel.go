@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+)
+
+const (
+	metricHaversine = "Haversine"   // great circle distance in km of lon,lat vertices
+	metricCost      = "Cost per km" // cost of the distance plus a fixed setup cost per edge
+	defaultCostKm   = 1.0           // default cost per km of the Cost per km metric
+)
+
+// WeightModel is an edge weight model.  The weight of the edge a-b is its distance component
+// plus its fixed component.
+type WeightModel interface {
+	Weight(a, b complex128) (distance, fixed float64)
+}
+
+// weightModel is a registered weight model, the form selects it by name as the metric
+type weightModel struct {
+	name   string
+	create func(p *PrimMST, r *http.Request) (WeightModel, error) // model with the form parameters
+}
+
+// weightModels are the registered weight models, in the order of the metric choices.
+// Euclidean is first, the default.
+var weightModels = []weightModel{
+	{"Euclidean", func(p *PrimMST, r *http.Request) (WeightModel, error) { return euclideanModel{}, nil }},
+	{metricCustom, newExprModel},
+	{metricHaversine, func(p *PrimMST, r *http.Request) (WeightModel, error) { return haversineModel{}, nil }},
+	{metricCost, newCostModel},
+}
+
+// CostBreakdown is the MST weight split into the distance and fixed components
+type CostBreakdown struct {
+	Distance float64 `json:"distance"`
+	Fixed    float64 `json:"fixed"`
+}
+
+// euclideanModel weighs the edges by their Euclidean length, the solver computes the distances
+type euclideanModel struct{}
+
+func (euclideanModel) Weight(a, b complex128) (float64, float64) {
+	return math.Hypot(real(b)-real(a), imag(b)-imag(a)), 0
+}
+
+// exprModel weighs the edges by the expression of the Custom metric
+type exprModel struct {
+	e expr
+}
+
+// newExprModel parses the weight expression of the form
+func newExprModel(p *PrimMST, r *http.Request) (WeightModel, error) {
+	src := strings.TrimSpace(r.FormValue("expression"))
+	if len(src) == 0 {
+		return nil, fmt.Errorf("the %s metric needs a weight expression", metricCustom)
+	}
+	e, err := parseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p.meta.Expression = src
+	return exprModel{e}, nil
+}
+
+func (m exprModel) Weight(a, b complex128) (float64, float64) {
+	var vars [nvars]float64
+	vars[varX1], vars[varY1] = real(a), imag(a)
+	vars[varX2], vars[varY2] = real(b), imag(b)
+	vars[varDx], vars[varDy] = real(b)-real(a), imag(b)-imag(a)
+	vars[varD] = math.Hypot(vars[varDx], vars[varDy])
+	return m.e(&vars), 0
+}
+
+// haversineModel weighs the edges by the great circle distance in km of x,y as lon,lat degrees
+type haversineModel struct{}
+
+func (haversineModel) Weight(a, b complex128) (float64, float64) {
+	return haversine(a, b), 0
+}
+
+// haversine returns the great circle distance in km between the lon,lat degrees on a sphere
+// of the WGS 84 equatorial radius
+func haversine(a, b complex128) float64 {
+	lat1, lat2 := imag(a)*math.Pi/180, imag(b)*math.Pi/180
+	dlat := lat2 - lat1
+	dlon := (real(b) - real(a)) * math.Pi / 180
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dlon/2), 2)
+	return 2 * earthRadius / 1000 * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// costModel weighs the edges by the cost of their Euclidean length in km plus the setup cost
+type costModel struct {
+	perKm float64 // cost per km
+	setup float64 // fixed cost per edge
+	km    float64 // km per x,y unit, projected vertex lists are in meters
+}
+
+// newCostModel gets the cost per km and setup cost from the form
+func newCostModel(p *PrimMST, r *http.Request) (WeightModel, error) {
+	m := costModel{km: 1}
+	var err error
+	if m.perKm, err = formFloat(r, "costperkm", defaultCostKm); err != nil {
+		return nil, err
+	}
+	if m.setup, err = formFloat(r, "setupcost", 0); err != nil {
+		return nil, err
+	}
+	if !(m.perKm >= 0 && m.setup >= 0) || math.IsInf(m.perKm, 0) || math.IsInf(m.setup, 0) {
+		return nil, fmt.Errorf("cost per km %g and setup cost %g must be finite and non-negative", m.perKm, m.setup)
+	}
+	if len(p.projection) > 0 && p.projection != projections[0] {
+		m.km = 0.001
+	}
+	return m, nil
+}
+
+func (m costModel) Weight(a, b complex128) (float64, float64) {
+	return m.perKm * m.km * math.Hypot(real(b)-real(a), imag(b)-imag(a)), m.setup
+}
+
+// formWeightModel creates the weight model of the metric selected in the HTML form.
+// An invalid model falls back to Euclidean.
+func (p *PrimMST) formWeightModel(r *http.Request) error {
+	for _, m := range weightModels {
+		if m.name != p.meta.Metric {
+			continue
+		}
+		model, err := m.create(p, r)
+		if err != nil {
+			p.meta.Metric, p.meta.Expression = metrics[0], ""
+			return fmt.Errorf("%v, using %s", err, metrics[0])
+		}
+		p.model = model
+	}
+	return nil
+}
+
+// modelDistances evaluates the weight model for each vertex pair v < w.
+// The weights must be finite and non-negative.
+func (p *PrimMST) modelDistances() error {
+	n := len(p.location)
+	p.graph = make([][]float64, n)
+	for i := range p.graph {
+		p.graph[i] = make([]float64, n)
+		p.graph[i][i] = math.MaxFloat64
+	}
+	for v := 0; v < n; v++ {
+		for w := v + 1; w < n; w++ {
+			weight, err := p.modelWeight(v, w)
+			if err != nil {
+				return err
+			}
+			p.graph[v][w] = weight
+			p.graph[w][v] = weight
+		}
+	}
+	return nil
+}
+
+// modelWeight evaluates the weight model for edge v-w
+func (p *PrimMST) modelWeight(v, w int) (float64, error) {
+	distance, fixed := p.model.Weight(p.location[v], p.location[w])
+	weight := distance + fixed
+	if math.IsNaN(weight) || math.IsInf(weight, 0) || weight < 0 {
+		return 0, fmt.Errorf("%s weight %g of edge %d-%d is not finite and non-negative", p.meta.Metric, weight, v, w)
+	}
+	return weight, nil
+}
+
+// costBreakdown splits the MST weight into the distance and fixed components of the model,
+// nil when the model has no fixed component
+func (p *PrimMST) costBreakdown() *CostBreakdown {
+	if p.model == nil || p.terrain != nil {
+		return nil
+	}
+	c := &CostBreakdown{}
+	for _, e := range p.mst {
+		if e != nil {
+			distance, fixed := p.model.Weight(p.location[e.v], p.location[e.w])
+			c.Distance += distance
+			c.Fixed += fixed
+		}
+	}
+	if c.Fixed == 0 {
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+)
+
+const patternRandomTree = "/primmstrandomtree" // http handler for the random spanning tree beside the MST
+
+// wilson draws a uniformly random spanning tree of the complete graph on the vertices
+// rooted at the start vertex using Wilson's loop-erased random walk algorithm
+func (p *PrimMST) wilson(rnd *rand.Rand) MST {
+	n := len(p.location)
+	inTree := make([]bool, n)
+	next := make([]int, n)
+	inTree[p.start] = true
+	tree := make(MST, n)
+	for i := 0; i < n; i++ {
+		// Random walk from i until it hits the tree, remembering the last exit from each
+		// vertex, which erases the loops
+		u := i
+		for !inTree[u] {
+			w := rnd.Intn(n - 1)
+			if w >= u {
+				w++
+			}
+			next[u] = w
+			u = w
+		}
+		// Add the loop-erased path to the tree
+		for u = i; !inTree[u]; u = next[u] {
+			inTree[u] = true
+			tree[u] = &Edge{v: next[u], w: u}
+		}
+	}
+	return tree
+}
+
+// HTTP handler for /primmstrandomtree connections, the optional seed query parameter
+// reproduces a random spanning tree
+func handleRandomTree(w http.ResponseWriter, r *http.Request) {
+	last := latestMST()
+	if last == nil || len(last.order) == 0 {
+		http.Error(w, "No MST has been created, submit the graph options first", http.StatusNotFound)
+		return
+	}
+	seed := newSeed()
+	if str := r.FormValue("seed"); len(str) > 0 {
+		var err error
+		if seed, err = strconv.ParseInt(str, 10, 64); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	random := &PrimMST{Endpoints: last.Endpoints, location: last.location, graph: last.graph, start: last.start,
+		model: last.model, terrain: last.terrain}
+	random.mst = last.wilson(rand.New(rand.NewSource(seed)))
+
+	plot := CompareT{Theme: last.theme}
+	trees := []*PrimMST{last, random}
+	names := []string{"MST", "Random spanning tree"}
+	for i, p := range trees {
+		stats := CompareStats{Name: names[i], Vertices: len(p.location)}
+		p.treeStats(&stats, defaultLabelFormat)
+		plot.Stats = append(plot.Stats, stats)
+	}
+	ratio := random.totalDistance() / last.totalDistance()
+	plot.Status = fmt.Sprintf("Wilson's algorithm, seed %d: the random spanning tree is %.1f times the MST weight",
+		seed, ratio)
+
+	ep := last.plotEndpoints()
+	g := newGridPlot(ep)
+	plot.Xlabel, plot.Ylabel = g.labels(last.format)
+
+	sw := newStreamWriter(w, r)
+	if err := tmplCompare.ExecuteTemplate(sw, "gridstart", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(last.plotCompare(ep), columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplCompare.ExecuteTemplate(sw, "gridmiddle", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+		return
+	}
+	if err := sw.writeGrid(random.plotCompare(ep), columns); err != nil {
+		fmt.Printf("writeGrid error: %v\n", err)
+		return
+	}
+	if err := tmplCompare.ExecuteTemplate(sw, "gridend", plot); err != nil {
+		fmt.Printf("Write to HTTP output using template with grid error: %v\n", err)
+	}
+}
@@ -0,0 +1,217 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// MaxBruteForce is the most vertices BruteForceMST enumerates the spanning trees of
+const MaxBruteForce = 6
+
+// CheckQueue runs ops random pushes, pops, and decrease-keys on the named priority queue and
+// checks each result against a brute-force scan of the keys
+func CheckQueue(name string, rnd *rand.Rand, n, ops int) error {
+	q, err := NewQueue(name, n)
+	if err != nil {
+		return err
+	}
+	keys := make(map[int]float64) // vertices in the queue and their distances
+	for op := 0; op < ops; op++ {
+		v := rnd.Intn(n)
+		// Small integer distances make ties common
+		distance := float64(rnd.Intn(20))
+		switch {
+		case !q.Contains(v) && rnd.Intn(3) > 0:
+			q.Push(v, distance)
+			keys[v] = distance
+		case q.Contains(v) && distance < keys[v]:
+			q.DecreaseKey(v, distance)
+			keys[v] = distance
+		case q.Len() > 0:
+			w, got := q.Pop()
+			least := math.MaxFloat64
+			for _, d := range keys {
+				least = math.Min(least, d)
+			}
+			want, ok := keys[w]
+			if !ok || got != want || got != least {
+				return fmt.Errorf("%s queue op %d popped vertex %d at %g, the least distance is %g", name, op, w, got, least)
+			}
+			delete(keys, w)
+		}
+		if q.Len() != len(keys) {
+			return fmt.Errorf("%s queue op %d has length %d, want %d", name, op, q.Len(), len(keys))
+		}
+		for w := 0; w < n; w++ {
+			if _, ok := keys[w]; q.Contains(w) != ok {
+				return fmt.Errorf("%s queue op %d contains vertex %d is %v, want %v", name, op, w, q.Contains(w), ok)
+			}
+		}
+	}
+	return nil
+}
+
+// BruteForceMST returns the least weight of the spanning trees of the graph, found by
+// enumerating the sets of n-1 edges.  The graph has at most MaxBruteForce vertices.
+func BruteForceMST(graph [][]float64) (float64, error) {
+	n := len(graph)
+	if n > MaxBruteForce {
+		return 0, fmt.Errorf("brute force MST of %d vertices, more than %d", n, MaxBruteForce)
+	}
+	var edges [][2]int
+	for v := 0; v < n; v++ {
+		for w := v + 1; w < n; w++ {
+			edges = append(edges, [2]int{v, w})
+		}
+	}
+	best := math.Inf(1)
+	chosen := make([][2]int, 0, n)
+	var choose func(next int)
+	choose = func(next int) {
+		if len(chosen) == n-1 {
+			if weight, ok := treeWeight(graph, chosen); ok && weight < best {
+				best = weight
+			}
+			return
+		}
+		for i := next; i <= len(edges)-(n-1-len(chosen)); i++ {
+			chosen = append(chosen, edges[i])
+			choose(i + 1)
+			chosen = chosen[:len(chosen)-1]
+		}
+	}
+	if n <= 1 {
+		return 0, nil
+	}
+	choose(0)
+	return best, nil
+}
+
+// treeWeight returns the weight of the n-1 edges and whether they span the graph
+func treeWeight(graph [][]float64, edges [][2]int) (float64, bool) {
+	component := make([]int, len(graph))
+	for i := range component {
+		component[i] = i
+	}
+	var find func(v int) int
+	find = func(v int) int {
+		if component[v] != v {
+			component[v] = find(component[v])
+		}
+		return component[v]
+	}
+	var weight float64
+	for _, e := range edges {
+		a, b := find(e[0]), find(e[1])
+		if a == b {
+			return 0, false
+		}
+		component[a] = b
+		weight += graph[e[0]][e[1]]
+	}
+	return weight, true
+}
+
+// CheckTree checks that parent is a spanning tree of the graph from start with the weight
+// of the brute-force MST
+func CheckTree(graph [][]float64, start int, parent []int) error {
+	var edges [][2]int
+	for v, u := range parent {
+		switch {
+		case v == start && u != -1:
+			return fmt.Errorf("start vertex %d has parent %d", v, u)
+		case v != start && (u < 0 || u >= len(graph)):
+			return fmt.Errorf("vertex %d is not in the tree", v)
+		case v != start:
+			edges = append(edges, [2]int{u, v})
+		}
+	}
+	return CheckEdges(graph, edges)
+}
+
+// CheckEdges checks that the edges are a spanning tree of the graph with the weight of the
+// brute-force MST
+func CheckEdges(graph [][]float64, edges [][2]int) error {
+	n := len(graph)
+	if n > 0 && len(edges) != n-1 {
+		return fmt.Errorf("%d edges, a spanning tree of %d vertices has %d", len(edges), n, n-1)
+	}
+	weight, ok := treeWeight(graph, edges)
+	if !ok {
+		return fmt.Errorf("the edges have a cycle")
+	}
+	want, err := BruteForceMST(graph)
+	if err != nil {
+		return err
+	}
+	if math.Abs(weight-want) > 1e-9*math.Max(1, want) {
+		return fmt.Errorf("tree weight %g, the brute-force MST weight is %g", weight, want)
+	}
+	return nil
+}
+
+// RandomGraph returns a complete graph of n vertices with random weights, small integer
+// weights with many ties when ties is true
+func RandomGraph(rnd *rand.Rand, n int, ties bool) [][]float64 {
+	graph := make([][]float64, n)
+	for v := range graph {
+		graph[v] = make([]float64, n)
+		graph[v][v] = math.MaxFloat64
+	}
+	for v := 0; v < n; v++ {
+		for w := v + 1; w < n; w++ {
+			weight := rnd.Float64()
+			if ties {
+				weight = float64(rnd.Intn(4))
+			}
+			graph[v][w], graph[w][v] = weight, weight
+		}
+	}
+	return graph
+}
+
+// SelfTest checks the priority queues with random operations, and Prim's algorithm with each
+// queue against the brute-force MST of small random, tied, and Euclidean graphs.  The
+// lexicographic tie-break must find the same tree with each queue.
+func SelfTest(rnd *rand.Rand, trials int) error {
+	for _, name := range QueueNames() {
+		for trial := 0; trial < trials; trial++ {
+			if err := CheckQueue(name, rnd, 1+rnd.Intn(30), 200); err != nil {
+				return err
+			}
+		}
+	}
+	for trial := 0; trial < trials; trial++ {
+		n := 1 + rnd.Intn(MaxBruteForce)
+		graphs := [][][]float64{RandomGraph(rnd, n, false), RandomGraph(rnd, n, true),
+			Distances(RandomPoints(rnd, n, -10, 10, -10, 10))}
+		for _, graph := range graphs {
+			start := rnd.Intn(n)
+			var lexicographic []int // tree of the lexicographic tie-break, the same for each queue
+			for _, name := range QueueNames() {
+				q, _ := NewQueue(name, n)
+				parent, _ := PrimQueue(graph, start, q)
+				if err := CheckTree(graph, start, parent); err != nil {
+					return fmt.Errorf("Prim with the %s queue on %d vertices: %v", name, n, err)
+				}
+				q, _ = NewQueue(name, n)
+				parent, _, _, _ = PrimTies(context.Background(), graph, start, q, true)
+				if err := CheckTree(graph, start, parent); err != nil {
+					return fmt.Errorf("Prim with the lexicographic tie-break and the %s queue on %d vertices: %v", name, n, err)
+				}
+				if lexicographic == nil {
+					lexicographic = parent
+				}
+				for v := range parent {
+					if parent[v] != lexicographic[v] {
+						return fmt.Errorf("Prim with the lexicographic tie-break and the %s queue on %d vertices: vertex %d has parent %d, the %s queue gave %d",
+							name, n, v, parent[v], QueueNames()[0], lexicographic[v])
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package solver
+
+const defaultArity = 4 // children per node of the d-ary heap
+
+// daryQueue is a d-ary heap of vertices, shallower than a binary heap so that
+// DecreaseKey, which Prim calls more often than Pop, sifts up fewer levels
+type daryQueue struct {
+	d           int       // children per node
+	heap        []int     // vertices in heap order
+	pos         []int     // heap index of each vertex, -1 when not queued
+	key         []float64 // distance of each vertex
+	comparisons int
+}
+
+// NewDaryQueue creates a 4-ary heap priority queue for n vertices
+func NewDaryQueue(n int) Queue {
+	q := &daryQueue{d: defaultArity, heap: make([]int, 0, n), pos: make([]int, n), key: make([]float64, n)}
+	for i := range q.pos {
+		q.pos[i] = -1
+	}
+	return q
+}
+
+func (q *daryQueue) less(i, j int) bool {
+	q.comparisons++
+	return q.key[q.heap[i]] < q.key[q.heap[j]]
+}
+
+func (q *daryQueue) swap(i, j int) {
+	q.heap[i], q.heap[j] = q.heap[j], q.heap[i]
+	q.pos[q.heap[i]] = i
+	q.pos[q.heap[j]] = j
+}
+
+func (q *daryQueue) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / q.d
+		if !q.less(i, parent) {
+			return
+		}
+		q.swap(i, parent)
+		i = parent
+	}
+}
+
+func (q *daryQueue) down(i int) {
+	for {
+		smallest := i
+		first := q.d*i + 1
+		for c := first; c < first+q.d && c < len(q.heap); c++ {
+			if q.less(c, smallest) {
+				smallest = c
+			}
+		}
+		if smallest == i {
+			return
+		}
+		q.swap(i, smallest)
+		i = smallest
+	}
+}
+
+func (q *daryQueue) Push(v int, distance float64) {
+	q.key[v] = distance
+	q.pos[v] = len(q.heap)
+	q.heap = append(q.heap, v)
+	q.up(q.pos[v])
+}
+
+func (q *daryQueue) Pop() (int, float64) {
+	v := q.heap[0]
+	last := len(q.heap) - 1
+	q.swap(0, last)
+	q.heap = q.heap[:last]
+	q.pos[v] = -1
+	if last > 0 {
+		q.down(0)
+	}
+	return v, q.key[v]
+}
+
+func (q *daryQueue) DecreaseKey(v int, distance float64) {
+	q.key[v] = distance
+	q.up(q.pos[v])
+}
+
+func (q *daryQueue) Contains(v int) bool {
+	return q.pos[v] >= 0
+}
+
+func (q *daryQueue) Len() int {
+	return len(q.heap)
+}
+
+func (q *daryQueue) Comparisons() int {
+	return q.comparisons
+}
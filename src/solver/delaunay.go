@@ -0,0 +1,140 @@
+package solver
+
+import (
+	"math"
+	"sort"
+)
+
+// Delaunay returns the edges v < w of the Delaunay triangulation of the points using
+// the Bowyer-Watson algorithm.  Duplicate points are left out of the triangulation.
+func Delaunay(points []complex128) [][2]int {
+	n := len(points)
+	if n < 2 {
+		return nil
+	}
+	if n == 2 {
+		return [][2]int{{0, 1}}
+	}
+
+	// A super triangle containing all the points, its vertices are n, n+1, n+2
+	xmin, xmax := math.Inf(1), math.Inf(-1)
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, z := range points {
+		xmin, xmax = math.Min(xmin, real(z)), math.Max(xmax, real(z))
+		ymin, ymax = math.Min(ymin, imag(z)), math.Max(ymax, imag(z))
+	}
+	size := math.Max(math.Max(xmax-xmin, ymax-ymin), 1)
+	cx, cy := (xmin+xmax)/2, (ymin+ymax)/2
+	pts := append(append([]complex128(nil), points...),
+		complex(cx-20*size, cy-10*size), complex(cx+20*size, cy-10*size), complex(cx, cy+20*size))
+
+	type triangle struct{ a, b, c int }
+	// inCircle returns true if z is strictly inside the circumcircle of t
+	inCircle := func(t triangle, z complex128) bool {
+		a, b, c := pts[t.a]-z, pts[t.b]-z, pts[t.c]-z
+		det := (real(a)*real(a)+imag(a)*imag(a))*(real(b)*imag(c)-real(c)*imag(b)) -
+			(real(b)*real(b)+imag(b)*imag(b))*(real(a)*imag(c)-real(c)*imag(a)) +
+			(real(c)*real(c)+imag(c)*imag(c))*(real(a)*imag(b)-real(b)*imag(a))
+		// The sign depends on the orientation of the triangle
+		orient := (real(pts[t.b])-real(pts[t.a]))*(imag(pts[t.c])-imag(pts[t.a])) -
+			(imag(pts[t.b])-imag(pts[t.a]))*(real(pts[t.c])-real(pts[t.a]))
+		if orient < 0 {
+			det = -det
+		}
+		return det > 0
+	}
+
+	triangles := []triangle{{n, n + 1, n + 2}}
+	for i := 0; i < n; i++ {
+		// Remove the triangles whose circumcircle contains the point, keeping the
+		// edges of the hole they leave
+		edges := make(map[[2]int]int)
+		kept := triangles[:0]
+		var bad []triangle
+		for _, t := range triangles {
+			if inCircle(t, pts[i]) {
+				bad = append(bad, t)
+			} else {
+				kept = append(kept, t)
+			}
+		}
+		if len(bad) == 0 {
+			continue // a duplicate point
+		}
+		triangles = kept
+		for _, t := range bad {
+			for _, e := range [][2]int{{t.a, t.b}, {t.b, t.c}, {t.c, t.a}} {
+				if e[0] > e[1] {
+					e[0], e[1] = e[1], e[0]
+				}
+				edges[e]++
+			}
+		}
+		// Connect the point to each edge of the hole boundary
+		for e, count := range edges {
+			if count == 1 {
+				triangles = append(triangles, triangle{e[0], e[1], i})
+			}
+		}
+	}
+
+	// The edges of the triangles that do not use the super triangle
+	seen := make(map[[2]int]bool)
+	var result [][2]int
+	for _, t := range triangles {
+		for _, e := range [][2]int{{t.a, t.b}, {t.b, t.c}, {t.c, t.a}} {
+			if e[0] >= n || e[1] >= n {
+				continue
+			}
+			if e[0] > e[1] {
+				e[0], e[1] = e[1], e[0]
+			}
+			if !seen[e] {
+				seen[e] = true
+				result = append(result, e)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0] || (result[i][0] == result[j][0] && result[i][1] < result[j][1])
+	})
+	return result
+}
+
+// ConvexHull returns the indexes of the points on the convex hull in counterclockwise
+// order using Andrew's monotone chain algorithm
+func ConvexHull(points []complex128) []int {
+	n := len(points)
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		a, b := points[idx[i]], points[idx[j]]
+		return real(a) < real(b) || (real(a) == real(b) && imag(a) < imag(b))
+	})
+	if n < 3 {
+		return idx
+	}
+	cross := func(o, a, b int) float64 {
+		return (real(points[a])-real(points[o]))*(imag(points[b])-imag(points[o])) -
+			(imag(points[a])-imag(points[o]))*(real(points[b])-real(points[o]))
+	}
+	hull := make([]int, 0, 2*n)
+	// Lower hull, then upper hull
+	for _, i := range idx {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], i) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, i)
+	}
+	lower := len(hull) + 1
+	for k := n - 2; k >= 0; k-- {
+		i := idx[k]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], i) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, i)
+	}
+	return hull[:len(hull)-1]
+}
@@ -0,0 +1,117 @@
+package solver
+
+// pairingNode is a vertex in the pairing heap
+type pairingNode struct {
+	v        int
+	key      float64
+	child    *pairingNode // first child
+	sibling  *pairingNode // next sibling
+	prev     *pairingNode // previous sibling, or the parent of the first child
+	attached bool         // the node is in the heap
+}
+
+// pairingQueue is a pairing heap of vertices with O(1) Push and amortized
+// sub-logarithmic DecreaseKey
+type pairingQueue struct {
+	root        *pairingNode
+	nodes       []pairingNode
+	n           int
+	comparisons int
+}
+
+// NewPairingQueue creates a pairing heap priority queue for n vertices
+func NewPairingQueue(n int) Queue {
+	return &pairingQueue{nodes: make([]pairingNode, n)}
+}
+
+// meld links two heap roots, the larger becomes the first child of the smaller
+func (q *pairingQueue) meld(a, b *pairingNode) *pairingNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	q.comparisons++
+	if b.key < a.key {
+		a, b = b, a
+	}
+	b.prev = a
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	a.sibling = nil
+	a.prev = nil
+	return a
+}
+
+// mergePairs melds the siblings in pairs left to right, then the pairs right to left
+func (q *pairingQueue) mergePairs(first *pairingNode) *pairingNode {
+	var pairs []*pairingNode
+	for first != nil {
+		a := first
+		b := a.sibling
+		if b == nil {
+			a.sibling, a.prev = nil, nil
+			pairs = append(pairs, a)
+			break
+		}
+		first = b.sibling
+		a.sibling, a.prev, b.sibling, b.prev = nil, nil, nil, nil
+		pairs = append(pairs, q.meld(a, b))
+	}
+	var root *pairingNode
+	for i := len(pairs) - 1; i >= 0; i-- {
+		root = q.meld(pairs[i], root)
+	}
+	return root
+}
+
+func (q *pairingQueue) Push(v int, distance float64) {
+	node := &q.nodes[v]
+	*node = pairingNode{v: v, key: distance, attached: true}
+	q.root = q.meld(q.root, node)
+	q.n++
+}
+
+func (q *pairingQueue) Pop() (int, float64) {
+	root := q.root
+	q.root = q.mergePairs(root.child)
+	root.child = nil
+	root.attached = false
+	q.n--
+	return root.v, root.key
+}
+
+func (q *pairingQueue) DecreaseKey(v int, distance float64) {
+	node := &q.nodes[v]
+	node.key = distance
+	if node == q.root {
+		return
+	}
+	// Cut the subtree from its parent and meld it with the root
+	if node.prev.child == node {
+		node.prev.child = node.sibling
+	} else {
+		node.prev.sibling = node.sibling
+	}
+	if node.sibling != nil {
+		node.sibling.prev = node.prev
+	}
+	node.sibling, node.prev = nil, nil
+	q.root = q.meld(q.root, node)
+}
+
+func (q *pairingQueue) Contains(v int) bool {
+	return q.nodes[v].attached
+}
+
+func (q *pairingQueue) Len() int {
+	return q.n
+}
+
+func (q *pairingQueue) Comparisons() int {
+	return q.comparisons
+}
@@ -0,0 +1,47 @@
+package solver
+
+// Items are stored in the Priority Queue
+type Item struct {
+	V        int     // one vertex of the edge
+	W        int     // the other vertex of the edge
+	index    int     // The index is used by Priority Queue update and is maintained by the heap.Interface
+	Distance float64 // Edge distance between vertices
+}
+
+// Priority Queue is a map of indexes and queue items and implements the heap.Interface
+// A map is used instead of a slice so that it can be easily determined if an edge is in the queue
+type PriorityQueue map[int]*Item
+
+// A PriorityQueue implements heap.Interface and holds Items
+func (pq PriorityQueue) Len() int {
+	return len(pq)
+}
+
+func (pq PriorityQueue) Less(i, j int) bool {
+	return pq[i].Distance < pq[j].Distance
+}
+
+func (pq PriorityQueue) Swap(i, j int) {
+	pq[i], (pq)[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+// Push inserts an Item in the queue
+func (pq *PriorityQueue) Push(x any) {
+	n := len(*pq)
+	item := x.(*Item)
+	item.index = n
+	(*pq)[n] = item
+}
+
+// Pop removes an Item from the queue and returns it
+func (pq *PriorityQueue) Pop() any {
+	old := *pq
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	delete(*pq, n-1)
+	return item
+}
@@ -0,0 +1,40 @@
+package solver
+
+// Proximity graphs of the points of a distance matrix.  Both contain the Euclidean MST,
+// MST ⊆ RNG ⊆ Gabriel, so an MST of either is an MST of the complete graph.
+
+// Gabriel returns the edges v < w of the Gabriel graph: no other point is strictly
+// inside the circle whose diameter is the edge
+func Gabriel(graph [][]float64) [][2]int {
+	return proximity(graph, func(dvr, dwr, dvw float64) bool {
+		return dvr*dvr+dwr*dwr < dvw*dvw
+	})
+}
+
+// RelativeNeighborhood returns the edges v < w of the relative neighborhood graph (RNG):
+// no other point is strictly closer to both v and w than they are to each other
+func RelativeNeighborhood(graph [][]float64) [][2]int {
+	return proximity(graph, func(dvr, dwr, dvw float64) bool {
+		return dvr < dvw && dwr < dvw
+	})
+}
+
+// proximity returns the edges v < w that no point r blocks.  The diagonal of the
+// distance matrix is MaxFloat64, so v and w never block their own edge.
+func proximity(graph [][]float64, blocks func(dvr, dwr, dvw float64) bool) [][2]int {
+	n := len(graph)
+	var edges [][2]int
+	for v := 0; v < n; v++ {
+		for w := v + 1; w < n; w++ {
+			dvw := graph[v][w]
+			blocked := false
+			for r := 0; r < n && !blocked; r++ {
+				blocked = blocks(graph[v][r], graph[w][r], dvw)
+			}
+			if !blocked {
+				edges = append(edges, [2]int{v, w})
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+// Queue is the priority queue of the vertices not yet in the MST, keyed by their
+// distance to the MST
+type Queue interface {
+	Push(v int, distance float64)        // insert vertex v
+	Pop() (int, float64)                 // remove the vertex with the smallest distance
+	DecreaseKey(v int, distance float64) // lower the distance of vertex v in the queue
+	Contains(v int) bool                 // vertex v is in the queue
+	Len() int                            // number of vertices in the queue
+	Comparisons() int                    // distance comparisons made so far
+}
+
+// Queues are the priority queue implementations by name, each creates a queue for n vertices
+var Queues = map[string]func(n int) Queue{
+	"binary":  NewBinaryQueue,
+	"pairing": NewPairingQueue,
+	"dary":    NewDaryQueue,
+}
+
+// QueueNames returns the names of the priority queue implementations in sorted order
+func QueueNames() []string {
+	names := make([]string, 0, len(Queues))
+	for name := range Queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewQueue creates the named priority queue for n vertices
+func NewQueue(name string, n int) (Queue, error) {
+	newQueue, ok := Queues[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown priority queue %s, one of %v", name, QueueNames())
+	}
+	return newQueue(n), nil
+}
+
+// countingHeap is the PriorityQueue counting the comparisons made by container/heap
+type countingHeap struct {
+	PriorityQueue
+	comparisons int
+}
+
+func (h *countingHeap) Less(i, j int) bool {
+	h.comparisons++
+	return h.PriorityQueue.Less(i, j)
+}
+
+// binaryQueue is the binary heap Queue using container/heap
+type binaryQueue struct {
+	h      countingHeap
+	queued map[int]*Item // the queue map is keyed by heap index, queued by vertex
+}
+
+// NewBinaryQueue creates a binary heap priority queue
+func NewBinaryQueue(n int) Queue {
+	return &binaryQueue{h: countingHeap{PriorityQueue: make(PriorityQueue)}, queued: make(map[int]*Item)}
+}
+
+func (q *binaryQueue) Push(v int, distance float64) {
+	item := &Item{V: v, W: v, Distance: distance}
+	heap.Push(&q.h, item)
+	q.queued[v] = item
+}
+
+func (q *binaryQueue) Pop() (int, float64) {
+	item := heap.Pop(&q.h).(*Item)
+	delete(q.queued, item.W)
+	return item.W, item.Distance
+}
+
+func (q *binaryQueue) DecreaseKey(v int, distance float64) {
+	item := q.queued[v]
+	item.Distance = distance
+	heap.Fix(&q.h, item.index)
+}
+
+func (q *binaryQueue) Contains(v int) bool {
+	_, ok := q.queued[v]
+	return ok
+}
+
+func (q *binaryQueue) Len() int {
+	return len(q.h.PriorityQueue)
+}
+
+func (q *binaryQueue) Comparisons() int {
+	return q.h.comparisons
+}
+
+// Counts are the priority queue operations made by Prim's algorithm
+type Counts struct {
+	Push        int
+	Pop         int
+	DecreaseKey int
+	Comparisons int
+	Scanned     int // edges scanned from the vertices added to the MST
+}
+
+// scanCounter is implemented by the queues that count the edges Prim's algorithm scans
+type scanCounter interface {
+	Scan(edges int)
+}
+
+// CountingQueue counts the operations on a Queue
+type CountingQueue struct {
+	Queue
+	Counts Counts
+}
+
+// NewCountingQueue wraps the queue to count its operations
+func NewCountingQueue(q Queue) *CountingQueue {
+	return &CountingQueue{Queue: q}
+}
+
+func (q *CountingQueue) Push(v int, distance float64) {
+	q.Counts.Push++
+	q.Queue.Push(v, distance)
+}
+
+func (q *CountingQueue) Pop() (int, float64) {
+	q.Counts.Pop++
+	return q.Queue.Pop()
+}
+
+func (q *CountingQueue) DecreaseKey(v int, distance float64) {
+	q.Counts.DecreaseKey++
+	q.Queue.DecreaseKey(v, distance)
+}
+
+// Scan counts the edges scanned from a vertex added to the MST
+func (q *CountingQueue) Scan(edges int) {
+	q.Counts.Scanned += edges
+}
+
+// Comparisons updates and returns the comparison count
+func (q *CountingQueue) Comparisons() int {
+	q.Counts.Comparisons = q.Queue.Comparisons()
+	return q.Counts.Comparisons
+}
@@ -0,0 +1,61 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestQueues(t *testing.T) {
+	tests := []struct {
+		n, ops int
+	}{
+		{1, 50},
+		{2, 100},
+		{10, 500},
+		{100, 5000},
+	}
+	for _, name := range QueueNames() {
+		for _, tt := range tests {
+			rnd := rand.New(rand.NewSource(int64(tt.n)))
+			if err := CheckQueue(name, rnd, tt.n, tt.ops); err != nil {
+				t.Errorf("n %d: %v", tt.n, err)
+			}
+		}
+	}
+}
+
+func TestNewQueueUnknown(t *testing.T) {
+	if _, err := NewQueue("fibonacci", 10); err == nil {
+		t.Error("NewQueue of an unknown queue gave no error")
+	}
+}
+
+func TestBruteForceMST(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph [][]float64
+		want  float64
+	}{
+		{"one vertex", [][]float64{{0}}, 0},
+		{"two vertices", [][]float64{{0, 3}, {3, 0}}, 3},
+		{"triangle", [][]float64{{0, 1, 4}, {1, 0, 2}, {4, 2, 0}}, 3},
+		{"square with diagonals", [][]float64{
+			{0, 1, 5, 1},
+			{1, 0, 1, 5},
+			{5, 1, 0, 1},
+			{1, 5, 1, 0},
+		}, 3},
+	}
+	for _, tt := range tests {
+		got, err := BruteForceMST(tt.graph)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: BruteForceMST = %g, %v, want %g", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(rand.New(rand.NewSource(1)), 50); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,203 @@
+/*
+Package solver is the core of the Prim minimum spanning tree (MST) web application:
+random vertex generation, the Euclidean distance matrix, and Prim's algorithm.
+It has no dependencies on net/http or the file system so that it also compiles to
+WebAssembly and runs entirely in the browser.
+*/
+package solver
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"math/rand"
+)
+
+// RandomPoints generates n random points in the complex plane within the x-y bounds
+func RandomPoints(rnd *rand.Rand, n int, xmin, xmax, ymin, ymax float64) []complex128 {
+	delx := xmax - xmin
+	dely := ymax - ymin
+	points := make([]complex128, n)
+	for i := 0; i < n; i++ {
+		x := xmin + delx*rnd.Float64()
+		y := ymin + dely*rnd.Float64()
+		points[i] = complex(x, y)
+	}
+	return points
+}
+
+// Distances returns the matrix of Euclidean distances between the points.
+// The diagonal is MaxFloat64 so that a vertex is never its own nearest neighbor.
+func Distances(points []complex128) [][]float64 {
+	verts := len(points)
+	graph := make([][]float64, verts)
+	for i := 0; i < verts; i++ {
+		graph[i] = make([]float64, verts)
+	}
+
+	for i := 0; i < verts; i++ {
+		for j := i + 1; j < verts; j++ {
+			distance := cmplx.Abs(points[i] - points[j])
+			graph[i][j] = distance
+			graph[j][i] = distance
+		}
+	}
+	for i := 0; i < verts; i++ {
+		graph[i][i] = math.MaxFloat64
+	}
+	return graph
+}
+
+// Prim finds the MST of the complete graph of distances using Prim's algorithm with
+// a binary heap priority queue.  It returns the parent of each vertex in the MST, -1 for
+// the start vertex, and the vertices in the order they were added to the MST.
+func Prim(graph [][]float64, start int) ([]int, []int) {
+	return PrimQueue(graph, start, NewBinaryQueue(len(graph)))
+}
+
+// PrimQueue finds the MST using Prim's algorithm with the priority queue q
+func PrimQueue(graph [][]float64, start int, q Queue) ([]int, []int) {
+	parent, order, _ := PrimContext(context.Background(), graph, start, q)
+	return parent, order
+}
+
+// PrimContext finds the MST using Prim's algorithm with the priority queue q, checking
+// ctx as each vertex is added.  When ctx is done it returns the partial tree of the
+// vertices added so far, with parent -1 for the others, and the context error.
+func PrimContext(ctx context.Context, graph [][]float64, start int, q Queue) ([]int, []int, error) {
+	return prim(ctx, graph, start, q, nil, false)
+}
+
+// Ties counts the arbitrary choices between equal-length candidate edges made by Prim's
+// algorithm.  Without them the MST is unique.
+type Ties struct {
+	Pops    int // vertices added while other vertices in the queue had the same distance
+	Parents int // candidate edges as short as the vertex's tree edge that were not chosen
+}
+
+// Breaks returns the number of tie-breaks
+func (t Ties) Breaks() int {
+	return t.Pops + t.Parents
+}
+
+// PrimTies is PrimContext counting the tie-breaks.  With lexicographic it breaks the ties
+// by the vertex order of the edges, least first, so the MST does not depend on the queue.
+func PrimTies(ctx context.Context, graph [][]float64, start int, q Queue, lexicographic bool) ([]int, []int, Ties, error) {
+	var ties Ties
+	parent, order, err := prim(ctx, graph, start, q, &ties, lexicographic)
+	return parent, order, ties, err
+}
+
+// edgeLess compares the edges v1-w1 and v2-w2 by their lesser vertex, then their greater vertex
+func edgeLess(v1, w1, v2, w2 int) bool {
+	if v1 > w1 {
+		v1, w1 = w1, v1
+	}
+	if v2 > w2 {
+		v2, w2 = w2, v2
+	}
+	return v1 < v2 || v1 == v2 && w1 < w2
+}
+
+// prim finds the MST, counting the tie-breaks when ties is not nil
+func prim(ctx context.Context, graph [][]float64, start int, q Queue, ties *Ties, lexicographic bool) ([]int, []int, error) {
+	vertices := len(graph)
+	parent := make([]int, vertices)
+	marked := make([]bool, vertices)
+	distTo := make([]float64, vertices)
+	for i := range distTo {
+		distTo[i] = math.MaxFloat64
+		parent[i] = -1
+	}
+	order := make([]int, 0, vertices)
+	if vertices == 0 {
+		return parent, order, nil
+	}
+
+	counter, counting := q.(scanCounter)
+	visit := func(v int) {
+		marked[v] = true
+		order = append(order, v)
+		if counting {
+			counter.Scan(len(graph[v]) - 1)
+		}
+		// find shortest distance from vertex v to w
+		for w, dist := range graph[v] {
+			// Check if already in the MST
+			if marked[w] {
+				continue
+			}
+			if dist == distTo[w] && ties != nil {
+				// An edge as short as the best connection to w
+				ties.Parents++
+				if lexicographic && edgeLess(v, w, parent[w], w) {
+					parent[w] = v
+				}
+			}
+			if dist < distTo[w] {
+				// Edge to w is new best connection from MST to w
+				parent[w] = v
+				distTo[w] = dist
+				// Check if already in the queue and update, otherwise insert
+				if q.Contains(w) {
+					q.DecreaseKey(w, dist)
+				} else {
+					q.Push(w, dist)
+				}
+			}
+		}
+	}
+
+	// Starting index is start, put it in the queue
+	q.Push(start, math.MaxFloat64)
+
+	// Loop until the queue is empty and the MST is finished
+	for q.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			// Drop the tentative parents of the vertices not yet in the tree
+			for w := range parent {
+				if !marked[w] {
+					parent[w] = -1
+				}
+			}
+			return parent, order, err
+		}
+		v, dist := q.Pop()
+		if ties != nil {
+			v = popTies(q, v, dist, parent, ties, lexicographic)
+		}
+		visit(v)
+	}
+
+	return parent, order, nil
+}
+
+// popTies pops the other vertices at the same distance as the popped vertex v, counting a
+// tie when there are any.  It pushes back all but the vertex added next: v, or with
+// lexicographic the vertex whose tree edge is least in the vertex order.
+func popTies(q Queue, v int, dist float64, parent []int, ties *Ties, lexicographic bool) int {
+	var rivals []int
+	for q.Len() > 0 {
+		w, d := q.Pop()
+		if d != dist {
+			q.Push(w, d)
+			break
+		}
+		rivals = append(rivals, w)
+	}
+	if len(rivals) == 0 {
+		return v
+	}
+	ties.Pops++
+	if lexicographic {
+		for i, w := range rivals {
+			if edgeLess(parent[w], w, parent[v], v) {
+				v, rivals[i] = w, v
+			}
+		}
+	}
+	for _, w := range rivals {
+		q.Push(w, dist)
+	}
+	return v
+}
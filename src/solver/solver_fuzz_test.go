@@ -0,0 +1,127 @@
+package solver
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+// fuzzGraph builds a complete graph from the fuzz input: the first byte is the number of
+// vertices, 1-16, and the rest are the edge weights 0-7, repeated as needed, so ties are common
+func fuzzGraph(data []byte) [][]float64 {
+	n := 1
+	if len(data) > 0 {
+		n += int(data[0]) % 16
+		data = data[1:]
+	}
+	if len(data) == 0 {
+		data = []byte{1}
+	}
+	graph := make([][]float64, n)
+	for v := range graph {
+		graph[v] = make([]float64, n)
+	}
+	k := 0
+	for v := 0; v < n; v++ {
+		for w := v + 1; w < n; w++ {
+			weight := float64(data[k%len(data)] % 8)
+			graph[v][w], graph[w][v] = weight, weight
+			k++
+		}
+	}
+	return graph
+}
+
+// kruskalWeight returns the MST weight of the graph by Kruskal's algorithm, the reference
+func kruskalWeight(graph [][]float64) float64 {
+	var edges [][2]int
+	for v := range graph {
+		for w := v + 1; w < len(graph); w++ {
+			edges = append(edges, [2]int{v, w})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return graph[edges[i][0]][edges[i][1]] < graph[edges[j][0]][edges[j][1]]
+	})
+	component := make([]int, len(graph))
+	for i := range component {
+		component[i] = i
+	}
+	var find func(v int) int
+	find = func(v int) int {
+		if component[v] != v {
+			component[v] = find(component[v])
+		}
+		return component[v]
+	}
+	var weight float64
+	for _, e := range edges {
+		if a, b := find(e[0]), find(e[1]); a != b {
+			component[a] = b
+			weight += graph[e[0]][e[1]]
+		}
+	}
+	return weight
+}
+
+// parentEdges returns the tree edges of the parents
+func parentEdges(parent []int) [][2]int {
+	var edges [][2]int
+	for v, u := range parent {
+		if u >= 0 {
+			edges = append(edges, [2]int{u, v})
+		}
+	}
+	return edges
+}
+
+// FuzzPrim checks Prim's algorithm with each priority queue against Kruskal's algorithm, and
+// that the lexicographic tie-break finds the same tree with each queue
+func FuzzPrim(f *testing.F) {
+	f.Add([]byte{3, 1, 2, 3, 1, 2, 3}, uint8(0))
+	f.Add([]byte{5, 0, 0, 0, 0}, uint8(2))
+	f.Add([]byte{11, 7, 1, 5, 3, 3, 0, 2, 6, 4}, uint8(11))
+	f.Add([]byte{15, 2, 2, 1, 1}, uint8(200))
+	f.Add([]byte{0}, uint8(0))
+	f.Fuzz(func(t *testing.T, data []byte, start uint8) {
+		graph := fuzzGraph(data)
+		n := len(graph)
+		s := int(start) % n
+		want := kruskalWeight(graph)
+		var lexicographic []int
+		for _, name := range QueueNames() {
+			q, err := NewQueue(name, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parent, order := PrimQueue(graph, s, q)
+			if len(order) != n || order[0] != s {
+				t.Fatalf("%s queue: order %v does not start at %d and add all %d vertices", name, order, s, n)
+			}
+			weight, ok := treeWeight(graph, parentEdges(parent))
+			if !ok || weight != want {
+				t.Fatalf("%s queue: tree weight %g (spanning %v), Kruskal weight %g", name, weight, ok, want)
+			}
+			if n <= MaxBruteForce {
+				if err := CheckTree(graph, s, parent); err != nil {
+					t.Fatalf("%s queue: %v", name, err)
+				}
+			}
+
+			q, _ = NewQueue(name, n)
+			parent, _, _, err = PrimTies(context.Background(), graph, s, q, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lexicographic == nil {
+				lexicographic = parent
+			}
+			for v := range parent {
+				if parent[v] != lexicographic[v] {
+					t.Fatalf("%s queue: lexicographic parent of %d is %d, %s queue gave %d",
+						name, v, parent[v], QueueNames()[0], lexicographic[v])
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,71 @@
+//go:build js && wasm
+
+/*
+This is the WebAssembly build of the Prim minimum spanning tree (MST) solver.
+It exports the JavaScript function primmst(vertices, xmin, xmax, ymin, ymax, seed)
+which generates random vertices and finds the MST entirely in the browser.
+The result is an object with vertices [{x, y}], edges [{v, w, distance}],
+the start vertex, and the total distance of the MST.
+Build with:  GOOS=js GOARCH=wasm go build -o mstmain/static/primmst.wasm ./wasmmain
+*/
+
+package main
+
+import (
+	"math/rand"
+	"syscall/js"
+
+	"github.com/thomasteplick/primmst/solver"
+)
+
+const maxVertices = 500 // the browser is meant for small graphs, the server handles big ones
+
+// primmst is called from JavaScript with the graph options and returns the MST
+func primmst(this js.Value, args []js.Value) any {
+	if len(args) != 6 {
+		return map[string]any{"error": "primmst(vertices, xmin, xmax, ymin, ymax, seed) takes 6 arguments"}
+	}
+	verts := args[0].Int()
+	xmin, xmax := args[1].Float(), args[2].Float()
+	ymin, ymax := args[3].Float(), args[4].Float()
+	seed := int64(args[5].Float())
+	if verts < 2 || verts > maxVertices {
+		return map[string]any{"error": "vertices must be between 2 and 500"}
+	}
+	if xmin >= xmax || ymin >= ymax {
+		return map[string]any{"error": "the min bounds must be less than the max bounds"}
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	points := solver.RandomPoints(rnd, verts, xmin, xmax, ymin, ymax)
+	start := rnd.Intn(verts)
+	graph := solver.Distances(points)
+	parent, _ := solver.Prim(graph, start)
+
+	vertices := make([]any, verts)
+	for i, z := range points {
+		vertices[i] = map[string]any{"x": real(z), "y": imag(z)}
+	}
+	edges := make([]any, 0, verts-1)
+	total := 0.0
+	for w, v := range parent {
+		if v < 0 {
+			continue
+		}
+		total += graph[v][w]
+		edges = append(edges, map[string]any{"v": v, "w": w, "distance": graph[v][w]})
+	}
+
+	return map[string]any{
+		"vertices": vertices,
+		"edges":    edges,
+		"start":    start,
+		"distance": total,
+	}
+}
+
+func main() {
+	js.Global().Set("primmst", js.FuncOf(primmst))
+	// Keep the Go runtime alive so primmst can be called again
+	select {}
+}